@@ -0,0 +1,261 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package distributedvl3ipam provides a registry chain element negotiating the vL3 NSE prefix with the other vL3 NSEs
+// of the same network service without the central vl3ipam server.
+//
+// Each vL3 NSE claims a sub-prefix of the global prefix, starting from the one chosen by the NSE name hash and skipping
+// the ones already claimed by the other vL3 NSEs, and advertises it in the PrefixLabel of its registration. The vL3 NSEs
+// watch each other via the registry: if two of them have claimed overlapping sub-prefixes, the one with the greater
+// name claims another sub-prefix, resets its vl3.IPAM and registers again.
+package distributedvl3ipam
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/connectioncontext/ipcontext/vl3"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/begin"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type distributedVL3IPAMClient struct {
+	ctx         context.Context
+	ipam        *vl3.IPAM
+	subPrefixes *subPrefixes
+	findClient  registry.NetworkServiceEndpointRegistryClient
+	opts        *options
+
+	mu     sync.Mutex
+	name   string
+	claim  *net.IPNet
+	peers  map[string]*net.IPNet
+	cancel context.CancelFunc
+}
+
+// NewNetworkServiceEndpointRegistryClient creates new NetworkServiceEndpointRegistryClient negotiating the sub-prefix
+// of the prefix with the prefixLen for the vL3 NSE with the other vL3 NSEs of the same network service. The claimed
+// sub-prefix is set to ipam and is advertised in the PrefixLabel of the NSE. findClient is used to watch the other vL3
+// NSEs.
+// The element should be placed after begin.
+func NewNetworkServiceEndpointRegistryClient(
+	ctx context.Context,
+	ipam *vl3.IPAM,
+	prefix string,
+	prefixLen uint8,
+	findClient registry.NetworkServiceEndpointRegistryClient,
+	opts ...Option,
+) registry.NetworkServiceEndpointRegistryClient {
+	if ipam == nil {
+		panic("vl3IPAM can not be nil")
+	}
+	if findClient == nil {
+		panic("findClient can not be nil")
+	}
+	subPrefixes, err := newSubPrefixes(prefix, prefixLen)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	c := &distributedVL3IPAMClient{
+		ctx:         ctx,
+		ipam:        ipam,
+		subPrefixes: subPrefixes,
+		findClient:  findClient,
+		opts: &options{
+			retryInterval: time.Second,
+		},
+		peers: make(map[string]*net.IPNet),
+	}
+	for _, opt := range opts {
+		opt(c.opts)
+	}
+	return c
+}
+
+func (c *distributedVL3IPAMClient) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*registry.NetworkServiceEndpoint, error) {
+	c.mu.Lock()
+	if c.claim == nil {
+		c.name = nse.GetName()
+		c.loadPeers(ctx, nse.GetNetworkServiceNames())
+		if err := c.reclaim(); err != nil {
+			c.mu.Unlock()
+			return nil, err
+		}
+	}
+	setPrefixLabel(nse, c.claim.String())
+	watching := c.cancel != nil
+	c.mu.Unlock()
+
+	resp, err := next.NetworkServiceEndpointRegistryClient(ctx).Register(ctx, nse, opts...)
+	if err != nil || watching {
+		return resp, err
+	}
+
+	c.mu.Lock()
+	var watchCtx context.Context
+	watchCtx, c.cancel = context.WithCancel(c.ctx)
+	c.mu.Unlock()
+
+	go c.watch(watchCtx, begin.FromContext(ctx), nse.GetNetworkServiceNames())
+
+	return resp, nil
+}
+
+// loadPeers should be called under the mu
+func (c *distributedVL3IPAMClient) loadPeers(ctx context.Context, networkServiceNames []string) {
+	stream, err := c.findClient.Find(ctx, newQuery(networkServiceNames, false))
+	if err != nil {
+		log.FromContext(ctx).WithField("distributedVL3IPAMClient", "loadPeers").Warnf("failed to find vL3 NSEs: %s", err.Error())
+		return
+	}
+	for _, resp := range registry.ReadNetworkServiceEndpointList(stream) {
+		c.updatePeer(resp, false)
+	}
+}
+
+// reclaim should be called under the mu
+func (c *distributedVL3IPAMClient) reclaim() error {
+	claimed := make([]*net.IPNet, 0, len(c.peers))
+	for _, prefix := range c.peers {
+		claimed = append(claimed, prefix)
+	}
+	claim, err := c.subPrefixes.choose(c.name, claimed)
+	if err != nil {
+		return err
+	}
+	if err := c.ipam.Reset(claim.String(), c.opts.excludedPrefixes...); err != nil {
+		return err
+	}
+	c.claim = claim
+	return nil
+}
+
+func (c *distributedVL3IPAMClient) watch(ctx context.Context, factory begin.EventFactory, networkServiceNames []string) {
+	logger := log.FromContext(ctx).WithField("distributedVL3IPAMClient", "watch")
+	clockTime := clock.FromContext(ctx)
+
+	for ctx.Err() == nil {
+		stream, err := c.findClient.Find(ctx, newQuery(networkServiceNames, true))
+		if err != nil {
+			logger.Warnf("failed to watch vL3 NSEs: %s", err.Error())
+		}
+		for err == nil {
+			var resp *registry.NetworkServiceEndpointResponse
+			if resp, err = stream.Recv(); err != nil {
+				break
+			}
+
+			c.mu.Lock()
+			conflict := c.updatePeer(resp.GetNetworkServiceEndpoint(), resp.GetDeleted())
+			if conflict {
+				oldClaim := c.claim.String()
+				if reclaimErr := c.reclaim(); reclaimErr != nil {
+					logger.Errorf("failed to resolve the conflict of %s: %s", oldClaim, reclaimErr.Error())
+					conflict = false
+				} else {
+					logger.Infof("%s conflicts with %s, claimed %s", oldClaim, resp.GetNetworkServiceEndpoint().GetName(), c.claim.String())
+				}
+			}
+			c.mu.Unlock()
+
+			if conflict {
+				<-factory.Register(begin.CancelContext(ctx))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-clockTime.After(c.opts.retryInterval):
+		}
+	}
+}
+
+// updatePeer should be called under the mu. Returns true if the peer wins the conflict with the current claim.
+func (c *distributedVL3IPAMClient) updatePeer(nse *registry.NetworkServiceEndpoint, deleted bool) bool {
+	if nse.GetName() == c.name {
+		return false
+	}
+	prefix := getPrefixLabel(nse)
+	if deleted || prefix == nil {
+		delete(c.peers, nse.GetName())
+		return false
+	}
+	c.peers[nse.GetName()] = prefix
+
+	return c.claim != nil && overlaps(c.claim, prefix) && nse.GetName() < c.name
+}
+
+func (c *distributedVL3IPAMClient) Find(ctx context.Context, query *registry.NetworkServiceEndpointQuery, opts ...grpc.CallOption) (registry.NetworkServiceEndpointRegistry_FindClient, error) {
+	return next.NetworkServiceEndpointRegistryClient(ctx).Find(ctx, query, opts...)
+}
+
+func (c *distributedVL3IPAMClient) Unregister(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*empty.Empty, error) {
+	c.mu.Lock()
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	c.mu.Unlock()
+
+	return next.NetworkServiceEndpointRegistryClient(ctx).Unregister(ctx, nse, opts...)
+}
+
+func newQuery(networkServiceNames []string, watch bool) *registry.NetworkServiceEndpointQuery {
+	return &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{
+			NetworkServiceNames: networkServiceNames,
+		},
+		Watch: watch,
+	}
+}
+
+func setPrefixLabel(nse *registry.NetworkServiceEndpoint, prefix string) {
+	if nse.NetworkServiceLabels == nil {
+		nse.NetworkServiceLabels = make(map[string]*registry.NetworkServiceLabels)
+	}
+	for _, name := range nse.GetNetworkServiceNames() {
+		labels := nse.NetworkServiceLabels[name]
+		if labels == nil {
+			labels = new(registry.NetworkServiceLabels)
+			nse.NetworkServiceLabels[name] = labels
+		}
+		if labels.Labels == nil {
+			labels.Labels = make(map[string]string)
+		}
+		labels.Labels[PrefixLabel] = prefix
+	}
+}
+
+func getPrefixLabel(nse *registry.NetworkServiceEndpoint) *net.IPNet {
+	for _, labels := range nse.GetNetworkServiceLabels() {
+		if value, ok := labels.GetLabels()[PrefixLabel]; ok {
+			if _, prefix, err := net.ParseCIDR(value); err == nil {
+				return prefix
+			}
+		}
+	}
+	return nil
+}
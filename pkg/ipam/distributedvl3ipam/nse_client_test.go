@@ -0,0 +1,186 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distributedvl3ipam_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/ipam/distributedvl3ipam"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/connectioncontext/ipcontext/vl3"
+	registryclient "github.com/networkservicemesh/sdk/pkg/registry/chains/client"
+	"github.com/networkservicemesh/sdk/pkg/tools/sandbox"
+)
+
+const networkService = "vl3"
+
+func newRegistryClient(ctx context.Context, domain *sandbox.Domain, opts ...registryclient.Option) registry.NetworkServiceEndpointRegistryClient {
+	return registryclient.NewNetworkServiceEndpointRegistryClient(ctx, append([]registryclient.Option{
+		registryclient.WithClientURL(sandbox.CloneURL(domain.Nodes[0].NSMgr.URL)),
+		registryclient.WithDialOptions(sandbox.DialOptions()...),
+	}, opts...)...)
+}
+
+func registerVL3NSE(ctx context.Context, t *testing.T, domain *sandbox.Domain, name, prefix string, prefixLen uint8) *vl3.IPAM {
+	ipam := new(vl3.IPAM)
+	nseClient := newRegistryClient(ctx, domain, registryclient.WithNSEAdditionalFunctionality(
+		distributedvl3ipam.NewNetworkServiceEndpointRegistryClient(ctx, ipam, prefix, prefixLen, newRegistryClient(ctx, domain),
+			distributedvl3ipam.WithRetryInterval(100*time.Millisecond)),
+	))
+
+	_, err := nseClient.Register(ctx, &registry.NetworkServiceEndpoint{
+		Name:                name,
+		Url:                 "tcp://0.0.0.0",
+		NetworkServiceNames: []string{networkService},
+	})
+	require.NoError(t, err)
+
+	return ipam
+}
+
+// advertisedPrefixes returns the prefixes advertised by the vL3 NSEs if they are consistent with the IPAMs
+func advertisedPrefixes(ctx context.Context, t *testing.T, findClient registry.NetworkServiceEndpointRegistryClient, ipams map[string]*vl3.IPAM) map[string]*net.IPNet {
+	stream, err := findClient.Find(ctx, &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{networkService}},
+	})
+	require.NoError(t, err)
+
+	prefixes := make(map[string]*net.IPNet)
+	for _, nse := range registry.ReadNetworkServiceEndpointList(stream) {
+		_, prefix, err := net.ParseCIDR(nse.GetNetworkServiceLabels()[networkService].GetLabels()[distributedvl3ipam.PrefixLabel])
+		require.NoError(t, err)
+
+		ipam, ok := ipams[nse.GetName()]
+		if !ok {
+			continue
+		}
+		host := &net.IPNet{IP: append(net.IP(nil), prefix.IP...), Mask: net.CIDRMask(32, 32)}
+		host.IP[len(host.IP)-1]++
+		if !ipam.ContainsNetString(host.String()) {
+			return nil
+		}
+		prefixes[nse.GetName()] = prefix
+	}
+	return prefixes
+}
+
+func Test_DistributedVL3IPAM_NonOverlappingPrefixes(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	domain := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(1).
+		SetRegistryProxySupplier(nil).
+		SetNSMgrProxySupplier(nil).
+		Build()
+
+	// 4 vL3 NSEs share 4 sub-prefixes, so some of them start with the conflicting ones
+	var mu sync.Mutex
+	ipams := make(map[string]*vl3.IPAM)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("vl3-nse-%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ipam := registerVL3NSE(ctx, t, domain, name, "10.0.0.0/22", 24)
+			mu.Lock()
+			ipams[name] = ipam
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	findClient := newRegistryClient(ctx, domain)
+	require.Eventually(t, func() bool {
+		prefixes := advertisedPrefixes(ctx, t, findClient, ipams)
+		if len(prefixes) != len(ipams) {
+			return false
+		}
+		unique := make(map[string]struct{})
+		for _, prefix := range prefixes {
+			unique[prefix.String()] = struct{}{}
+		}
+		return len(unique) == len(ipams)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+}
+
+func Test_DistributedVL3IPAM_Conflict(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	domain := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(1).
+		SetRegistryProxySupplier(nil).
+		SetNSMgrProxySupplier(nil).
+		Build()
+
+	ipams := map[string]*vl3.IPAM{
+		"vl3-nse-b": registerVL3NSE(ctx, t, domain, "vl3-nse-b", "10.0.0.0/16", 24),
+	}
+	var resets int
+	var mu sync.Mutex
+	ipams["vl3-nse-b"].Subscribe(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		resets++
+	})
+
+	findClient := newRegistryClient(ctx, domain)
+	prefixes := advertisedPrefixes(ctx, t, findClient, ipams)
+	require.Len(t, prefixes, 1)
+	claimed := prefixes["vl3-nse-b"].String()
+
+	// vl3-nse-a claims the same prefix and wins the conflict
+	_, err := newRegistryClient(ctx, domain).Register(ctx, &registry.NetworkServiceEndpoint{
+		Name:                "vl3-nse-a",
+		Url:                 "tcp://0.0.0.0",
+		NetworkServiceNames: []string{networkService},
+		NetworkServiceLabels: map[string]*registry.NetworkServiceLabels{
+			networkService: {Labels: map[string]string{distributedvl3ipam.PrefixLabel: claimed}},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		prefixes = advertisedPrefixes(ctx, t, findClient, ipams)
+		return len(prefixes) == 1 && prefixes["vl3-nse-b"].String() != claimed
+	}, 5*time.Second, 50*time.Millisecond)
+
+	mu.Lock()
+	require.Equal(t, 1, resets)
+	mu.Unlock()
+
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distributedvl3ipam
+
+import "time"
+
+// PrefixLabel is the NSE label advertising the vL3 NSE sub-prefix
+const PrefixLabel = "vl3Prefix"
+
+type options struct {
+	retryInterval    time.Duration
+	excludedPrefixes []string
+}
+
+// Option is an option pattern for NewNetworkServiceEndpointRegistryClient
+type Option func(o *options)
+
+// WithRetryInterval sets the interval between the attempts to restart the broken watch of the vL3 NSEs. Default: 1s
+func WithRetryInterval(retryInterval time.Duration) Option {
+	return func(o *options) {
+		o.retryInterval = retryInterval
+	}
+}
+
+// WithExcludedPrefixes sets the prefixes excluded from the claimed sub-prefix on vl3.IPAM reset
+func WithExcludedPrefixes(excludedPrefixes ...string) Option {
+	return func(o *options) {
+		o.excludedPrefixes = excludedPrefixes
+	}
+}
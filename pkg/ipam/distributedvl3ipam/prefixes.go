@@ -0,0 +1,98 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distributedvl3ipam
+
+import (
+	"hash/fnv"
+	"math/big"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// subPrefixes splits the global prefix into the sub-prefixes of the same length
+type subPrefixes struct {
+	global    *net.IPNet
+	prefixLen int
+	count     *big.Int
+}
+
+func newSubPrefixes(prefix string, prefixLen uint8) (*subPrefixes, error) {
+	_, global, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", prefix)
+	}
+	ones, bits := global.Mask.Size()
+	if int(prefixLen) < ones || int(prefixLen) > bits {
+		return nil, errors.Errorf("sub-prefix length %d is out of [%d, %d]", prefixLen, ones, bits)
+	}
+	return &subPrefixes{
+		global:    global,
+		prefixLen: int(prefixLen),
+		count:     new(big.Int).Lsh(big.NewInt(1), uint(int(prefixLen)-ones)),
+	}, nil
+}
+
+// home returns the index of the sub-prefix preferred by the NSE
+func (s *subPrefixes) home(name string) *big.Int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return new(big.Int).Mod(new(big.Int).SetUint64(h.Sum64()), s.count)
+}
+
+func (s *subPrefixes) get(index *big.Int) *net.IPNet {
+	_, bits := s.global.Mask.Size()
+	offset := new(big.Int).Lsh(index, uint(bits-s.prefixLen))
+	ip := new(big.Int).Add(new(big.Int).SetBytes(s.global.IP), offset).Bytes()
+
+	ipNet := &net.IPNet{
+		IP:   make(net.IP, len(s.global.IP)),
+		Mask: net.CIDRMask(s.prefixLen, bits),
+	}
+	copy(ipNet.IP[len(ipNet.IP)-len(ip):], ip)
+	return ipNet
+}
+
+// choose returns the first sub-prefix starting from the NSE home sub-prefix not overlapping with the claimed ones
+func (s *subPrefixes) choose(name string, claimed []*net.IPNet) (*net.IPNet, error) {
+	index := s.home(name)
+	attempts := big.NewInt(int64(len(claimed) + 1))
+	if attempts.Cmp(s.count) > 0 {
+		attempts = s.count
+	}
+	for i := int64(0); i < attempts.Int64(); i++ {
+		candidate := s.get(index)
+		if !overlapsAny(candidate, claimed) {
+			return candidate, nil
+		}
+		index.Add(index, big.NewInt(1)).Mod(index, s.count)
+	}
+	return nil, errors.Errorf("no free sub-prefixes of %s", s.global.String())
+}
+
+func overlapsAny(ipNet *net.IPNet, others []*net.IPNet) bool {
+	for _, other := range others {
+		if overlaps(ipNet, other) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vl3ipam
+
+import (
+	"context"
+	"time"
+)
+
+// Option is an option pattern for NewIPAMServer
+type Option func(s *vl3IPAMServer)
+
+// WithContext sets the context used to get the clock and the logger. Default: context.Background()
+func WithContext(ctx context.Context) Option {
+	return func(s *vl3IPAMServer) {
+		s.ctx = ctx
+	}
+}
+
+// WithGracePeriod sets the lease grace period: when the client stream breaks, its prefixes are kept for the grace
+// period, so the reconnecting client requesting the same prefix gets it back. Explicitly deleted prefixes are released
+// immediately. Default: 0, the prefixes are released when the stream breaks.
+func WithGracePeriod(gracePeriod time.Duration) Option {
+	return func(s *vl3IPAMServer) {
+		s.gracePeriod = gracePeriod
+	}
+}
+
+// WithStorage sets the storage for the pool and the leases state. The server restores the state on creation, all the
+// restored leases are released after the grace period unless reclaimed by the reconnecting clients.
+func WithStorage(storage Storage) Option {
+	return func(s *vl3IPAMServer) {
+		s.storage = storage
+	}
+}
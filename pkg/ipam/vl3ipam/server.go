@@ -1,4 +1,4 @@
-// Copyright (c) 2022-2023 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -18,14 +18,19 @@
 package vl3ipam
 
 import (
+	"context"
 	"net"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/networkservicemesh/api/pkg/api/ipam"
 	"github.com/pkg/errors"
 
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/ipaminfo"
 	"github.com/networkservicemesh/sdk/pkg/tools/ippool"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
 )
@@ -36,19 +41,42 @@ var ErrUndefined = errors.New("request type is undefined")
 // ErrOutOfRange means that ip pool of IPAM is empty
 var ErrOutOfRange = errors.New("prefix is out of range or already in use")
 
+type lease struct {
+	// owner is the ID of the client stream holding the lease, it is empty for the released lease
+	owner          string
+	expirationTime time.Time
+	timer          clock.Timer
+}
+
 type vl3IPAMServer struct {
+	ctx              context.Context
+	prefix           string
 	pool             *ippool.IPPool
+	leases           map[string]*lease
 	excludedPrefixes []string
 	poolMutex        sync.Mutex
 	initalSize       uint8
+	gracePeriod      time.Duration
+	storage          Storage
 }
 
-// NewIPAMServer creates a new ipam.IPAMServer handler for grpc.Server
-func NewIPAMServer(prefix string, initialNSEPrefixSize uint8) ipam.IPAMServer {
-	return &vl3IPAMServer{
+// NewIPAMServer creates a new ipam.IPAMServer handler for grpc.Server. The returned server implements
+// ipaminfo.Introspector.
+func NewIPAMServer(prefix string, initialNSEPrefixSize uint8, options ...Option) ipam.IPAMServer {
+	s := &vl3IPAMServer{
+		ctx:        context.Background(),
+		prefix:     prefix,
 		pool:       ippool.NewWithNetString(prefix),
+		leases:     make(map[string]*lease),
 		initalSize: initialNSEPrefixSize,
 	}
+	for _, opt := range options {
+		opt(s)
+	}
+	if s.storage != nil {
+		s.restore()
+	}
+	return s
 }
 
 var _ ipam.IPAMServer = (*vl3IPAMServer)(nil)
@@ -57,7 +85,8 @@ func (s *vl3IPAMServer) ManagePrefixes(prefixServer ipam.IPAM_ManagePrefixesServ
 	var clientsPrefixes []string
 	var err error
 
-	logger := log.Default().WithField("ID", uuid.New().String())
+	id := uuid.New().String()
+	logger := log.Default().WithField("ID", id)
 	for err == nil {
 		var r *ipam.PrefixRequest
 
@@ -73,7 +102,7 @@ func (s *vl3IPAMServer) ManagePrefixes(prefixServer ipam.IPAM_ManagePrefixesServ
 
 		case ipam.Type_ALLOCATE:
 			var resp *ipam.PrefixResponse
-			resp, err = s.allocate(r)
+			resp, err = s.allocate(id, r)
 			if err != nil {
 				break
 			}
@@ -94,13 +123,9 @@ func (s *vl3IPAMServer) ManagePrefixes(prefixServer ipam.IPAM_ManagePrefixesServ
 		}
 	}
 
-	s.poolMutex.Lock()
-	for _, prefix := range clientsPrefixes {
-		s.pool.AddNetString(prefix)
-	}
-	s.poolMutex.Unlock()
+	s.release(id, clientsPrefixes)
 	logger.Debugf("Disconnected. Error: %v", err.Error())
-	logger.Debugf("Deleted: %v", clientsPrefixes)
+	logger.Debugf("Released: %v", clientsPrefixes)
 
 	if prefixServer.Context().Err() != nil {
 		return nil
@@ -109,33 +134,43 @@ func (s *vl3IPAMServer) ManagePrefixes(prefixServer ipam.IPAM_ManagePrefixesServ
 	return errors.Wrap(err, "failed to manage prefixes")
 }
 
-func (s *vl3IPAMServer) allocate(r *ipam.PrefixRequest) (*ipam.PrefixResponse, error) {
+func (s *vl3IPAMServer) allocate(owner string, r *ipam.PrefixRequest) (*ipam.PrefixResponse, error) {
 	s.poolMutex.Lock()
-	// We don't need to exclude prefixes which were indicated in the PrefixRequest from the main pool
-	pool := s.pool.Clone()
-	for _, excludePrefix := range r.ExcludePrefixes {
-		pool.ExcludeString(excludePrefix)
-	}
+	defer s.poolMutex.Unlock()
+
 	resp := &ipam.PrefixResponse{
 		Prefix: r.Prefix,
 	}
-	if resp.Prefix == "" || !s.pool.ContainsNetString(resp.Prefix) {
-		ip, err := pool.Pull()
-		if err != nil {
-			s.poolMutex.Unlock()
-			return nil, err
-		}
-		ipNet := &net.IPNet{
-			IP: ip,
-			Mask: net.CIDRMask(
-				int(s.initalSize),
-				len(ip)*8,
-			),
+	if l, ok := s.leases[resp.Prefix]; ok && l.owner == "" {
+		// The reconnecting client reclaims the released lease
+		l.timer.Stop()
+		l.owner = owner
+		l.expirationTime = time.Time{}
+	} else {
+		if resp.Prefix == "" || !s.pool.ContainsNetString(resp.Prefix) {
+			// We don't need to exclude prefixes which were indicated in the PrefixRequest from the main pool
+			pool := s.pool.Clone()
+			for _, excludePrefix := range r.ExcludePrefixes {
+				pool.ExcludeString(excludePrefix)
+			}
+			ip, err := pool.Pull()
+			if err != nil {
+				return nil, err
+			}
+			ipNet := &net.IPNet{
+				IP: ip,
+				Mask: net.CIDRMask(
+					int(s.initalSize),
+					len(ip)*8,
+				),
+			}
+			resp.Prefix = ipNet.String()
 		}
-		resp.Prefix = ipNet.String()
+		s.pool.ExcludeString(resp.Prefix)
+		s.leases[resp.Prefix] = &lease{owner: owner}
 	}
-	s.pool.ExcludeString(resp.Prefix)
-	s.poolMutex.Unlock()
+	s.store()
+
 	resp.ExcludePrefixes = r.ExcludePrefixes
 	resp.ExcludePrefixes = append(resp.ExcludePrefixes, s.excludedPrefixes...)
 	return resp, nil
@@ -143,6 +178,158 @@ func (s *vl3IPAMServer) allocate(r *ipam.PrefixRequest) (*ipam.PrefixResponse, e
 
 func (s *vl3IPAMServer) delete(r *ipam.PrefixRequest) {
 	s.poolMutex.Lock()
-	s.pool.AddNetString(r.Prefix)
-	s.poolMutex.Unlock()
+	defer s.poolMutex.Unlock()
+
+	s.returnToPool(r.Prefix)
+	s.store()
+}
+
+// release releases the leases of the disconnected client. The released leases return to the pool after the grace
+// period unless reclaimed.
+func (s *vl3IPAMServer) release(owner string, prefixes []string) {
+	s.poolMutex.Lock()
+	defer s.poolMutex.Unlock()
+
+	clockTime := clock.FromContext(s.ctx)
+	for _, prefix := range prefixes {
+		if l, ok := s.leases[prefix]; !ok || l.owner != owner {
+			continue
+		}
+		if s.gracePeriod <= 0 {
+			s.returnToPool(prefix)
+			continue
+		}
+		s.expireLease(prefix, clockTime.Now().Add(s.gracePeriod))
+	}
+	s.store()
+}
+
+// expireLease should be called under the poolMutex
+func (s *vl3IPAMServer) expireLease(prefix string, expirationTime time.Time) {
+	clockTime := clock.FromContext(s.ctx)
+
+	l := &lease{expirationTime: expirationTime}
+	l.timer = clockTime.AfterFunc(clockTime.Until(expirationTime), func() {
+		s.poolMutex.Lock()
+		defer s.poolMutex.Unlock()
+
+		if s.leases[prefix] != l {
+			return
+		}
+		log.FromContext(s.ctx).WithField("vl3IPAMServer", "expireLease").Debugf("Lease expired: %v", prefix)
+		s.returnToPool(prefix)
+		s.store()
+	})
+	s.leases[prefix] = l
+}
+
+// returnToPool should be called under the poolMutex
+func (s *vl3IPAMServer) returnToPool(prefix string) {
+	if l, ok := s.leases[prefix]; ok && l.timer != nil {
+		l.timer.Stop()
+	}
+	delete(s.leases, prefix)
+	s.pool.AddNetString(prefix)
+}
+
+func (s *vl3IPAMServer) restore() {
+	logger := log.FromContext(s.ctx).WithField("vl3IPAMServer", "restore")
+
+	state, err := s.storage.Load()
+	if err != nil {
+		logger.Errorf("failed to load the state: %s", err.Error())
+		return
+	}
+	if state == nil {
+		return
+	}
+	if state.Prefix != s.prefix {
+		logger.Warnf("the stored state is for the different prefix %s, ignoring it", state.Prefix)
+		return
+	}
+
+	s.poolMutex.Lock()
+	defer s.poolMutex.Unlock()
+
+	s.pool.Clear()
+	for _, prefix := range state.Pool {
+		s.pool.AddNetString(prefix)
+	}
+
+	// The clients of the restored leases are disconnected, so the leases are released
+	now := clock.FromContext(s.ctx).Now()
+	for _, l := range state.Leases {
+		expirationTime := now.Add(s.gracePeriod)
+		if !l.ExpirationTime.IsZero() && l.ExpirationTime.Before(expirationTime) {
+			expirationTime = l.ExpirationTime
+		}
+		if !expirationTime.After(now) {
+			s.pool.AddNetString(l.Prefix)
+			continue
+		}
+		s.expireLease(l.Prefix, expirationTime)
+	}
+	logger.Infof("restored %d leases", len(s.leases))
+	s.store()
+}
+
+// store should be called under the poolMutex
+func (s *vl3IPAMServer) store() {
+	if s.storage == nil {
+		return
+	}
+
+	state := &State{
+		Prefix: s.prefix,
+		Pool:   s.pool.GetPrefixes(),
+	}
+	for prefix, l := range s.leases {
+		state.Leases = append(state.Leases, Lease{
+			Prefix:         prefix,
+			ExpirationTime: l.expirationTime,
+		})
+	}
+	sort.Slice(state.Leases, func(i, j int) bool {
+		return state.Leases[i].Prefix < state.Leases[j].Prefix
+	})
+
+	if err := s.storage.Store(state); err != nil {
+		log.FromContext(s.ctx).WithField("vl3IPAMServer", "store").Errorf("failed to store the state: %s", err.Error())
+	}
+}
+
+// Allocations returns the prefixes leased to the client streams by the stream IDs. The released leases kept for the
+// grace period have empty connection ID.
+func (s *vl3IPAMServer) Allocations() []*ipaminfo.Allocation {
+	s.poolMutex.Lock()
+	defer s.poolMutex.Unlock()
+
+	byOwner := make(map[string]*ipaminfo.Allocation)
+	var allocations []*ipaminfo.Allocation
+	for prefix, l := range s.leases {
+		allocation, ok := byOwner[l.owner]
+		if !ok {
+			allocation = &ipaminfo.Allocation{ConnectionID: l.owner}
+			byOwner[l.owner] = allocation
+			allocations = append(allocations, allocation)
+		}
+		allocation.Addresses = append(allocation.Addresses, prefix)
+	}
+	for _, allocation := range allocations {
+		sort.Strings(allocation.Addresses)
+	}
+	return ipaminfo.SortAllocations(allocations)
+}
+
+// Usage returns the usage of the prefix
+func (s *vl3IPAMServer) Usage() []*ipaminfo.PrefixUsage {
+	_, ipNet, err := net.ParseCIDR(s.prefix)
+	if err != nil {
+		return nil
+	}
+
+	s.poolMutex.Lock()
+	defer s.poolMutex.Unlock()
+
+	return []*ipaminfo.PrefixUsage{ipaminfo.NewPrefixUsage(ipNet, s.pool.AddressCount())}
 }
@@ -1,4 +1,4 @@
-// Copyright (c) 2022-2023 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -31,13 +32,16 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/networkservicemesh/sdk/pkg/ipam/vl3ipam"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
 	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
+	"github.com/networkservicemesh/sdk/pkg/tools/ipaminfo"
 )
 
 // nolint:unparam
-func newVL3IPAMServer(ctx context.Context, t *testing.T, prefix string, initialSize uint8) url.URL {
+func newVL3IPAMServer(ctx context.Context, t *testing.T, prefix string, initialSize uint8, options ...vl3ipam.Option) url.URL {
 	var s = grpc.NewServer()
-	ipam.RegisterIPAMServer(s, vl3ipam.NewIPAMServer(prefix, initialSize))
+	ipam.RegisterIPAMServer(s, vl3ipam.NewIPAMServer(prefix, initialSize, options...))
 
 	var serverAddr url.URL
 
@@ -190,3 +194,110 @@ func Test_vl3_IPAM_Allocate4(t *testing.T) {
 		require.NotEmpty(t, resp.ExcludePrefixes, i)
 	}
 }
+
+func allocate(ctx context.Context, t *testing.T, connectTO *url.URL, prefix string) string {
+	c := newVL3IPAMClient(ctx, t, connectTO)
+
+	stream, err := c.ManagePrefixes(ctx)
+	require.NoError(t, err)
+
+	err = stream.Send(&ipam.PrefixRequest{
+		Type:   ipam.Type_ALLOCATE,
+		Prefix: prefix,
+	})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	return resp.Prefix
+}
+
+func Test_vl3_IPAM_GracePeriod(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+
+	ipamServer := vl3ipam.NewIPAMServer("172.16.0.0/16", 24,
+		vl3ipam.WithContext(clock.WithClock(ctx, clockMock)),
+		vl3ipam.WithGracePeriod(time.Minute))
+	introspector, ok := ipamServer.(ipaminfo.Introspector)
+	require.True(t, ok)
+
+	var s = grpc.NewServer()
+	ipam.RegisterIPAMServer(s, ipamServer)
+	var connectTO url.URL
+	require.Len(t, grpcutils.ListenAndServe(ctx, &connectTO, s), 0)
+
+	clientCtx, clientCancel := context.WithCancel(ctx)
+	require.Equal(t, "172.16.0.0/24", allocate(clientCtx, t, &connectTO, ""))
+	clientCancel()
+	time.Sleep(time.Millisecond * 50)
+
+	// The released prefix is kept for the grace period
+	require.Equal(t, "172.16.1.0/24", allocate(ctx, t, &connectTO, ""))
+
+	allocations := introspector.Allocations()
+	require.Len(t, allocations, 2)
+	require.Empty(t, allocations[0].ConnectionID)
+	require.Equal(t, []string{"172.16.0.0/24"}, allocations[0].Addresses)
+	require.Equal(t, []string{"172.16.1.0/24"}, allocations[1].Addresses)
+	require.Equal(t, int64(1<<16-2*(1<<8)), introspector.Usage()[0].Free.Int64())
+
+	// The reconnecting client gets the same prefix back
+	clientCtx, clientCancel = context.WithCancel(ctx)
+	require.Equal(t, "172.16.0.0/24", allocate(clientCtx, t, &connectTO, "172.16.0.0/24"))
+	clientCancel()
+	time.Sleep(time.Millisecond * 50)
+
+	// The lease returns to the pool after the grace period
+	clockMock.Add(time.Minute)
+	require.Equal(t, "172.16.0.0/24", allocate(ctx, t, &connectTO, ""))
+}
+
+func Test_vl3_IPAM_Storage(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	storage := vl3ipam.NewFileStorage(filepath.Join(t.TempDir(), "vl3ipam.json"))
+
+	serverCtx, serverCancel := context.WithCancel(ctx)
+	connectTO := newVL3IPAMServer(serverCtx, t, "172.16.0.0/16", 24,
+		vl3ipam.WithGracePeriod(time.Minute),
+		vl3ipam.WithStorage(storage))
+
+	require.Equal(t, "172.16.0.0/24", allocate(serverCtx, t, &connectTO, ""))
+	require.Equal(t, "172.16.1.0/24", allocate(serverCtx, t, &connectTO, ""))
+	serverCancel()
+	time.Sleep(time.Millisecond * 50)
+
+	state, err := storage.Load()
+	require.NoError(t, err)
+	require.Len(t, state.Leases, 2)
+
+	// The restarted server keeps the leases for the grace period
+	connectTO = newVL3IPAMServer(ctx, t, "172.16.0.0/16", 24,
+		vl3ipam.WithGracePeriod(time.Minute),
+		vl3ipam.WithStorage(storage))
+
+	require.Equal(t, "172.16.2.0/24", allocate(ctx, t, &connectTO, ""))
+	require.Equal(t, "172.16.1.0/24", allocate(ctx, t, &connectTO, "172.16.1.0/24"))
+
+	// The restarted server without the grace period releases the leases
+	connectTO = newVL3IPAMServer(ctx, t, "172.16.0.0/16", 24,
+		vl3ipam.WithStorage(storage))
+	require.Equal(t, "172.16.0.0/24", allocate(ctx, t, &connectTO, ""))
+
+	// Wait for the servers to release the leases before the temporary dir is removed
+	cancel()
+	time.Sleep(time.Millisecond * 50)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vl3ipam
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/fs"
+)
+
+// State is the persisted state of the vL3 IPAM server
+type State struct {
+	// Prefix is the prefix managed by the IPAM server
+	Prefix string `json:"prefix"`
+	// Pool is the list of the available prefixes of the ippool.IPPool
+	Pool []string `json:"pool"`
+	// Leases is the list of the allocated prefixes
+	Leases []Lease `json:"leases,omitempty"`
+}
+
+// Lease is the prefix allocated by the IPAM server
+type Lease struct {
+	Prefix string `json:"prefix"`
+	// ExpirationTime is the time when the released lease returns to the pool, it is zero for the active lease
+	ExpirationTime time.Time `json:"expirationTime,omitempty"`
+}
+
+// Storage persists the vL3 IPAM server state
+type Storage interface {
+	// Load returns the stored state or nil if there is no stored state
+	Load() (*State, error)
+	// Store replaces the stored state
+	Store(state *State) error
+}
+
+const filePerm = 0o600
+
+type fileStorage struct {
+	path string
+}
+
+// NewFileStorage returns Storage keeping the state in the JSON file
+func NewFileStorage(path string) Storage {
+	return &fileStorage{
+		path: path,
+	}
+}
+
+func (s *fileStorage) Load() (*State, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", s.path)
+	}
+
+	state := new(State)
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", s.path)
+	}
+	return state, nil
+}
+
+func (s *fileStorage) Store(state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal vL3 IPAM state")
+	}
+	return fs.WriteFileAtomic(s.path, data, filePerm)
+}
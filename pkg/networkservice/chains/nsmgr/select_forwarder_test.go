@@ -23,6 +23,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 
@@ -31,10 +32,14 @@ import (
 
 	nsclient "github.com/networkservicemesh/sdk/pkg/networkservice/chains/client"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/nsmgr"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/circuitbreaker"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/null"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/roundrobin"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/count"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
 	"github.com/networkservicemesh/sdk/pkg/tools/sandbox"
+	"github.com/networkservicemesh/sdk/pkg/tools/token"
 )
 
 func Test_DiscoverForwarder_CloseAfterError(t *testing.T) {
@@ -397,3 +402,188 @@ func Test_DiscoverForwarder_Should_KeepSelectedForwarderWhileConnectionIsFine(t
 	require.Greater(t, counter.Closes(), 0)
 	require.NotEqual(t, selectedForwarder, conn.GetPath().GetPathSegments()[2].Name)
 }
+
+func Test_DiscoverForwarder_LeastActiveSelector(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+	defer cancel()
+	domain := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(1).
+		SetNSMgrProxySupplier(nil).
+		SetRegistryProxySupplier(nil).
+		SetNodeSetup(func(ctx context.Context, node *sandbox.Node, _ int) {
+			node.NewNSMgr(ctx, "nsmgr", nil, sandbox.GenerateTestToken, func(ctx context.Context, tokenGenerator token.GeneratorFunc, options ...nsmgr.Option) nsmgr.Nsmgr {
+				return nsmgr.NewServer(ctx, tokenGenerator, append(options, nsmgr.WithForwarderSelector(roundrobin.NewLeastActiveSelector()))...)
+			})
+		}).
+		Build()
+
+	const fwdCount = 3
+	for i := 0; i < fwdCount; i++ {
+		domain.Nodes[0].NewForwarder(ctx, &registry.NetworkServiceEndpoint{
+			Name:                sandbox.UniqueName("forwarder-" + fmt.Sprint(i)),
+			NetworkServiceNames: []string{"forwarder"},
+		}, sandbox.GenerateTestToken)
+	}
+
+	nsRegistryClient := domain.NewNSRegistryClient(ctx, sandbox.GenerateTestToken)
+
+	nsReg, err := nsRegistryClient.Register(ctx, defaultRegistryService(t.Name()))
+	require.NoError(t, err)
+
+	domain.Nodes[0].NewEndpoint(ctx, defaultRegistryEndpoint(nsReg.Name), sandbox.GenerateTestToken)
+
+	nsc := domain.Nodes[0].NewClient(ctx, sandbox.GenerateTestToken)
+
+	var conns []*networkservice.Connection
+	var forwarders = make(map[string]bool)
+	for i := 0; i < fwdCount; i++ {
+		conn, err := nsc.Request(ctx, defaultRequest(nsReg.Name))
+		require.NoError(t, err)
+
+		conns = append(conns, conn)
+		forwarders[conn.GetPath().GetPathSegments()[2].Name] = true
+	}
+	require.Len(t, forwarders, fwdCount)
+
+	_, err = nsc.Close(ctx, conns[1])
+	require.NoError(t, err)
+
+	conn, err := nsc.Request(ctx, defaultRequest(nsReg.Name))
+	require.NoError(t, err)
+	require.Equal(t, conns[1].GetPath().GetPathSegments()[2].Name, conn.GetPath().GetPathSegments()[2].Name)
+}
+
+func Test_DiscoverForwarder_CircuitBreakerKeepsSelectedForwarder(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+	defer cancel()
+	cb := circuitbreaker.NewCircuitBreaker(
+		circuitbreaker.WithFailureThreshold(1),
+		circuitbreaker.WithCoolDown(time.Hour),
+	)
+	domain := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(1).
+		SetNSMgrProxySupplier(nil).
+		SetRegistryProxySupplier(nil).
+		SetNodeSetup(func(ctx context.Context, node *sandbox.Node, _ int) {
+			node.NewNSMgr(ctx, "nsmgr", nil, sandbox.GenerateTestToken, func(ctx context.Context, tokenGenerator token.GeneratorFunc, options ...nsmgr.Option) nsmgr.Nsmgr {
+				return nsmgr.NewServer(ctx, tokenGenerator, append(options, nsmgr.WithForwarderCircuitBreaker(cb))...)
+			})
+		}).
+		Build()
+
+	for i := 0; i < 2; i++ {
+		domain.Nodes[0].NewForwarder(ctx, &registry.NetworkServiceEndpoint{
+			Name:                sandbox.UniqueName("forwarder-" + fmt.Sprint(i)),
+			NetworkServiceNames: []string{"forwarder"},
+		}, sandbox.GenerateTestToken)
+	}
+
+	nsRegistryClient := domain.NewNSRegistryClient(ctx, sandbox.GenerateTestToken)
+
+	nsReg, err := nsRegistryClient.Register(ctx, defaultRegistryService(t.Name()))
+	require.NoError(t, err)
+
+	domain.Nodes[0].NewEndpoint(ctx, defaultRegistryEndpoint(nsReg.Name), sandbox.GenerateTestToken)
+
+	request := defaultRequest(nsReg.Name)
+
+	nsc := domain.Nodes[0].NewClient(ctx, sandbox.GenerateTestToken)
+
+	conn, err := nsc.Request(ctx, request.Clone())
+	require.NoError(t, err)
+
+	selectedForwarder := conn.GetPath().GetPathSegments()[2].Name
+
+	// Open the circuit for the selected forwarder
+	cb.Report(ctx, selectedForwarder, errors.New("failure"))
+
+	// New connections avoid the forwarder with the open circuit
+	newConn, err := nsc.Request(ctx, defaultRequest(nsReg.Name))
+	require.NoError(t, err)
+	require.NotEqual(t, selectedForwarder, newConn.GetPath().GetPathSegments()[2].Name)
+
+	// Refresh keeps the established connection on the selected forwarder
+	request.Connection = conn
+	conn, err = nsc.Request(ctx, request.Clone())
+	require.NoError(t, err)
+	require.Equal(t, selectedForwarder, conn.GetPath().GetPathSegments()[2].Name)
+}
+
+func Test_Nsmgr_EndpointCircuitBreaker(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+	defer cancel()
+	cb := circuitbreaker.NewCircuitBreaker(
+		circuitbreaker.WithFailureThreshold(1),
+		circuitbreaker.WithCoolDown(time.Hour),
+	)
+	domain := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(1).
+		SetNSMgrProxySupplier(nil).
+		SetRegistryProxySupplier(nil).
+		SetNodeSetup(func(ctx context.Context, node *sandbox.Node, _ int) {
+			node.NewNSMgr(ctx, "nsmgr", nil, sandbox.GenerateTestToken, func(ctx context.Context, tokenGenerator token.GeneratorFunc, options ...nsmgr.Option) nsmgr.Nsmgr {
+				return nsmgr.NewServer(ctx, tokenGenerator, append(options, nsmgr.WithEndpointCircuitBreaker(cb))...)
+			})
+			node.NewForwarder(ctx, &registry.NetworkServiceEndpoint{
+				Name:                sandbox.UniqueName("forwarder"),
+				NetworkServiceNames: []string{"forwarder"},
+			}, sandbox.GenerateTestToken, sandbox.WithForwarderEndpointCircuitBreaker(cb))
+		}).
+		Build()
+
+	nsRegistryClient := domain.NewNSRegistryClient(ctx, sandbox.GenerateTestToken)
+
+	nsReg, err := nsRegistryClient.Register(ctx, defaultRegistryService(t.Name()))
+	require.NoError(t, err)
+
+	counter1 := new(count.Server)
+	nse1 := defaultRegistryEndpoint(nsReg.Name)
+	nse1.Name = "nse-1"
+	domain.Nodes[0].NewEndpoint(ctx, nse1, sandbox.GenerateTestToken, counter1)
+
+	request := defaultRequest(nsReg.Name)
+
+	// Heal is disabled so the retries keep the selected endpoint
+	nsc := domain.Nodes[0].NewClient(ctx, sandbox.GenerateTestToken, nsclient.WithHealClient(null.NewClient()))
+
+	conn, err := nsc.Request(ctx, request.Clone())
+	require.NoError(t, err)
+	require.Equal(t, "nse-1", conn.GetNetworkServiceEndpointName())
+	require.Equal(t, 1, counter1.Requests())
+
+	// Open the circuit for the endpoint
+	cb.Report(ctx, "nse-1", errors.New("failure"))
+
+	counter2 := new(count.Server)
+	nse2 := defaultRegistryEndpoint(nsReg.Name)
+	nse2.Name = "nse-2"
+	domain.Nodes[0].NewEndpoint(ctx, nse2, sandbox.GenerateTestToken, counter2)
+
+	// New connections avoid the endpoint with the open circuit
+	newConn, err := nsc.Request(ctx, defaultRequest(nsReg.Name))
+	require.NoError(t, err)
+	require.Equal(t, "nse-2", newConn.GetNetworkServiceEndpointName())
+	require.Equal(t, 1, counter2.Requests())
+
+	// New connection to the endpoint with the open circuit fails fast without reaching it
+	newRequest := defaultRequest(nsReg.Name)
+	newRequest.Connection.NetworkServiceEndpointName = "nse-1"
+	newRequestCtx, newRequestCancel := context.WithTimeout(ctx, time.Second)
+	defer newRequestCancel()
+	_, err = nsc.Request(newRequestCtx, newRequest)
+	require.Error(t, err)
+	require.Equal(t, 1, counter1.Requests())
+
+	// Refresh of the established connection reaches the endpoint with the open circuit
+	request.Connection = conn
+	conn, err = nsc.Request(ctx, request.Clone())
+	require.NoError(t, err)
+	require.Equal(t, "nse-1", conn.GetNetworkServiceEndpointName())
+	require.Equal(t, 2, counter1.Requests())
+}
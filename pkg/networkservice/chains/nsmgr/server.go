@@ -34,6 +34,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/client"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/endpoint"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/authorize"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/circuitbreaker"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/clientinfo"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/connect"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/discoverforwarder"
@@ -42,6 +43,8 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/sendfd"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/metrics"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/netsvcmonitor"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/null"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/roundrobin"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
 	"github.com/networkservicemesh/sdk/pkg/registry"
 	registryauthorize "github.com/networkservicemesh/sdk/pkg/registry/common/authorize"
@@ -92,6 +95,9 @@ type serverOptions struct {
 	name                             string
 	url                              string
 	forwarderServiceName             string
+	forwarderSelector                roundrobin.Selector
+	forwarderCircuitBreaker          *circuitbreaker.CircuitBreaker
+	endpointCircuitBreakerServer     networkservice.NetworkServiceServer
 }
 
 // Option modifies server option value
@@ -112,6 +118,32 @@ func WithForwarderServiceName(forwarderServiceName string) Option {
 	}
 }
 
+// WithForwarderSelector sets the roundrobin.Selector used to choose among the forwarders
+// By default forwarders are tried in the registry order
+func WithForwarderSelector(selector roundrobin.Selector) Option {
+	return func(o *serverOptions) {
+		o.forwarderSelector = selector
+	}
+}
+
+// WithForwarderCircuitBreaker sets the circuitbreaker.CircuitBreaker excluding failing forwarders
+func WithForwarderCircuitBreaker(cb *circuitbreaker.CircuitBreaker) Option {
+	return func(o *serverOptions) {
+		o.forwarderCircuitBreaker = cb
+	}
+}
+
+// WithEndpointCircuitBreaker sets the circuitbreaker.CircuitBreaker failing fast the requests to the network service
+// endpoints with open circuits. The refreshes of the established connections are not affected.
+func WithEndpointCircuitBreaker(cb *circuitbreaker.CircuitBreaker) Option {
+	if cb == nil {
+		panic("endpoint circuit breaker cannot be nil")
+	}
+	return func(o *serverOptions) {
+		o.endpointCircuitBreakerServer = circuitbreaker.NewServer(cb)
+	}
+}
+
 // WithDefaultExpiration sets the default expiration for endpoints
 func WithDefaultExpiration(d time.Duration) Option {
 	return func(o *serverOptions) {
@@ -226,13 +258,14 @@ func NewServer(ctx context.Context, tokenGenerator token.GeneratorFunc, options
 		dialTimeout:                      time.Millisecond * 300,
 		name:                             "nsmgr-" + uuid.New().String(),
 		forwarderServiceName:             "forwarder",
+		endpointCircuitBreakerServer:     null.NewServer(),
 	}
 	for _, opt := range options {
 		opt(opts)
 	}
 
 	rv := &nsmgrServer{}
-	var nsRegistry = memory.NewNetworkServiceRegistryServer()
+	var nsRegistry = memory.NewNetworkServiceRegistryServer(memory.WithContext(ctx))
 	if opts.regURL != nil {
 		// Use remote registry
 		nsRegistry = registryconnect.NewNetworkServiceRegistryServer(
@@ -278,7 +311,7 @@ func NewServer(ctx context.Context, tokenGenerator token.GeneratorFunc, options
 
 	if opts.regURL == nil {
 		remoteOrLocalRegistry = chain.NewNetworkServiceEndpointRegistryServer(
-			memory.NewNetworkServiceEndpointRegistryServer(),
+			memory.NewNetworkServiceEndpointRegistryServer(memory.WithContext(ctx)),
 			localbypass.NewNetworkServiceEndpointRegistryServer(opts.url),
 		)
 	}
@@ -306,7 +339,10 @@ func NewServer(ctx context.Context, tokenGenerator token.GeneratorFunc, options
 				registryadapter.NetworkServiceEndpointServerToClient(remoteOrLocalRegistry),
 				discoverforwarder.WithForwarderServiceName(opts.forwarderServiceName),
 				discoverforwarder.WithNSMgrURL(opts.url),
+				discoverforwarder.WithSelector(opts.forwarderSelector),
+				discoverforwarder.WithCircuitBreaker(opts.forwarderCircuitBreaker),
 			),
+			opts.endpointCircuitBreakerServer,
 			netsvcmonitor.NewServer(ctx,
 				registryadapter.NetworkServiceServerToClient(nsRegistry),
 				registryadapter.NetworkServiceEndpointServerToClient(remoteOrLocalRegistry),
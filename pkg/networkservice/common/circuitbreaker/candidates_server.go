@@ -0,0 +1,66 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreaker
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/discover"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/clienturlctx"
+)
+
+type candidatesServer struct {
+	cb *CircuitBreaker
+}
+
+// NewCandidatesServer - returns a new NetworkServiceServer chain element that excludes the candidates with open
+// circuits from discover.Candidates(ctx). It should be placed right after discover.
+func NewCandidatesServer(cb *CircuitBreaker) networkservice.NetworkServiceServer {
+	return &candidatesServer{
+		cb: cb,
+	}
+}
+
+func (s *candidatesServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	candidates := discover.Candidates(ctx)
+	if clienturlctx.ClientURL(ctx) != nil || candidates == nil {
+		return next.Server(ctx).Request(ctx, request)
+	}
+
+	var nses []*registry.NetworkServiceEndpoint
+	for _, nse := range candidates.Endpoints {
+		if !s.cb.IsOpen(ctx, nse.GetName()) {
+			nses = append(nses, nse)
+		}
+	}
+	if len(nses) == 0 {
+		return nil, errors.Errorf("all network service endpoint candidates for %v have open circuits", candidates.NetworkService.GetName())
+	}
+
+	return next.Server(ctx).Request(discover.WithCandidates(ctx, nses, candidates.NetworkService), request)
+}
+
+func (s *candidatesServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package circuitbreaker provides networkservice chain elements excluding the candidates which keep failing for a
+// cool-down period
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultCoolDown         = 30 * time.Second
+)
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (c *circuit) isOpen() bool {
+	return !c.openUntil.IsZero()
+}
+
+// CircuitBreaker keeps the circuit states per candidate name. The circuit opens after failureThreshold consecutive
+// failures and stays open for the cool-down period. After that a single probe request is allowed: the circuit closes if
+// it succeeds and opens again otherwise.
+type CircuitBreaker struct {
+	failureThreshold int
+	coolDown         time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// NewCircuitBreaker creates a new CircuitBreaker
+func NewCircuitBreaker(opts ...Option) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		failureThreshold: defaultFailureThreshold,
+		coolDown:         defaultCoolDown,
+		circuits:         make(map[string]*circuit),
+	}
+	for _, opt := range opts {
+		opt(cb)
+	}
+	return cb
+}
+
+// IsOpen returns true if the requests to the candidate with the name should not be made now: the circuit is open and
+// either the cool-down period has not passed yet or the probe request is already in progress.
+func (cb *CircuitBreaker) IsOpen(ctx context.Context, name string) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c, ok := cb.circuits[name]
+	if !ok || !c.isOpen() {
+		return false
+	}
+	return c.probing || clock.FromContext(ctx).Now().Before(c.openUntil)
+}
+
+// Allow returns true if the request to the candidate with the name can be made now. If the circuit is open and the
+// cool-down period has passed, the caller becomes the probe and must Report the result.
+func (cb *CircuitBreaker) Allow(ctx context.Context, name string) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c, ok := cb.circuits[name]
+	if !ok || !c.isOpen() {
+		return true
+	}
+	if c.probing || clock.FromContext(ctx).Now().Before(c.openUntil) {
+		return false
+	}
+	c.probing = true
+	return true
+}
+
+// Report records the result of the request to the candidate with the name. Failures caused by the ctx cancellation
+// are not counted.
+func (cb *CircuitBreaker) Report(ctx context.Context, name string, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err == nil {
+		delete(cb.circuits, name)
+		return
+	}
+
+	c, ok := cb.circuits[name]
+	if ctx.Err() != nil {
+		if ok {
+			c.probing = false
+		}
+		return
+	}
+	if !ok {
+		c = new(circuit)
+		cb.circuits[name] = c
+	}
+	c.failures++
+	if c.probing || c.failures >= cb.failureThreshold {
+		c.openUntil = clock.FromContext(ctx).Now().Add(cb.coolDown)
+		c.probing = false
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreaker
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+)
+
+type endpointNameMetadataKey struct{}
+
+func loadEndpointName(ctx context.Context) string {
+	if v, ok := metadata.Map(ctx, false).Load(endpointNameMetadataKey{}); ok {
+		return v.(string)
+	}
+	return ""
+}
+
+func storeEndpointName(ctx context.Context, name string) {
+	metadata.Map(ctx, false).Store(endpointNameMetadataKey{}, name)
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreaker
+
+import "time"
+
+// Option is an option pattern for NewCircuitBreaker
+type Option func(cb *CircuitBreaker)
+
+// WithFailureThreshold sets the number of consecutive failures opening the circuit. Default: 5
+func WithFailureThreshold(threshold int) Option {
+	return func(cb *CircuitBreaker) {
+		cb.failureThreshold = threshold
+	}
+}
+
+// WithCoolDown sets the period the open circuit excludes the candidate for. Default: 30s
+func WithCoolDown(coolDown time.Duration) Option {
+	return func(cb *CircuitBreaker) {
+		cb.coolDown = coolDown
+	}
+}
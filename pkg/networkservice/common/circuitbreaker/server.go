@@ -0,0 +1,71 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreaker
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+type circuitBreakerServer struct {
+	cb *CircuitBreaker
+}
+
+// NewServer - returns a new NetworkServiceServer chain element that records the results of the requests per
+// network service endpoint name and fails fast the requests to the endpoints with open circuits. The refreshes of the
+// connections established with the endpoint are always let through. It should be placed after the chain element
+// selecting the endpoint (e.g. roundrobin) and requires metadata in the chain.
+func NewServer(cb *CircuitBreaker) networkservice.NetworkServiceServer {
+	return &circuitBreakerServer{
+		cb: cb,
+	}
+}
+
+func (s *circuitBreakerServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	nseName := request.GetConnection().GetNetworkServiceEndpointName()
+	if nseName == "" {
+		conn, err := next.Server(ctx).Request(ctx, request)
+		if err == nil {
+			storeEndpointName(ctx, conn.GetNetworkServiceEndpointName())
+		}
+		return conn, err
+	}
+
+	// The circuit breaker only steers new connections: the endpoint already serving the connection is always tried
+	// so an open circuit doesn't tear down the established connection.
+	established := nseName == loadEndpointName(ctx)
+	if !established && !s.cb.Allow(ctx, nseName) {
+		return nil, errors.Errorf("circuit is open for network service endpoint %v", nseName)
+	}
+
+	conn, err := next.Server(ctx).Request(ctx, request)
+	s.cb.Report(ctx, nseName, err)
+	if err == nil {
+		storeEndpointName(ctx, conn.GetNetworkServiceEndpointName())
+	}
+	return conn, err
+}
+
+func (s *circuitBreakerServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreaker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/circuitbreaker"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/discover"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/roundrobin"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/switchcase"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/count"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
+)
+
+const (
+	ns       = "ns"
+	nse1     = "nse-1"
+	nse2     = "nse-2"
+	coolDown = time.Minute
+)
+
+func TestCircuitBreaker_OpenProbeClose(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	cb := circuitbreaker.NewCircuitBreaker(
+		circuitbreaker.WithFailureThreshold(2),
+		circuitbreaker.WithCoolDown(coolDown),
+	)
+	failure := errors.New("failure")
+
+	cb.Report(ctx, nse1, failure)
+	require.False(t, cb.IsOpen(ctx, nse1))
+
+	cb.Report(ctx, nse1, failure)
+	require.True(t, cb.IsOpen(ctx, nse1))
+	require.False(t, cb.Allow(ctx, nse1))
+
+	clockMock.Add(coolDown)
+	require.False(t, cb.IsOpen(ctx, nse1))
+
+	// Only a single probe is allowed
+	require.True(t, cb.Allow(ctx, nse1))
+	require.True(t, cb.IsOpen(ctx, nse1))
+	require.False(t, cb.Allow(ctx, nse1))
+
+	// Failed probe opens the circuit again
+	cb.Report(ctx, nse1, failure)
+	require.True(t, cb.IsOpen(ctx, nse1))
+
+	clockMock.Add(coolDown)
+	require.True(t, cb.Allow(ctx, nse1))
+
+	// Successful probe closes the circuit
+	cb.Report(ctx, nse1, nil)
+	require.False(t, cb.IsOpen(ctx, nse1))
+	require.True(t, cb.Allow(ctx, nse1))
+
+	cb.Report(ctx, nse1, failure)
+	require.False(t, cb.IsOpen(ctx, nse1))
+}
+
+func TestCircuitBreaker_CanceledProbe(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	cb := circuitbreaker.NewCircuitBreaker(
+		circuitbreaker.WithFailureThreshold(1),
+		circuitbreaker.WithCoolDown(coolDown),
+	)
+
+	cb.Report(ctx, nse1, errors.New("failure"))
+	clockMock.Add(coolDown)
+	require.True(t, cb.Allow(ctx, nse1))
+
+	requestCtx, requestCancel := context.WithCancel(ctx)
+	requestCancel()
+	cb.Report(requestCtx, nse1, requestCtx.Err())
+
+	require.False(t, cb.IsOpen(ctx, nse1))
+	require.True(t, cb.Allow(ctx, nse1))
+}
+
+func TestCircuitBreakerServer_ExcludesFailingCandidate(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	cb := circuitbreaker.NewCircuitBreaker(
+		circuitbreaker.WithFailureThreshold(1),
+		circuitbreaker.WithCoolDown(coolDown),
+	)
+
+	counter := new(count.Server)
+	s := next.NewNetworkServiceServer(
+		metadata.NewServer(),
+		circuitbreaker.NewCandidatesServer(cb),
+		roundrobin.NewServer(),
+		circuitbreaker.NewServer(cb),
+		switchcase.NewServer(&switchcase.ServerCase{
+			Condition: func(_ context.Context, conn *networkservice.Connection) bool {
+				return conn.GetNetworkServiceEndpointName() == nse1
+			},
+			Server: next.NewNetworkServiceServer(counter, injecterror.NewServer()),
+		}),
+	)
+
+	ctx = discover.WithCandidates(ctx, []*registry.NetworkServiceEndpoint{
+		{Name: nse1, Url: "unix://" + nse1},
+		{Name: nse2, Url: "unix://" + nse2},
+	}, &registry.NetworkService{Name: ns})
+
+	request := func() *networkservice.Connection {
+		conn, err := s.Request(ctx, &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{Id: uuid.NewString(), NetworkService: ns},
+		})
+		require.NoError(t, err)
+		return conn
+	}
+
+	require.Equal(t, nse2, request().GetNetworkServiceEndpointName())
+	require.Equal(t, 1, counter.Requests())
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, nse2, request().GetNetworkServiceEndpointName())
+	}
+	require.Equal(t, 1, counter.Requests())
+
+	clockMock.Add(coolDown)
+
+	// The probe request fails and the circuit opens again
+	for i := 0; i < 2; i++ {
+		require.Equal(t, nse2, request().GetNetworkServiceEndpointName())
+	}
+	require.Equal(t, 2, counter.Requests())
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, nse2, request().GetNetworkServiceEndpointName())
+	}
+	require.Equal(t, 2, counter.Requests())
+}
+
+func TestCircuitBreakerServer_RefreshIsLetThrough(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	cb := circuitbreaker.NewCircuitBreaker(
+		circuitbreaker.WithFailureThreshold(1),
+		circuitbreaker.WithCoolDown(coolDown),
+	)
+
+	counter := new(count.Server)
+	s := next.NewNetworkServiceServer(
+		metadata.NewServer(),
+		circuitbreaker.NewServer(cb),
+		counter,
+	)
+
+	conn, err := s.Request(ctx, &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "id", NetworkService: ns, NetworkServiceEndpointName: nse1},
+	})
+	require.NoError(t, err)
+
+	cb.Report(ctx, nse1, errors.New("failure"))
+	require.True(t, cb.IsOpen(ctx, nse1))
+
+	// New connection to the endpoint with the open circuit fails fast
+	_, err = s.Request(ctx, &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "new-id", NetworkService: ns, NetworkServiceEndpointName: nse1},
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, counter.Requests())
+
+	// Refresh of the established connection reaches the endpoint
+	_, err = s.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	require.Equal(t, 2, counter.Requests())
+}
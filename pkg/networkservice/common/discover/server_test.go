@@ -1,6 +1,6 @@
 // Copyright (c) 2020-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -37,6 +37,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
 	registryadapters "github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
 	registrynext "github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/drain"
 	"github.com/networkservicemesh/sdk/pkg/tools/clienturlctx"
 	"github.com/networkservicemesh/sdk/pkg/tools/matchutils"
 )
@@ -336,6 +337,52 @@ func TestDiscoverCandidatesServer_MatchSelectedNSE(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestDiscoverCandidatesServer_SkipDrainingNSE(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), testWait)
+	defer cancel()
+
+	nsName := networkServiceName()
+	nses := endpoints()
+	drain.Set(nses[0], time.Time{})
+
+	nsServer, nseServer := testServers(t, nsName, nses)
+
+	server := next.NewNetworkServiceServer(
+		discover.NewServer(
+			registryadapters.NetworkServiceServerToClient(nsServer),
+			registryadapters.NetworkServiceEndpointServerToClient(nseServer)),
+		checkcontext.NewServer(t, func(t *testing.T, ctx context.Context) {
+			if clienturlctx.ClientURL(ctx) != nil {
+				return
+			}
+			candidates := discover.Candidates(ctx).Endpoints
+			require.Len(t, candidates, 2)
+			for _, nse := range candidates {
+				require.NotEqual(t, nses[0].Name, nse.Name)
+			}
+		}),
+	)
+
+	// New connection
+	_, err := server.Request(ctx, &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			NetworkService: nsName,
+		},
+	})
+	require.NoError(t, err)
+
+	// Existing connection to the draining NSE
+	_, err = server.Request(ctx, &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			NetworkService:             nsName,
+			NetworkServiceEndpointName: nses[0].Name,
+		},
+	})
+	require.NoError(t, err)
+}
+
 func TestDiscoverCandidatesServer_NoMatchServiceFound(t *testing.T) {
 	t.Cleanup(func() { goleak.VerifyNone(t) })
 
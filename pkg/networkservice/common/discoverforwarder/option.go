@@ -16,6 +16,11 @@
 
 package discoverforwarder
 
+import (
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/circuitbreaker"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/roundrobin"
+)
+
 // Option changes default settings for the discoverForwarderServer
 type Option func(*discoverForwarderServer)
 
@@ -33,3 +38,20 @@ func WithNSMgrURL(nsmgrURL string) Option {
 		d.nsmgrURL = nsmgrURL
 	}
 }
+
+// WithSelector sets the roundrobin.Selector used to choose among the forwarders. If the selector is also a
+// roundrobin.ConnectionTracker, it is notified about connections established and closed via the forwarders.
+// By default forwarders are tried in the registry order.
+func WithSelector(selector roundrobin.Selector) Option {
+	return func(d *discoverForwarderServer) {
+		d.selector = selector
+	}
+}
+
+// WithCircuitBreaker sets the circuitbreaker.CircuitBreaker recording the forwarder failures. Forwarders with open
+// circuits are not selected for new connections, the forwarder already selected for the connection is kept.
+func WithCircuitBreaker(cb *circuitbreaker.CircuitBreaker) Option {
+	return func(d *discoverForwarderServer) {
+		d.circuitBreaker = cb
+	}
+}
@@ -1,6 +1,6 @@
 // Copyright (c) 2021-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -28,6 +28,8 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/registry"
 	"github.com/pkg/errors"
 
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/circuitbreaker"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/roundrobin"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"github.com/networkservicemesh/sdk/pkg/tools/clienturlctx"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
@@ -39,6 +41,8 @@ type discoverForwarderServer struct {
 	nsClient             registry.NetworkServiceRegistryClient
 	forwarderServiceName string
 	nsmgrURL             string
+	selector             roundrobin.Selector
+	circuitBreaker       *circuitbreaker.CircuitBreaker
 }
 
 // NewServer creates new instance of discoverforwarder networkservice.NetworkServiceServer.
@@ -100,22 +104,17 @@ func (d *discoverForwarderServer) Request(ctx context.Context, request *networks
 		return nil, errors.New("no candidates found")
 	}
 
+	var stickyName string
 	if forwarderName == "" && request.GetConnection().GetState() != networkservice.State_RESELECT_REQUESTED {
 		segments := request.Connection.GetPath().GetPathSegments()
 		if pathIndex := int(request.Connection.GetPath().Index); len(segments) > pathIndex+1 {
-			for i, candidate := range nses {
-				if candidate.Name == segments[pathIndex+1].GetName() {
-					nses[0], nses[i] = nses[i], nses[0]
-					break
-				}
-			}
+			stickyName = segments[pathIndex+1].GetName()
 		}
 	}
+	nses = d.orderForwarders(ctx, request.GetConnection(), ns, nses, stickyName)
 
 	var candidatesErr = errors.New("all forwarders have failed")
 
-	// TODO: Should we consider about load balancing?
-	// https://github.com/networkservicemesh/sdk/issues/790
 	for i, candidate := range nses {
 		u, err := url.Parse(candidate.Url)
 		if err != nil {
@@ -123,11 +122,26 @@ func (d *discoverForwarderServer) Request(ctx context.Context, request *networks
 			continue
 		}
 
+		// The circuit breaker only steers new selections: the forwarder already serving the connection is always tried
+		// so an open circuit doesn't tear down the established connection.
+		sticky := candidate.GetName() == forwarderName || candidate.GetName() == stickyName
+		if d.circuitBreaker != nil && !sticky && !d.circuitBreaker.Allow(ctx, candidate.GetName()) {
+			logger.Warnf("forwarder=%v is skipped: circuit is open", candidate.Name)
+			candidatesErr = errors.Wrapf(candidatesErr, "%v. Circuit is open for forwarder %v", i, candidate.Name)
+			continue
+		}
+
 		resp, err := next.Server(ctx).Request(clienturlctx.WithClientURL(ctx, u), request.Clone())
+		if d.circuitBreaker != nil {
+			d.circuitBreaker.Report(ctx, candidate.GetName(), err)
+		}
 		if err == nil {
 			if forwarderName == "" {
 				storeForwarderName(ctx, candidate.GetName())
 			}
+			if tracker, ok := d.selector.(roundrobin.ConnectionTracker); ok {
+				tracker.Connected(resp.GetId(), candidate.GetName())
+			}
 			return resp, nil
 		}
 		logger.Errorf("forwarder=%v url=%v returned error=%v", candidate.Name, candidate.Url, err.Error())
@@ -141,6 +155,10 @@ func (d *discoverForwarderServer) Close(ctx context.Context, conn *networkservic
 	// Unlike Request, Close method should always call next element in chain
 	// to make sure we clear resources in the current app.
 
+	if tracker, ok := d.selector.(roundrobin.ConnectionTracker); ok {
+		tracker.Disconnected(conn.GetId())
+	}
+
 	var forwarderName = loadForwarderName(ctx)
 
 	if forwarderName == "" {
@@ -183,6 +201,33 @@ func (d *discoverForwarderServer) Close(ctx context.Context, conn *networkservic
 	return next.Server(ctx).Close(ctx, conn)
 }
 
+// orderForwarders returns nses in the order they should be tried: the forwarder with stickyName goes first, then the
+// forwarder chosen by the selector, then the rest in the registry order.
+func (d *discoverForwarderServer) orderForwarders(ctx context.Context, conn *networkservice.Connection, ns *registry.NetworkService, nses []*registry.NetworkServiceEndpoint, stickyName string) []*registry.NetworkServiceEndpoint {
+	var result = make([]*registry.NetworkServiceEndpoint, 0, len(nses))
+	var rest = make([]*registry.NetworkServiceEndpoint, 0, len(nses))
+	for _, nse := range nses {
+		if stickyName != "" && nse.GetName() == stickyName {
+			result = append(result, nse)
+			continue
+		}
+		rest = append(rest, nse)
+	}
+
+	if d.selector != nil && len(rest) > 0 {
+		selected := d.selector.Select(ctx, conn, ns, rest)
+		for i := range rest {
+			if rest[i] == selected {
+				result = append(result, selected)
+				rest = append(rest[:i], rest[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return append(result, rest...)
+}
+
 func (d *discoverForwarderServer) matchForwarders(nsLabels map[string]string, ns *registry.NetworkService, nses []*registry.NetworkServiceEndpoint) []*registry.NetworkServiceEndpoint {
 	var result []*registry.NetworkServiceEndpoint
 
@@ -0,0 +1,55 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package locality
+
+const (
+	defaultNodeLabel    = "nodeName"
+	defaultZoneLabel    = "topology.kubernetes.io/zone"
+	defaultClusterLabel = "clusterName"
+)
+
+// Option is an option pattern for NewServer
+type Option func(s *localityServer)
+
+// WithNodeLabel sets the label key holding the node name. Default: "nodeName"
+func WithNodeLabel(key string) Option {
+	return func(s *localityServer) {
+		s.labelKeys[nodeLevel] = key
+	}
+}
+
+// WithZoneLabel sets the label key holding the zone name. Default: "topology.kubernetes.io/zone"
+func WithZoneLabel(key string) Option {
+	return func(s *localityServer) {
+		s.labelKeys[zoneLevel] = key
+	}
+}
+
+// WithClusterLabel sets the label key holding the cluster name. Default: "clusterName"
+func WithClusterLabel(key string) Option {
+	return func(s *localityServer) {
+		s.labelKeys[clusterLevel] = key
+	}
+}
+
+// WithSpilloverThreshold sets the minimal number of candidates offered to the next chain elements at once. If the
+// closest locality level has less candidates, candidates from the following levels are added to it. Default: 1
+func WithSpilloverThreshold(threshold int) Option {
+	return func(s *localityServer) {
+		s.spilloverThreshold = threshold
+	}
+}
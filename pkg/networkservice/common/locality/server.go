@@ -0,0 +1,133 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package locality provides a networkservice chain element that prefers the candidates located closer to the client
+package locality
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/discover"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/clienturlctx"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+const (
+	nodeLevel = iota
+	zoneLevel
+	clusterLevel
+	levelsCount
+)
+
+type localityServer struct {
+	labelKeys          [levelsCount]string
+	spilloverThreshold int
+}
+
+// NewServer - returns a new NetworkServiceServer chain element that splits the candidates provided by
+// discover.Candidates(ctx) into groups by their locality relative to the client: sharing node, then zone, then
+// cluster, then the rest. Groups are passed to the next chain element one by one until the Request succeeds, so it
+// should be placed between discover and roundrobin.
+//
+// Locality is determined by comparing the connection labels with the candidate labels for the requested network
+// service, so clientinfo and clusterinfo chain elements should be used on the both sides.
+func NewServer(opts ...Option) networkservice.NetworkServiceServer {
+	s := &localityServer{
+		labelKeys:          [levelsCount]string{defaultNodeLabel, defaultZoneLabel, defaultClusterLabel},
+		spilloverThreshold: 1,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *localityServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	candidates := discover.Candidates(ctx)
+	if clienturlctx.ClientURL(ctx) != nil || candidates == nil {
+		return next.Server(ctx).Request(ctx, request)
+	}
+
+	groups := s.group(request.GetConnection().GetLabels(), candidates.NetworkService, candidates.Endpoints)
+	if len(groups) < 2 {
+		return next.Server(ctx).Request(ctx, request)
+	}
+
+	var groupsErr = errors.New("all locality groups have failed")
+	for i, group := range groups {
+		resp, err := next.Server(ctx).Request(discover.WithCandidates(ctx, group, candidates.NetworkService), request.Clone())
+		if err == nil {
+			return resp, nil
+		}
+		log.FromContext(ctx).WithField("localityServer", "Request").Warnf("locality group %v has failed, spilling over: %v", i, err.Error())
+		groupsErr = errors.Wrapf(groupsErr, "%v. An error during request with locality group --> %v", i, err.Error())
+	}
+	return nil, groupsErr
+}
+
+func (s *localityServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+// group splits nses into the groups ordered by locality. Each group except the last one has at least
+// spilloverThreshold candidates.
+func (s *localityServer) group(labels map[string]string, ns *registry.NetworkService, nses []*registry.NetworkServiceEndpoint) [][]*registry.NetworkServiceEndpoint {
+	var levels [levelsCount + 1][]*registry.NetworkServiceEndpoint
+	for _, nse := range nses {
+		level := s.level(labels, nse.GetNetworkServiceLabels()[ns.GetName()].GetLabels())
+		levels[level] = append(levels[level], nse)
+	}
+
+	var result [][]*registry.NetworkServiceEndpoint
+	var group []*registry.NetworkServiceEndpoint
+	for _, level := range levels {
+		group = append(group, level...)
+		if len(group) > 0 && len(group) >= s.spilloverThreshold {
+			result = append(result, group)
+			group = nil
+		}
+	}
+	if len(group) > 0 {
+		result = append(result, group)
+	}
+	return result
+}
+
+// level returns the closest locality level shared by the client and the candidate or levelsCount if there is no such
+// level. Closer levels are considered only if all the wider levels known to the client are shared too, so nodes with
+// the same name in different clusters are not treated as the same node.
+func (s *localityServer) level(clientLabels, nseLabels map[string]string) int {
+	result := levelsCount
+	for level := levelsCount - 1; level >= 0; level-- {
+		value := clientLabels[s.labelKeys[level]]
+		switch {
+		case value == "":
+			continue
+		case nseLabels[s.labelKeys[level]] == value:
+			result = level
+		default:
+			return result
+		}
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package locality_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/discover"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/locality"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/roundrobin"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/switchcase"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+)
+
+const (
+	ns         = "ns"
+	nodeKey    = "nodeName"
+	zoneKey    = "zone"
+	clusterKey = "clusterName"
+)
+
+func testNSE(name, node, zone, cluster string) *registry.NetworkServiceEndpoint {
+	return &registry.NetworkServiceEndpoint{
+		Name:                name,
+		Url:                 "unix://" + name,
+		NetworkServiceNames: []string{ns},
+		NetworkServiceLabels: map[string]*registry.NetworkServiceLabels{
+			ns: {
+				Labels: map[string]string{
+					nodeKey:    node,
+					zoneKey:    zone,
+					clusterKey: cluster,
+				},
+			},
+		},
+	}
+}
+
+func testCandidates() []*registry.NetworkServiceEndpoint {
+	return []*registry.NetworkServiceEndpoint{
+		testNSE("remote", "node-4", "zone-3", "cluster-2"),
+		testNSE("same-cluster", "node-3", "zone-2", "cluster-1"),
+		testNSE("same-zone", "node-2", "zone-1", "cluster-1"),
+		testNSE("same-node", "node-1", "zone-1", "cluster-1"),
+		testNSE("other-cluster-node", "node-1", "zone-1", "cluster-2"),
+	}
+}
+
+func testRequest() *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			NetworkService: ns,
+			Labels: map[string]string{
+				nodeKey:    "node-1",
+				zoneKey:    "zone-1",
+				clusterKey: "cluster-1",
+			},
+		},
+	}
+}
+
+func failingServer(names ...string) networkservice.NetworkServiceServer {
+	var cases []*switchcase.ServerCase
+	for _, name := range names {
+		name := name
+		cases = append(cases, &switchcase.ServerCase{
+			Condition: func(_ context.Context, conn *networkservice.Connection) bool {
+				return conn.GetNetworkServiceEndpointName() == name
+			},
+			Server: injecterror.NewServer(),
+		})
+	}
+	return switchcase.NewServer(cases...)
+}
+
+func TestLocalityServer_PrefersCloserCandidates(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceServer(
+		locality.NewServer(locality.WithZoneLabel(zoneKey)),
+		roundrobin.NewServer(),
+		failingServer("same-node", "same-zone"),
+	)
+
+	ctx := discover.WithCandidates(context.Background(), testCandidates(), &registry.NetworkService{Name: ns})
+
+	conn, err := s.Request(ctx, testRequest())
+	require.NoError(t, err)
+	require.Equal(t, "same-cluster", conn.GetNetworkServiceEndpointName())
+}
+
+func TestLocalityServer_SameNode(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceServer(
+		locality.NewServer(locality.WithZoneLabel(zoneKey)),
+		roundrobin.NewServer(),
+	)
+
+	ctx := discover.WithCandidates(context.Background(), testCandidates(), &registry.NetworkService{Name: ns})
+
+	for i := 0; i < 3; i++ {
+		conn, err := s.Request(ctx, testRequest())
+		require.NoError(t, err)
+		require.Equal(t, "same-node", conn.GetNetworkServiceEndpointName())
+	}
+}
+
+func TestLocalityServer_SpilloverThreshold(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceServer(
+		locality.NewServer(
+			locality.WithZoneLabel(zoneKey),
+			locality.WithSpilloverThreshold(2),
+		),
+		roundrobin.NewServer(),
+	)
+
+	ctx := discover.WithCandidates(context.Background(), testCandidates(), &registry.NetworkService{Name: ns})
+
+	var selected = make(map[string]int)
+	for i := 0; i < 4; i++ {
+		conn, err := s.Request(ctx, testRequest())
+		require.NoError(t, err)
+		selected[conn.GetNetworkServiceEndpointName()]++
+	}
+	require.Equal(t, map[string]int{"same-node": 2, "same-zone": 2}, selected)
+}
+
+func TestLocalityServer_AllFailed(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceServer(
+		locality.NewServer(locality.WithZoneLabel(zoneKey)),
+		roundrobin.NewServer(),
+		injecterror.NewServer(),
+	)
+
+	ctx := discover.WithCandidates(context.Background(), testCandidates(), &registry.NetworkService{Name: ns})
+
+	_, err := s.Request(ctx, testRequest())
+	require.Error(t, err)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundrobin
+
+import "sync"
+
+// connectionCounter counts active connections per endpoint
+type connectionCounter struct {
+	mu          sync.Mutex
+	connections map[string]string
+	active      map[string]int
+}
+
+func newConnectionCounter() *connectionCounter {
+	return &connectionCounter{
+		connections: make(map[string]string),
+		active:      make(map[string]int),
+	}
+}
+
+// Connected implements ConnectionTracker
+func (c *connectionCounter) Connected(connID, nseName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if prev, ok := c.connections[connID]; ok {
+		if prev == nseName {
+			return
+		}
+		c.release(prev)
+	}
+	c.connections[connID] = nseName
+	c.active[nseName]++
+}
+
+// Disconnected implements ConnectionTracker
+func (c *connectionCounter) Disconnected(connID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if nseName, ok := c.connections[connID]; ok {
+		delete(c.connections, connID)
+		c.release(nseName)
+	}
+}
+
+func (c *connectionCounter) release(nseName string) {
+	if c.active[nseName]--; c.active[nseName] <= 0 {
+		delete(c.active, nseName)
+	}
+}
+
+func (c *connectionCounter) count(nseName string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.active[nseName]
+}
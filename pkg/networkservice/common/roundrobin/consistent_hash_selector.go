@@ -0,0 +1,66 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundrobin
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+type consistentHashSelector struct {
+	labelKey string
+}
+
+// NewConsistentHashSelector - returns a Selector that consistently maps the value of the connection label with
+// labelKey to one of the candidates, so the connections having the same label value are going to the same endpoint
+// while it is alive. Rendezvous hashing is used, so only the connections mapped to the disappeared endpoint are
+// remapped when the candidates change. The connection ID is used as a key if the connection doesn't have the label.
+func NewConsistentHashSelector(labelKey string) Selector {
+	return &consistentHashSelector{
+		labelKey: labelKey,
+	}
+}
+
+func (s *consistentHashSelector) Select(_ context.Context, conn *networkservice.Connection, _ *registry.NetworkService, candidates []*registry.NetworkServiceEndpoint) *registry.NetworkServiceEndpoint {
+	key, ok := conn.GetLabels()[s.labelKey]
+	if !ok {
+		key = conn.GetId()
+	}
+
+	var result *registry.NetworkServiceEndpoint
+	var maxScore uint64
+	for _, candidate := range candidates {
+		if candidate == nil {
+			continue
+		}
+		if score := rendezvousScore(key, candidate.GetName()); result == nil || score > maxScore {
+			result, maxScore = candidate, score
+		}
+	}
+	return result
+}
+
+func rendezvousScore(key, nseName string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(nseName))
+	return h.Sum64()
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundrobin
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+type leastActiveSelector struct {
+	*connectionCounter
+}
+
+// NewLeastActiveSelector - returns a Selector that selects the candidate with the least number of active connections.
+// Ties are resolved in favor of the candidate coming first.
+func NewLeastActiveSelector() Selector {
+	return &leastActiveSelector{
+		connectionCounter: newConnectionCounter(),
+	}
+}
+
+func (s *leastActiveSelector) Select(_ context.Context, _ *networkservice.Connection, _ *registry.NetworkService, candidates []*registry.NetworkServiceEndpoint) *registry.NetworkServiceEndpoint {
+	var result *registry.NetworkServiceEndpoint
+	var minCount int
+	for _, candidate := range candidates {
+		if candidate == nil {
+			continue
+		}
+		if count := s.count(candidate.GetName()); result == nil || count < minCount {
+			result, minCount = candidate, count
+		}
+	}
+	return result
+}
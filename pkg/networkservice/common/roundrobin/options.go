@@ -0,0 +1,34 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundrobin
+
+// Option is an option pattern for NewServer
+type Option func(s *selectEndpointServer)
+
+// WithSelector sets the default Selector used for all network services. Round robin is used by default.
+func WithSelector(selector Selector) Option {
+	return func(s *selectEndpointServer) {
+		s.selector = selector
+	}
+}
+
+// WithNetworkServiceSelector sets the Selector used for the network service with nsName
+func WithNetworkServiceSelector(nsName string, selector Selector) Option {
+	return func(s *selectEndpointServer) {
+		s.nsSelectors[nsName] = selector
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundrobin
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+type powerOfTwoSelector struct {
+	*connectionCounter
+}
+
+// NewPowerOfTwoSelector - returns a Selector that picks two random candidates and selects the one with the fewer
+// active connections.
+func NewPowerOfTwoSelector() Selector {
+	return &powerOfTwoSelector{
+		connectionCounter: newConnectionCounter(),
+	}
+}
+
+func (s *powerOfTwoSelector) Select(_ context.Context, _ *networkservice.Connection, _ *registry.NetworkService, candidates []*registry.NetworkServiceEndpoint) *registry.NetworkServiceEndpoint {
+	switch len(candidates) {
+	case 0:
+		return nil
+	case 1:
+		return candidates[0]
+	}
+
+	// nolint:gosec
+	i := rand.Intn(len(candidates))
+	// nolint:gosec
+	j := rand.Intn(len(candidates) - 1)
+	if j >= i {
+		j++
+	}
+
+	first, second := candidates[i], candidates[j]
+	if first == nil || (second != nil && s.count(second.GetName()) < s.count(first.GetName())) {
+		return second
+	}
+	return first
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundrobin
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+type randomSelector struct{}
+
+// NewRandomSelector - returns a Selector that selects a random candidate
+func NewRandomSelector() Selector {
+	return &randomSelector{}
+}
+
+func (s *randomSelector) Select(_ context.Context, _ *networkservice.Connection, _ *registry.NetworkService, candidates []*registry.NetworkServiceEndpoint) *registry.NetworkServiceEndpoint {
+	if len(candidates) == 0 {
+		return nil
+	}
+	// nolint:gosec
+	return candidates[rand.Intn(len(candidates))]
+}
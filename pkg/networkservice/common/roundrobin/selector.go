@@ -0,0 +1,49 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundrobin
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// Selector chooses an endpoint among the candidates providing the network service
+type Selector interface {
+	// Select returns the endpoint which should be used for the conn or nil if there is nothing to select
+	Select(ctx context.Context, conn *networkservice.Connection, ns *registry.NetworkService, candidates []*registry.NetworkServiceEndpoint) *registry.NetworkServiceEndpoint
+}
+
+// ConnectionTracker is an optional interface for the Selector which needs to know about the active connections
+type ConnectionTracker interface {
+	// Connected is called when the connection with connID has been established with the endpoint nseName
+	Connected(connID, nseName string)
+	// Disconnected is called when the connection with connID has been closed
+	Disconnected(connID string)
+}
+
+// Select selects an endpoint in round robin fashion per network service
+func (rr *roundRobinSelector) Select(_ context.Context, _ *networkservice.Connection, ns *registry.NetworkService, candidates []*registry.NetworkServiceEndpoint) *registry.NetworkServiceEndpoint {
+	return rr.selectEndpoint(ns, candidates)
+}
+
+// NewRoundRobinSelector - returns a Selector that round robins among the candidates per network service. It is used
+// by default.
+func NewRoundRobinSelector() Selector {
+	return newRoundRobinSelector()
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundrobin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+const testNS = "ns"
+
+func testCandidates(names ...string) []*registry.NetworkServiceEndpoint {
+	var result []*registry.NetworkServiceEndpoint
+	for _, name := range names {
+		result = append(result, &registry.NetworkServiceEndpoint{
+			Name:                name,
+			NetworkServiceNames: []string{testNS},
+		})
+	}
+	return result
+}
+
+func Test_leastActiveSelector_Select(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := NewLeastActiveSelector()
+	tracker := s.(ConnectionTracker)
+	ns := &registry.NetworkService{Name: testNS}
+	candidates := testCandidates("nse-1", "nse-2", "nse-3")
+
+	require.Equal(t, "nse-1", s.Select(context.Background(), nil, ns, candidates).GetName())
+
+	tracker.Connected("conn-1", "nse-1")
+	require.Equal(t, "nse-2", s.Select(context.Background(), nil, ns, candidates).GetName())
+
+	tracker.Connected("conn-2", "nse-2")
+	tracker.Connected("conn-2", "nse-2")
+	require.Equal(t, "nse-3", s.Select(context.Background(), nil, ns, candidates).GetName())
+
+	tracker.Connected("conn-3", "nse-3")
+	tracker.Disconnected("conn-2")
+	require.Equal(t, "nse-2", s.Select(context.Background(), nil, ns, candidates).GetName())
+
+	tracker.Connected("conn-1", "nse-3")
+	require.Equal(t, "nse-1", s.Select(context.Background(), nil, ns, candidates).GetName())
+}
+
+func Test_powerOfTwoSelector_Select(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := NewPowerOfTwoSelector()
+	tracker := s.(ConnectionTracker)
+	ns := &registry.NetworkService{Name: testNS}
+	candidates := testCandidates("nse-1", "nse-2")
+
+	require.Nil(t, s.Select(context.Background(), nil, ns, nil))
+	require.Equal(t, "nse-1", s.Select(context.Background(), nil, ns, candidates[:1]).GetName())
+
+	tracker.Connected("conn-1", "nse-1")
+	for i := 0; i < 10; i++ {
+		require.Equal(t, "nse-2", s.Select(context.Background(), nil, ns, candidates).GetName())
+	}
+}
+
+func Test_weightedRandomSelector_Select(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := NewWeightedRandomSelector("")
+	ns := &registry.NetworkService{Name: testNS}
+	candidates := testCandidates("nse-1", "nse-2", "nse-3")
+	candidates[0].NetworkServiceLabels = map[string]*registry.NetworkServiceLabels{
+		testNS: {Labels: map[string]string{DefaultWeightLabel: "0"}},
+	}
+	candidates[1].NetworkServiceLabels = map[string]*registry.NetworkServiceLabels{
+		testNS: {Labels: map[string]string{DefaultWeightLabel: "3"}},
+	}
+	candidates[2].NetworkServiceLabels = map[string]*registry.NetworkServiceLabels{
+		testNS: {Labels: map[string]string{DefaultWeightLabel: "invalid"}},
+	}
+
+	var selected = make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		selected[s.Select(context.Background(), nil, ns, candidates).GetName()]++
+	}
+
+	require.Zero(t, selected["nse-1"])
+	require.Greater(t, selected["nse-2"], selected["nse-3"])
+	require.NotZero(t, selected["nse-3"])
+}
+
+func Test_consistentHashSelector_Select(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	const labelKey = "app"
+
+	s := NewConsistentHashSelector(labelKey)
+	ns := &registry.NetworkService{Name: testNS}
+	candidates := testCandidates("nse-1", "nse-2", "nse-3", "nse-4")
+
+	conn := &networkservice.Connection{
+		Id:     "conn-1",
+		Labels: map[string]string{labelKey: "value"},
+	}
+	selected := s.Select(context.Background(), conn, ns, candidates)
+	require.NotNil(t, selected)
+
+	other := &networkservice.Connection{
+		Id:     "conn-2",
+		Labels: map[string]string{labelKey: "value"},
+	}
+	require.Equal(t, selected.GetName(), s.Select(context.Background(), other, ns, candidates).GetName())
+
+	var reduced []*registry.NetworkServiceEndpoint
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if candidates[i].GetName() == selected.GetName() {
+			continue
+		}
+		reduced = append(reduced, candidates[i])
+	}
+	reselected := s.Select(context.Background(), conn, ns, reduced)
+	require.NotEqual(t, selected.GetName(), reselected.GetName())
+
+	reduced = append(reduced, selected)
+	require.Equal(t, selected.GetName(), s.Select(context.Background(), conn, ns, reduced).GetName())
+}
+
+func Test_randomSelector_Select(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := NewRandomSelector()
+	ns := &registry.NetworkService{Name: testNS}
+	candidates := testCandidates("nse-1", "nse-2")
+
+	require.Nil(t, s.Select(context.Background(), nil, ns, nil))
+
+	var selected = make(map[string]int)
+	for i := 0; i < 100; i++ {
+		selected[s.Select(context.Background(), nil, ns, candidates).GetName()]++
+	}
+	require.Len(t, selected, 2)
+}
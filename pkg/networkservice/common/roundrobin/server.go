@@ -1,6 +1,6 @@
 // Copyright (c) 2021-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2020-2023 Cisco Systems, Inc.
+// Copyright (c) 2020-2024 Cisco Systems, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -28,21 +28,30 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/discover"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/drain"
 )
 
 type selectEndpointServer struct {
-	selector *roundRobinSelector
+	selector    Selector
+	nsSelectors map[string]Selector
 }
 
-// NewServer - provides a NetworkServiceServer chain element that round robins among candidates provided by
-// discover.Candidate(ctx) in the context.
-func NewServer() networkservice.NetworkServiceServer {
-	return &selectEndpointServer{
-		selector: newRoundRobinSelector(),
+// NewServer - provides a NetworkServiceServer chain element that selects among candidates provided by
+// discover.Candidate(ctx) in the context. Round robin is used by default, see WithSelector and
+// WithNetworkServiceSelector for the other strategies.
+func NewServer(opts ...Option) networkservice.NetworkServiceServer {
+	s := &selectEndpointServer{
+		selector:    newRoundRobinSelector(),
+		nsSelectors: make(map[string]Selector),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *selectEndpointServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
@@ -50,11 +59,14 @@ func (s *selectEndpointServer) Request(ctx context.Context, request *networkserv
 		return next.Server(ctx).Request(ctx, request)
 	}
 	candidates := discover.Candidates(ctx)
+	selector := s.selectorFor(candidates.NetworkService.GetName())
+	// Draining endpoints keep serving the existing connections but are not selected for the new ones
+	endpoints := drain.ExcludeDraining(candidates.Endpoints)
 
 	var candidatesErr = errors.New("all candidates have failed")
 
-	for i := 0; i < len(candidates.Endpoints); i++ {
-		endpoint := s.selector.selectEndpoint(candidates.NetworkService, candidates.Endpoints)
+	for i := 0; i < len(candidates.Endpoints) && len(endpoints) > 0; i++ {
+		endpoint := selector.Select(ctx, request.GetConnection(), candidates.NetworkService, endpoints)
 		if endpoint == nil {
 			return nil, errors.Errorf("failed to select endpoint for Network Service: %v %v", candidates.NetworkService, candidates.Endpoints)
 		}
@@ -66,13 +78,36 @@ func (s *selectEndpointServer) Request(ctx context.Context, request *networkserv
 		request.GetConnection().NetworkServiceEndpointName = endpoint.Name
 		resp, err := next.Server(ctx).Request(ctx, request.Clone())
 		if err == nil {
+			if tracker, ok := selector.(ConnectionTracker); ok {
+				tracker.Connected(resp.GetId(), endpoint.Name)
+			}
 			return resp, nil
 		}
+		endpoints = removeEndpoint(endpoints, endpoint)
 		candidatesErr = errors.Wrapf(candidatesErr, "%v. An error during select endpoint %v --> %v", i, endpoint.Name, err.Error())
 	}
 	return nil, candidatesErr
 }
 
 func (s *selectEndpointServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	if tracker, ok := s.selectorFor(conn.GetNetworkService()).(ConnectionTracker); ok {
+		tracker.Disconnected(conn.GetId())
+	}
 	return next.Server(ctx).Close(ctx, conn)
 }
+
+func (s *selectEndpointServer) selectorFor(nsName string) Selector {
+	if selector, ok := s.nsSelectors[nsName]; ok {
+		return selector
+	}
+	return s.selector
+}
+
+func removeEndpoint(endpoints []*registry.NetworkServiceEndpoint, endpoint *registry.NetworkServiceEndpoint) []*registry.NetworkServiceEndpoint {
+	for i := range endpoints {
+		if endpoints[i] == endpoint {
+			return append(endpoints[:i], endpoints[i+1:]...)
+		}
+	}
+	return endpoints
+}
@@ -1,5 +1,7 @@
 // Copyright (c) 2021 Doc.ai and/or its affiliates.
 //
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
 // SPDX-License-Identifier: Apache-2.0
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -19,6 +21,7 @@ package roundrobin_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
@@ -32,6 +35,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/checks/checkrequest"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/drain"
 )
 
 const (
@@ -86,3 +90,84 @@ func TestSelectEndpointServer_CleanRequest(t *testing.T) {
 	require.Equal(t, nse2, conn.NetworkServiceEndpointName)
 	require.Equal(t, map[string]string{nse2: nse2}, conn.Labels)
 }
+
+func TestSelectEndpointServer_NetworkServiceSelector(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceServer(
+		roundrobin.NewServer(
+			roundrobin.WithNetworkServiceSelector(ns, roundrobin.NewLeastActiveSelector()),
+		),
+	)
+
+	ctx := discover.WithCandidates(context.Background(), []*registry.NetworkServiceEndpoint{
+		{
+			Name:                nse1,
+			Url:                 "unix://" + nse1,
+			NetworkServiceNames: []string{ns},
+		},
+		{
+			Name:                nse2,
+			Url:                 "unix://" + nse2,
+			NetworkServiceNames: []string{ns},
+		},
+	}, &registry.NetworkService{Name: ns})
+
+	request := func(id string) *networkservice.Connection {
+		conn, err := s.Request(ctx, &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{
+				Id:             id,
+				NetworkService: ns,
+			},
+		})
+		require.NoError(t, err)
+		return conn
+	}
+
+	conn1 := request("conn-1")
+	require.Equal(t, nse1, conn1.NetworkServiceEndpointName)
+	require.Equal(t, nse2, request("conn-2").NetworkServiceEndpointName)
+	require.Equal(t, nse1, request("conn-3").NetworkServiceEndpointName)
+	require.Equal(t, nse2, request("conn-4").NetworkServiceEndpointName)
+
+	_, err := s.Close(ctx, conn1)
+	require.NoError(t, err)
+
+	require.Equal(t, nse1, request("conn-5").NetworkServiceEndpointName)
+}
+
+func TestSelectEndpointServer_SkipDraining(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceServer(roundrobin.NewServer())
+
+	draining := &registry.NetworkServiceEndpoint{
+		Name:                nse1,
+		Url:                 "unix://" + nse1,
+		NetworkServiceNames: []string{ns},
+	}
+	drain.Set(draining, time.Time{})
+
+	ctx := discover.WithCandidates(context.Background(), []*registry.NetworkServiceEndpoint{
+		draining,
+		{
+			Name:                nse2,
+			Url:                 "unix://" + nse2,
+			NetworkServiceNames: []string{ns},
+		},
+	}, &registry.NetworkService{Name: ns})
+
+	for i := 0; i < 3; i++ {
+		conn, err := s.Request(ctx, &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{NetworkService: ns},
+		})
+		require.NoError(t, err)
+		require.Equal(t, nse2, conn.GetNetworkServiceEndpointName())
+	}
+
+	ctx = discover.WithCandidates(context.Background(), []*registry.NetworkServiceEndpoint{draining}, &registry.NetworkService{Name: ns})
+	_, err := s.Request(ctx, &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{NetworkService: ns},
+	})
+	require.Error(t, err)
+}
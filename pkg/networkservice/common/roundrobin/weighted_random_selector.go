@@ -0,0 +1,89 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundrobin
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// DefaultWeightLabel is the NSE network service label used by the weighted random Selector by default
+const DefaultWeightLabel = "weight"
+
+type weightedRandomSelector struct {
+	weightLabel string
+}
+
+// NewWeightedRandomSelector - returns a Selector that randomly selects the candidate with the probability proportional
+// to its weight. The weight is an unsigned integer value of the weightLabel in the candidate NetworkServiceLabels for
+// the requested network service. Candidates with missing or malformed weight have weight 1, candidates with weight 0
+// are selected only if all the candidates have weight 0.
+func NewWeightedRandomSelector(weightLabel string) Selector {
+	if weightLabel == "" {
+		weightLabel = DefaultWeightLabel
+	}
+	return &weightedRandomSelector{
+		weightLabel: weightLabel,
+	}
+}
+
+func (s *weightedRandomSelector) Select(_ context.Context, _ *networkservice.Connection, ns *registry.NetworkService, candidates []*registry.NetworkServiceEndpoint) *registry.NetworkServiceEndpoint {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var weights = make([]uint64, len(candidates))
+	var total uint64
+	for i, candidate := range candidates {
+		weights[i] = s.weight(ns, candidate)
+		total += weights[i]
+	}
+
+	if total == 0 {
+		// nolint:gosec
+		return candidates[rand.Intn(len(candidates))]
+	}
+
+	// nolint:gosec
+	var r = rand.Uint64() % total
+	for i, weight := range weights {
+		if r < weight {
+			return candidates[i]
+		}
+		r -= weight
+	}
+	return candidates[len(candidates)-1]
+}
+
+func (s *weightedRandomSelector) weight(ns *registry.NetworkService, nse *registry.NetworkServiceEndpoint) uint64 {
+	if nse == nil {
+		return 0
+	}
+	value, ok := nse.GetNetworkServiceLabels()[ns.GetName()].GetLabels()[s.weightLabel]
+	if !ok {
+		return 1
+	}
+	weight, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 1
+	}
+	return weight
+}
@@ -97,6 +97,9 @@ func (n *vl3Client) Request(ctx context.Context, request *networkservice.Network
 
 	var address, prefix = n.pool.selfAddress().String(), n.pool.selfPrefix().String()
 
+	if n.pool.hasTransitionPeriod() {
+		n.removePreviousPrefixes(ctx, conn.GetContext().GetIpContext(), prefix)
+	}
 	addAddr(&conn.GetContext().GetIpContext().SrcIpAddrs, address)
 	addRoute(&conn.GetContext().GetIpContext().DstRoutes, address, n.pool.selfAddress().IP.String())
 	addRoute(&conn.GetContext().GetIpContext().DstRoutes, prefix, n.pool.selfAddress().IP.String())
@@ -104,6 +107,24 @@ func (n *vl3Client) Request(ctx context.Context, request *networkservice.Network
 	return next.Client(ctx).Request(ctx, request, opts...)
 }
 
+// removePreviousPrefixes removes the addresses and routes of the previous client prefixes from the IP context when
+// their transition has ended
+func (n *vl3Client) removePreviousPrefixes(ctx context.Context, ipContext *networkservice.IPContext, prefix string) {
+	prevPrefixes, _ := loadPrefixes(ctx)
+
+	var prefixes []string
+	for _, prevPrefix := range prevPrefixes {
+		switch {
+		case prevPrefix == prefix:
+		case n.pool.isInTransition(prevPrefix):
+			prefixes = append(prefixes, prevPrefix)
+		default:
+			removePreviousPrefixFromIPContext(ipContext, prevPrefix)
+		}
+	}
+	storePrefixes(ctx, append(prefixes, prefix))
+}
+
 func (n *vl3Client) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
 	if oldCancel, loaded := loadAndDeleteCancel(ctx); loaded {
 		oldCancel()
@@ -34,6 +34,8 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
 )
 
 func Test_Client_ConnectsToVl3NSE(t *testing.T) {
@@ -316,3 +318,60 @@ func Test_VL3NSE_ConnectsToVl3NSE_Close(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func Test_VL3NSE_ConnectsToVl3NSE_PrefixTransition(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+
+	clientIpam := vl3.NewIPAM("10.0.1.0/24")
+	clientIpam.SetTransitionPeriod(clock.WithClock(ctx, clockMock), time.Minute)
+	serverIpam := vl3.NewIPAM("10.0.0.1/24")
+
+	var server = next.NewNetworkServiceServer(
+		adapters.NewClientToServer(
+			next.NewNetworkServiceClient(
+				begin.NewClient(),
+				metadata.NewClient(),
+				vl3.NewClient(ctx, clientIpam),
+			),
+		),
+		metadata.NewServer(),
+		vl3.NewServer(ctx, serverIpam),
+	)
+
+	resp, err := server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: &networkservice.Connection{Id: t.Name()}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.1.0/32", "10.0.0.1/32"}, resp.GetContext().GetIpContext().GetSrcIpAddrs())
+
+	err = clientIpam.Reset("10.0.5.0/24")
+	require.NoError(t, err)
+
+	// Old and new addresses coexist during the transition
+	resp, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: resp})
+	require.NoError(t, err)
+
+	ipContext := resp.GetContext().GetIpContext()
+	require.Equal(t, []string{"10.0.1.0/32", "10.0.0.1/32", "10.0.5.0/32"}, ipContext.GetSrcIpAddrs())
+	require.Equal(t, []string{"10.0.1.0/32", "10.0.1.0/24", "10.0.0.1/32", "10.0.5.0/32", "10.0.5.0/24"}, routePrefixes(ipContext.GetDstRoutes()))
+
+	// Old addresses are removed after the transition
+	clockMock.Add(time.Minute)
+	require.Eventually(t, func() bool {
+		resp, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: resp})
+		require.NoError(t, err)
+		return len(resp.GetContext().GetIpContext().GetSrcIpAddrs()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	ipContext = resp.GetContext().GetIpContext()
+	require.Equal(t, []string{"10.0.0.1/32", "10.0.5.0/32"}, ipContext.GetSrcIpAddrs())
+	require.Equal(t, []string{"10.0.0.1/32", "10.0.5.0/32", "10.0.5.0/24"}, routePrefixes(ipContext.GetDstRoutes()))
+
+	_, err = server.Close(ctx, resp)
+	require.NoError(t, err)
+}
@@ -21,7 +21,9 @@ import (
 	"context"
 	"net"
 	"sync"
+	"time"
 
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
 	"github.com/networkservicemesh/sdk/pkg/tools/ippool"
 )
 
@@ -33,6 +35,9 @@ type IPAM struct {
 	excludedPrefixes map[string]struct{}
 	clientMask       uint8
 	subscriptions    list.List
+	transitionCtx    context.Context
+	transitionPeriod time.Duration
+	transitions      map[string]clock.Timer
 }
 
 // NewIPAM creates a new vl3 ipam with specified prefix and excluded prefixes
@@ -68,6 +73,81 @@ func (p *IPAM) notify() {
 	}
 }
 
+// SetTransitionPeriod sets the period while the addresses from the previous prefix are kept on the connections
+// together with the addresses from the new one after Reset. By default the previous addresses are removed at once.
+// The transitions use the clock from ctx, the pending transitions are stopped when ctx is done.
+func (p *IPAM) SetTransitionPeriod(ctx context.Context, transitionPeriod time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.transitionCtx = ctx
+	p.transitionPeriod = transitionPeriod
+
+	go func() {
+		<-ctx.Done()
+		p.stopTransitions(ctx)
+	}()
+}
+
+func (p *IPAM) hasTransitionPeriod() bool {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.hasTransitionPeriodLocked()
+}
+
+// hasTransitionPeriodLocked should be called under the lock
+func (p *IPAM) hasTransitionPeriodLocked() bool {
+	return p.transitionPeriod > 0 && p.transitionCtx.Err() == nil
+}
+
+func (p *IPAM) isInTransition(prefix string) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	_, ok := p.transitions[prefix]
+	return ok
+}
+
+// startTransition keeps the previous prefix in transition for the transition period. Subscribers are notified when
+// the transition ends, so they can remove the previous addresses.
+// Should be called under the lock
+func (p *IPAM) startTransition(prefix string) {
+	if p.transitions == nil {
+		p.transitions = make(map[string]clock.Timer)
+	}
+	if timer, ok := p.transitions[prefix]; ok {
+		timer.Stop()
+	}
+	var timer clock.Timer
+	timer = clock.FromContext(p.transitionCtx).AfterFunc(p.transitionPeriod, func() {
+		p.Lock()
+		defer p.Unlock()
+
+		if p.transitions[prefix] != timer || p.transitionCtx.Err() != nil {
+			return
+		}
+		delete(p.transitions, prefix)
+		p.notify()
+	})
+	p.transitions[prefix] = timer
+}
+
+// stopTransitions stops the pending transitions started with ctx, the previous addresses are removed on the next
+// refresh
+func (p *IPAM) stopTransitions(ctx context.Context) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.transitionCtx != ctx {
+		return
+	}
+	for prefix, timer := range p.transitions {
+		timer.Stop()
+		delete(p.transitions, prefix)
+	}
+}
+
 func (p *IPAM) isInitialized() bool {
 	p.Lock()
 	defer p.Unlock()
@@ -155,6 +235,13 @@ func (p *IPAM) Reset(prefix string, excludePrefixes ...string) error {
 	if err != nil {
 		return err
 	}
+	if timer, ok := p.transitions[ipNet.String()]; ok {
+		timer.Stop()
+		delete(p.transitions, ipNet.String())
+	}
+	if prevPrefix := p.self.String(); p.ipPool != nil && p.hasTransitionPeriodLocked() && prevPrefix != ipNet.String() {
+		p.startTransition(prevPrefix)
+	}
 	p.self = *ipNet
 	p.ipPool = ippool.NewWithNet(ipNet)
 	p.excludedPrefixes = make(map[string]struct{})
@@ -17,11 +17,17 @@
 package vl3_test
 
 import (
+	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/connectioncontext/ipcontext/vl3"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
 )
 
 func TestSubscribtions(t *testing.T) {
@@ -48,3 +54,69 @@ func TestSubscribtions(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, counter, 4)
 }
+
+func TestTransitionPeriod(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+
+	var counter atomic.Int32
+	ipam := vl3.NewIPAM("10.0.0.1/24")
+	ipam.SetTransitionPeriod(clock.WithClock(ctx, clockMock), time.Minute)
+	unsub := ipam.Subscribe(func() {
+		counter.Add(1)
+	})
+	defer unsub()
+
+	err := ipam.Reset("10.0.5.1/24")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), counter.Load())
+
+	clockMock.Add(time.Minute - time.Millisecond)
+	require.Never(t, func() bool {
+		return counter.Load() != 1
+	}, 100*time.Millisecond, 10*time.Millisecond)
+
+	// Subscribers are notified once again when the transition ends
+	clockMock.Add(time.Millisecond)
+	require.Eventually(t, func() bool {
+		return counter.Load() == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTransitionPeriod_Stop(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	transitionCtx, cancelTransitions := context.WithCancel(clock.WithClock(ctx, clockMock))
+
+	var counter atomic.Int32
+	ipam := vl3.NewIPAM("10.0.0.1/24")
+	ipam.SetTransitionPeriod(transitionCtx, time.Minute)
+	unsub := ipam.Subscribe(func() {
+		counter.Add(1)
+	})
+	defer unsub()
+
+	err := ipam.Reset("10.0.5.1/24")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), counter.Load())
+
+	// The pending transition is stopped with its context, the new prefixes are changed without the transition
+	cancelTransitions()
+
+	err = ipam.Reset("10.0.6.1/24")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), counter.Load())
+
+	clockMock.Add(time.Minute)
+	require.Never(t, func() bool {
+		return counter.Load() != 2
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
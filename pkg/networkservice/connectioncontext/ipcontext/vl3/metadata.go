@@ -36,3 +36,18 @@ func loadAndDeleteCancel(ctx context.Context) (value context.CancelFunc, ok bool
 	value, ok = rawValue.(context.CancelFunc)
 	return value, ok
 }
+
+type prefixesKey struct{}
+
+func storePrefixes(ctx context.Context, prefixes []string) {
+	metadata.Map(ctx, true).Store(prefixesKey{}, prefixes)
+}
+
+func loadPrefixes(ctx context.Context) (value []string, ok bool) {
+	rawValue, ok := metadata.Map(ctx, true).Load(prefixesKey{})
+	if !ok {
+		return
+	}
+	value, ok = rawValue.([]string)
+	return value, ok
+}
@@ -33,8 +33,15 @@ import (
 )
 
 type vl3Server struct {
-	pool      *IPAM
-	subnetMap genericsync.Map[string, string] // Map connectionId:subnet
+	pool        *IPAM
+	subnetMap   genericsync.Map[string, subnet]   // Map connectionId:subnet
+	prevSubnets genericsync.Map[string, []subnet] // Map connectionId:subnets in transition
+}
+
+// subnet is the server prefix the connection address is allocated from and the vL3 network prefix of the server
+type subnet struct {
+	prefix       string
+	globalPrefix string
 }
 
 // NewServer - returns a new vL3 server instance that manages connection.context.ipcontext for vL3 scenario.
@@ -62,17 +69,30 @@ func (v *vl3Server) Request(ctx context.Context, request *networkservice.Network
 
 	var ipContext = conn.GetContext().GetIpContext()
 
-	if prevAddress, ok := v.subnetMap.Load(conn.GetId()); ok {
-		// Remove previous prefix from IP Context if a current server prefix has changed
-		if v.pool.globalIPNet().String() != prevAddress {
+	var current = subnet{
+		prefix:       v.pool.selfPrefix().String(),
+		globalPrefix: v.pool.globalIPNet().String(),
+	}
+
+	if prev, ok := v.subnetMap.Load(conn.GetId()); ok {
+		// Remove previous prefix from IP Context if a current server prefix has changed. Without the transition period
+		// the address is re-allocated only if the vL3 network prefix has changed.
+		transition := v.pool.hasTransitionPeriod()
+		if transition && current.prefix != prev.prefix || !transition && current.globalPrefix != prev.globalPrefix {
 			srcNet, err := v.pool.allocate()
 			log.FromContext(ctx).Infof("Server Request. Allocated net: %+v for connection: %+v", srcNet.String(), conn.GetId())
 			if err != nil {
 				return nil, err
 			}
-			removePreviousPrefixFromIPContext(ipContext, prevAddress)
+			if transition {
+				// Keep the previous addresses until the transition ends, so the existing sessions can drain
+				prevSubnets, _ := v.prevSubnets.Load(conn.GetId())
+				v.prevSubnets.Store(conn.GetId(), append(prevSubnets, prev))
+			} else {
+				removePreviousPrefixFromIPContext(ipContext, prev.globalPrefix)
+			}
 			ipContext.SrcIpAddrs = append(ipContext.SrcIpAddrs, srcNet.String())
-			v.subnetMap.Store(conn.GetId(), v.pool.globalIPNet().String())
+			v.subnetMap.Store(conn.GetId(), current)
 		}
 	} else if len(request.GetConnection().GetContext().GetDnsContext().GetConfigs()) == 0 || countTheSameVersionSrcIps(request, len(v.pool.self.IP)) == 0 { // TODO Consider a better option to determine vL3NSE-to-vL3NSE server case
 		srcNet, err := v.pool.allocate()
@@ -81,8 +101,9 @@ func (v *vl3Server) Request(ctx context.Context, request *networkservice.Network
 			return nil, err
 		}
 		ipContext.SrcIpAddrs = append(ipContext.SrcIpAddrs, srcNet.String())
-		v.subnetMap.Store(conn.GetId(), v.pool.globalIPNet().String())
+		v.subnetMap.Store(conn.GetId(), current)
 	}
+	v.removePreviousPrefixes(conn.GetId(), ipContext, current)
 
 	addRoute(&ipContext.SrcRoutes, v.pool.selfAddress().String(), v.pool.selfAddress().IP.String())
 	addRoute(&ipContext.SrcRoutes, v.pool.selfPrefix().String(), v.pool.selfAddress().IP.String())
@@ -98,11 +119,40 @@ func (v *vl3Server) Request(ctx context.Context, request *networkservice.Network
 	return resp, err
 }
 
+// removePreviousPrefixes removes the addresses and routes of the previous server prefixes from the IP context when
+// their transition has ended
+func (v *vl3Server) removePreviousPrefixes(connID string, ipContext *networkservice.IPContext, current subnet) {
+	prevSubnets, ok := v.prevSubnets.Load(connID)
+	if !ok {
+		return
+	}
+
+	var subnets []subnet
+	for _, prev := range prevSubnets {
+		switch {
+		case prev.prefix == current.prefix:
+		case v.pool.isInTransition(prev.prefix):
+			subnets = append(subnets, prev)
+		default:
+			removePreviousPrefixFromIPContext(ipContext, prev.prefix)
+			if prev.globalPrefix != current.globalPrefix {
+				removeRoute(&ipContext.SrcRoutes, prev.globalPrefix)
+			}
+		}
+	}
+	if len(subnets) == 0 {
+		v.prevSubnets.Delete(connID)
+		return
+	}
+	v.prevSubnets.Store(connID, subnets)
+}
+
 func (v *vl3Server) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
 	for _, srcAddr := range conn.GetContext().GetIpContext().GetSrcIpAddrs() {
 		v.pool.freeIfAllocated(srcAddr)
 	}
 	v.subnetMap.Delete(conn.GetId())
+	v.prevSubnets.Delete(conn.GetId())
 	return next.Server(ctx).Close(ctx, conn)
 }
 
@@ -118,6 +168,16 @@ func addRoute(routes *[]*networkservice.Route, prefix, nextHop string) {
 	})
 }
 
+func removeRoute(routes *[]*networkservice.Route, prefix string) {
+	var result []*networkservice.Route
+	for _, route := range *routes {
+		if route.Prefix != prefix {
+			result = append(result, route)
+		}
+	}
+	*routes = result
+}
+
 func addAddr(addrs *[]string, addr string) {
 	for _, a := range *addrs {
 		if a == addr {
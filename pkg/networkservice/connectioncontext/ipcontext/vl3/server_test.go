@@ -19,6 +19,7 @@ package vl3_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
@@ -30,6 +31,8 @@ import (
 	"go.uber.org/goleak"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
 )
 
 func Test_NSC_ConnectsToVl3NSE(t *testing.T) {
@@ -222,3 +225,90 @@ func Test_NSC_ConnectsToVl3NSE_DualStack(t *testing.T) {
 	require.Equal(t, "10.0.0.1/32", ipContext.GetDstRoutes()[0].GetPrefix())
 	require.Equal(t, "2001:db8::1/128", ipContext.GetDstRoutes()[1].GetPrefix())
 }
+
+func Test_NSC_ConnectsToVl3NSE_SameNetworkPrefix(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ipam := vl3.NewIPAM("12.0.0.1/24")
+
+	var server = next.NewNetworkServiceServer(
+		metadata.NewServer(),
+		vl3.NewServer(context.Background(), ipam),
+	)
+
+	_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: &networkservice.Connection{Id: "1"}})
+	require.NoError(t, err)
+
+	resp, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: &networkservice.Connection{Id: "2"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"12.0.0.2/32"}, resp.GetContext().GetIpContext().GetSrcIpAddrs())
+
+	err = ipam.Reset("12.0.0.1/25")
+	require.NoError(t, err)
+
+	// Without the transition period the address is kept while the vL3 network prefix is the same
+	resp, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: resp})
+	require.NoError(t, err)
+	require.Equal(t, []string{"12.0.0.2/32"}, resp.GetContext().GetIpContext().GetSrcIpAddrs())
+}
+
+func Test_NSC_ConnectsToVl3NSE_PrefixTransition(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+
+	ipam := vl3.NewIPAM("12.0.0.1/24")
+	ipam.SetTransitionPeriod(clock.WithClock(ctx, clockMock), time.Minute)
+
+	var server = next.NewNetworkServiceServer(
+		metadata.NewServer(),
+		vl3.NewServer(ctx, ipam),
+	)
+
+	resp, err := server.Request(ctx, new(networkservice.NetworkServiceRequest))
+	require.NoError(t, err)
+	require.Equal(t, []string{"12.0.0.1/32"}, resp.GetContext().GetIpContext().GetSrcIpAddrs())
+
+	err = ipam.Reset("12.0.5.1/24")
+	require.NoError(t, err)
+
+	// Old and new addresses coexist during the transition
+	resp, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: resp})
+	require.NoError(t, err)
+
+	ipContext := resp.GetContext().GetIpContext()
+	require.Equal(t, []string{"12.0.0.1/32", "12.0.5.1/32"}, ipContext.GetSrcIpAddrs())
+	require.Equal(t, []string{"12.0.0.0/32", "12.0.5.0/32"}, ipContext.GetDstIpAddrs())
+	require.Equal(t, []string{"12.0.0.1/32", "12.0.5.1/32"}, routePrefixes(ipContext.GetDstRoutes()))
+	require.Equal(t, []string{"12.0.0.0/32", "12.0.0.0/24", "12.0.0.0/16", "12.0.5.0/32", "12.0.5.0/24", "12.0.5.0/16"},
+		routePrefixes(ipContext.GetSrcRoutes()))
+
+	// Old addresses are removed on the first refresh after the transition
+	clockMock.Add(time.Minute)
+	require.Eventually(t, func() bool {
+		resp, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: resp})
+		require.NoError(t, err)
+		return len(resp.GetContext().GetIpContext().GetSrcIpAddrs()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	ipContext = resp.GetContext().GetIpContext()
+	require.Equal(t, []string{"12.0.5.1/32"}, ipContext.GetSrcIpAddrs())
+	require.Equal(t, []string{"12.0.5.0/32"}, ipContext.GetDstIpAddrs())
+	require.Equal(t, []string{"12.0.5.1/32"}, routePrefixes(ipContext.GetDstRoutes()))
+	require.Equal(t, []string{"12.0.5.0/32", "12.0.5.0/24", "12.0.5.0/16"}, routePrefixes(ipContext.GetSrcRoutes()))
+}
+
+func routePrefixes(routes []*networkservice.Route) []string {
+	var prefixes []string
+	for _, route := range routes {
+		prefixes = append(prefixes, route.GetPrefix())
+	}
+	return prefixes
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configipam
+
+import (
+	"net"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// Config is the declarative IPAM config
+type Config struct {
+	// Prefixes are the prefixes for the network services without the sub-pool
+	Prefixes []string `json:"prefixes,omitempty"`
+	// Reserved are the prefixes never handed out to the clients, e.g. the gateway address
+	Reserved []string `json:"reserved,omitempty"`
+	// Reservations are the addresses statically reserved for the clients
+	Reservations []*Reservation `json:"reservations,omitempty"`
+	// NetworkServices are the per network service sub-pools
+	NetworkServices map[string]*SubPool `json:"networkServices,omitempty"`
+}
+
+// Reservation is the addresses statically reserved for the clients having the connection labels
+type Reservation struct {
+	// Labels are the connection labels the client should have
+	Labels map[string]string `json:"labels"`
+	// SrcAddr is the reserved client address
+	SrcAddr string `json:"srcAddr"`
+	// DstAddr is the reserved endpoint address, it is required by the point2pointipam and is ignored by the singlepointipam
+	DstAddr string `json:"dstAddr,omitempty"`
+}
+
+// SubPool is the pool of the addresses for the network service
+type SubPool struct {
+	Prefixes []string `json:"prefixes"`
+}
+
+// ParseConfig parses and validates the YAML config
+func ParseConfig(data []byte) (*Config, error) {
+	config := new(Config)
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal IPAM config")
+	}
+
+	var prefixes []*net.IPNet
+	parse := func(values []string) error {
+		for _, value := range values {
+			_, ipNet, err := net.ParseCIDR(value)
+			if err != nil {
+				return errors.Wrapf(err, "invalid prefix %s", value)
+			}
+			prefixes = append(prefixes, ipNet)
+		}
+		return nil
+	}
+	if err := parse(config.Prefixes); err != nil {
+		return nil, err
+	}
+	for name, subPool := range config.NetworkServices {
+		if subPool == nil || len(subPool.Prefixes) == 0 {
+			return nil, errors.Errorf("no prefixes for the network service %s", name)
+		}
+		if err := parse(subPool.Prefixes); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, value := range config.Reserved {
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return nil, errors.Wrapf(err, "invalid reserved prefix %s", value)
+		}
+	}
+
+	for _, r := range config.Reservations {
+		if len(r.Labels) == 0 {
+			return nil, errors.Errorf("no labels for the reservation %s", r.SrcAddr)
+		}
+		addrs := []string{r.SrcAddr}
+		if r.DstAddr != "" {
+			addrs = append(addrs, r.DstAddr)
+		}
+		for _, addr := range addrs {
+			ip, _, err := net.ParseCIDR(addr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid reserved address %s", addr)
+			}
+			if !containsIP(prefixes, ip) {
+				return nil, errors.Errorf("reserved address %s is out of the prefixes", addr)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+func containsIP(prefixes []*net.IPNet, ip net.IP) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configipam
+
+import (
+	"net"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+// ConfigPathDefault is the default IPAM config file path
+const ConfigPathDefault = "/var/lib/networkservicemesh/config/ipam.yaml"
+
+// Option is an option pattern for NewServer
+type Option func(s *configIPAMServer)
+
+// WithConfigPath sets the IPAM config file path. Default: ConfigPathDefault
+func WithConfigPath(configPath string) Option {
+	return func(s *configIPAMServer) {
+		s.configPath = configPath
+	}
+}
+
+// WithCustomIPAMServer replaces default `point2pointipam` to custom implementation, e.g. `singlepointipam` or
+// `groupipam` grouping the prefixes by the IP family. The static reservations are handed out only by the servers
+// recovering the addresses from the IpContext and implementing ipaminfo.Introspector, like `point2pointipam` and
+// `singlepointipam`, they are ignored for the other servers.
+func WithCustomIPAMServer(f func(...*net.IPNet) networkservice.NetworkServiceServer) Option {
+	if f == nil {
+		panic("nil is not allowed")
+	}
+
+	return func(s *configIPAMServer) {
+		s.newIPAMServerFn = f
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configipam provides a chain element that allocates the addresses according to the declarative IPAM config.
+// The config file is watched, so the changes are applied to the new connections and to the refreshes of the existing
+// ones without the restart.
+package configipam
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/edwarnicke/genericsync"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/point2pointipam"
+	"github.com/networkservicemesh/sdk/pkg/tools/fs"
+	"github.com/networkservicemesh/sdk/pkg/tools/ipaminfo"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type configIPAMServer struct {
+	ctx             context.Context
+	configPath      string
+	newIPAMServerFn func(...*net.IPNet) networkservice.NetworkServiceServer
+	state           atomic.Pointer[configState]
+	servers         map[string]networkservice.NetworkServiceServer
+	connections     genericsync.Map[string, *connectionInfo]
+	holders         genericsync.Map[string, string]
+	once            sync.Once
+}
+
+type configState struct {
+	defaultServer networkservice.NetworkServiceServer
+	servers       map[string]networkservice.NetworkServiceServer
+	reserved      []string
+	reservations  []*Reservation
+}
+
+type connectionInfo struct {
+	server  networkservice.NetworkServiceServer
+	srcAddr string
+}
+
+// NewServer - creates a new NetworkServiceServer chain element allocating the addresses according to the IPAM config:
+// the connection gets the addresses from the sub-pool of its network service or from the default prefixes, the
+// reserved prefixes are never handed out, the statically reserved addresses are handed out only to the clients with
+// the matching connection labels.
+func NewServer(ctx context.Context, options ...Option) networkservice.NetworkServiceServer {
+	s := &configIPAMServer{
+		ctx:             ctx,
+		configPath:      ConfigPathDefault,
+		newIPAMServerFn: point2pointipam.NewServer,
+		servers:         make(map[string]networkservice.NetworkServiceServer),
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	s.state.Store(new(configState))
+	return s
+}
+
+func (s *configIPAMServer) init() {
+	logger := log.FromContext(s.ctx).WithField("configIPAMServer", s.configPath)
+
+	updateConfig := func(data []byte) {
+		if data == nil {
+			s.update(new(Config))
+			return
+		}
+		config, err := ParseConfig(data)
+		if err != nil {
+			logger.Errorf("failed to update IPAM config: %s", err.Error())
+			return
+		}
+		s.update(config)
+	}
+
+	updateCh := fs.WatchFile(s.ctx, s.configPath)
+	updateConfig(<-updateCh)
+	go func() {
+		for update := range updateCh {
+			updateConfig(update)
+		}
+	}()
+}
+
+// update keeps the IPAM servers for the unchanged pools, so the existing connections keep their addresses
+func (s *configIPAMServer) update(config *Config) {
+	servers := make(map[string]networkservice.NetworkServiceServer)
+	serverFor := func(prefixes []string) networkservice.NetworkServiceServer {
+		if len(prefixes) == 0 {
+			return nil
+		}
+		key := poolKey(prefixes)
+		if server, ok := servers[key]; ok {
+			return server
+		}
+		server, ok := s.servers[key]
+		if !ok {
+			var ipNets []*net.IPNet
+			for _, prefix := range prefixes {
+				_, ipNet, _ := net.ParseCIDR(prefix)
+				ipNets = append(ipNets, ipNet)
+			}
+			server = s.newIPAMServerFn(ipNets...)
+		}
+		servers[key] = server
+		return server
+	}
+
+	state := &configState{
+		defaultServer: serverFor(config.Prefixes),
+		servers:       make(map[string]networkservice.NetworkServiceServer),
+		reserved:      config.Reserved,
+		reservations:  config.Reservations,
+	}
+	for name, subPool := range config.NetworkServices {
+		state.servers[name] = serverFor(subPool.Prefixes)
+	}
+
+	s.servers = servers
+	s.state.Store(state)
+
+	// The removed reservations are not held anymore, the connections keep the addresses until they are closed
+	reserved := make(map[string]bool, len(config.Reservations))
+	for _, r := range config.Reservations {
+		reserved[r.SrcAddr] = true
+	}
+	s.holders.Range(func(srcAddr, _ string) bool {
+		if !reserved[srcAddr] {
+			s.holders.Delete(srcAddr)
+		}
+		return true
+	})
+}
+
+func (s *configIPAMServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	s.once.Do(s.init)
+
+	conn := request.GetConnection()
+	if conn.GetContext() == nil {
+		conn.Context = &networkservice.ConnectionContext{}
+	}
+	if conn.GetContext().GetIpContext() == nil {
+		conn.GetContext().IpContext = &networkservice.IPContext{}
+	}
+	ipContext := conn.GetContext().GetIpContext()
+	state := s.state.Load()
+
+	connInfo, loaded := s.connections.Load(conn.GetId())
+	if !loaded {
+		connInfo = &connectionInfo{
+			server: state.serverFor(conn.GetNetworkService()),
+		}
+		if connInfo.server == nil {
+			return nil, errors.Errorf("no IPAM prefixes are configured for the network service %s", conn.GetNetworkService())
+		}
+	}
+
+	// The new connection of the client having the reservation holds it, so the reserved addresses are not excluded for
+	// the connection. The reserved addresses are put first to the IpContext, so the IPAM server recovers them.
+	var reservation *Reservation
+	var prefilled []string
+	r := state.reservationFor(conn.GetLabels())
+	if !loaded && r != nil && isIntrospector(connInfo.server) && !isExcluded(ipContext.GetExcludedPrefixes(), r) {
+		if holder, held := s.holders.LoadOrStore(r.SrcAddr, conn.GetId()); !held || holder == conn.GetId() {
+			reservation = r
+			connInfo.srcAddr = r.SrcAddr
+			prefilled = prefill(ipContext, r)
+		}
+	}
+
+	excludedPrefixes := ipContext.GetExcludedPrefixes()
+	added := state.excludedPrefixes(connInfo.srcAddr, excludedPrefixes)
+	ipContext.ExcludedPrefixes = append(append([]string(nil), excludedPrefixes...), added...)
+
+	resp, err := connInfo.server.Request(ctx, request)
+	ipContext.ExcludedPrefixes = excludedPrefixes
+	if err != nil {
+		if reservation != nil {
+			s.holders.Delete(reservation.SrcAddr)
+		}
+		ipContext.SrcIpAddrs = deletePrefixes(ipContext.GetSrcIpAddrs(), prefilled)
+		ipContext.DstIpAddrs = deletePrefixes(ipContext.GetDstIpAddrs(), prefilled)
+		return nil, err
+	}
+
+	respIPContext := resp.GetContext().GetIpContext()
+	respIPContext.ExcludedPrefixes = deletePrefixes(respIPContext.GetExcludedPrefixes(), added)
+	if reservation != nil {
+		// The IPAM server reports the addresses it has allocated to the connection, the prefilled addresses it has not
+		// allocated are not the connection addresses
+		allocated := allocatedAddrs(connInfo.server, conn.GetId())
+		if !containsAddr(allocated, reservation.SrcAddr) {
+			// The reserved addresses have not been allocated, e.g. they are still used by the closing connection
+			s.holders.Delete(reservation.SrcAddr)
+			connInfo.srcAddr = ""
+		}
+		notAllocated := deletePrefixes(append([]string(nil), prefilled...), allocated)
+		respIPContext.SrcIpAddrs = deletePrefixes(respIPContext.GetSrcIpAddrs(), notAllocated)
+		respIPContext.DstIpAddrs = deletePrefixes(respIPContext.GetDstIpAddrs(), notAllocated)
+	}
+	if !loaded {
+		s.connections.Store(conn.GetId(), connInfo)
+	}
+
+	return resp, nil
+}
+
+func (s *configIPAMServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	s.once.Do(s.init)
+
+	connInfo, ok := s.connections.LoadAndDelete(conn.GetId())
+	if !ok {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	if connInfo.srcAddr != "" {
+		if holder, held := s.holders.Load(connInfo.srcAddr); held && holder == conn.GetId() {
+			s.holders.Delete(connInfo.srcAddr)
+		}
+	}
+	return connInfo.server.Close(ctx, conn)
+}
+
+func (s *configState) serverFor(networkService string) networkservice.NetworkServiceServer {
+	if server, ok := s.servers[networkService]; ok {
+		return server
+	}
+	return s.defaultServer
+}
+
+func (s *configState) reservationFor(labels map[string]string) *Reservation {
+	for _, r := range s.reservations {
+		if isSubset(labels, r.Labels) {
+			return r
+		}
+	}
+	return nil
+}
+
+// excludedPrefixes returns the reserved prefixes and the addresses reserved for the other clients
+func (s *configState) excludedPrefixes(srcAddr string, excludedPrefixes []string) []string {
+	var prefixes []string
+	for _, prefix := range s.reserved {
+		prefixes = addPrefix(prefixes, excludedPrefixes, prefix)
+	}
+	for _, r := range s.reservations {
+		if r.SrcAddr == srcAddr {
+			continue
+		}
+		prefixes = addPrefix(prefixes, excludedPrefixes, r.SrcAddr)
+		if r.DstAddr != "" {
+			prefixes = addPrefix(prefixes, excludedPrefixes, r.DstAddr)
+		}
+	}
+	return prefixes
+}
+
+func isIntrospector(server networkservice.NetworkServiceServer) bool {
+	_, ok := server.(ipaminfo.Introspector)
+	return ok
+}
+
+// allocatedAddrs returns the addresses allocated to the connection by the IPAM server
+func allocatedAddrs(server networkservice.NetworkServiceServer, connID string) []string {
+	for _, allocation := range server.(ipaminfo.Introspector).Allocations() {
+		if allocation.ConnectionID == connID {
+			return allocation.Addresses
+		}
+	}
+	return nil
+}
+
+// isExcluded returns true if some of the reserved addresses are excluded by the client
+func isExcluded(excludedPrefixes []string, r *Reservation) bool {
+	for _, prefix := range excludedPrefixes {
+		_, ipNet, err := net.ParseCIDR(prefix)
+		if err != nil {
+			continue
+		}
+		for _, addr := range []string{r.SrcAddr, r.DstAddr} {
+			if ip, _, err := net.ParseCIDR(addr); err == nil && ipNet.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// prefill puts the reserved addresses first to the IpContext and returns the added ones
+func prefill(ipContext *networkservice.IPContext, r *Reservation) []string {
+	var prefilled []string
+	prepend := func(addrs []string, addr string) []string {
+		for _, a := range addrs {
+			if a == addr {
+				return addrs
+			}
+		}
+		prefilled = append(prefilled, addr)
+		return append([]string{addr}, addrs...)
+	}
+	ipContext.SrcIpAddrs = prepend(ipContext.GetSrcIpAddrs(), r.SrcAddr)
+	if r.DstAddr != "" {
+		ipContext.DstIpAddrs = prepend(ipContext.GetDstIpAddrs(), r.DstAddr)
+	}
+	return prefilled
+}
+
+// containsAddr returns true if one of the addresses has the same IP as the addr, the IPAM server can allocate the
+// address with the prefix mask
+func containsAddr(addrs []string, addr string) bool {
+	ip, _, err := net.ParseCIDR(addr)
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if aIP, _, err := net.ParseCIDR(a); err == nil && aIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSubset(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+func poolKey(prefixes []string) string {
+	sorted := append([]string(nil), prefixes...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func addPrefix(prefixes, existing []string, prefix string) []string {
+	for _, p := range existing {
+		if p == prefix {
+			return prefixes
+		}
+	}
+	for _, p := range prefixes {
+		if p == prefix {
+			return prefixes
+		}
+	}
+	return append(prefixes, prefix)
+}
+
+func deletePrefixes(prefixes, deleted []string) []string {
+	for _, prefix := range deleted {
+		deleteAddr(&prefixes, prefix)
+	}
+	return prefixes
+}
+
+func deleteAddr(addrs *[]string, addr string) {
+	for i, a := range *addrs {
+		if a == addr {
+			*addrs = append((*addrs)[:i], (*addrs)[i+1:]...)
+			return
+		}
+	}
+}
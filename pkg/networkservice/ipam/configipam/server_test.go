@@ -0,0 +1,337 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configipam_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/updatepath"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/configipam"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/singlepointipam"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+)
+
+const configName = "ipam.yaml"
+
+func newConfigIPAMServer(ctx context.Context, t *testing.T, config string, options ...configipam.Option) (networkservice.NetworkServiceServer, string) {
+	configPath := filepath.Join(t.TempDir(), configName)
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0o600))
+
+	return next.NewNetworkServiceServer(
+		updatepath.NewServer("ipam"),
+		metadata.NewServer(),
+		configipam.NewServer(ctx, append([]configipam.Option{configipam.WithConfigPath(configPath)}, options...)...),
+	), configPath
+}
+
+func newRequest(networkService string, labels map[string]string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			NetworkService: networkService,
+			Labels:         labels,
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					ExcludedPrefixes: []string{"10.0.0.6/32"},
+				},
+			},
+		},
+	}
+}
+
+func validateConn(t *testing.T, conn *networkservice.Connection, dst, src string) {
+	ipContext := conn.GetContext().GetIpContext()
+	require.Equal(t, []string{dst}, ipContext.GetDstIpAddrs())
+	require.Equal(t, []string{src}, ipContext.GetSrcIpAddrs())
+	require.Equal(t, []string{"10.0.0.6/32"}, ipContext.GetExcludedPrefixes())
+}
+
+func TestConfigIPAMServer_SubPools(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, _ := newConfigIPAMServer(ctx, t, `
+prefixes:
+- 10.0.0.0/24
+reserved:
+- 10.0.0.0/31
+networkServices:
+  ns-2:
+    prefixes:
+    - 10.0.1.0/24
+`)
+
+	conn, err := server.Request(ctx, newRequest("ns-1", nil))
+	require.NoError(t, err)
+	validateConn(t, conn, "10.0.0.2/32", "10.0.0.3/32")
+
+	conn, err = server.Request(ctx, newRequest("ns-2", nil))
+	require.NoError(t, err)
+	validateConn(t, conn, "10.0.1.0/32", "10.0.1.1/32")
+
+	conn, err = server.Request(ctx, newRequest("ns-1", nil))
+	require.NoError(t, err)
+	validateConn(t, conn, "10.0.0.4/32", "10.0.0.5/32")
+
+	// 10.0.0.6/32 is excluded by the client
+	conn, err = server.Request(ctx, newRequest("ns-1", nil))
+	require.NoError(t, err)
+	validateConn(t, conn, "10.0.0.7/32", "10.0.0.8/32")
+}
+
+func TestConfigIPAMServer_NoPrefixes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, _ := newConfigIPAMServer(ctx, t, `
+networkServices:
+  ns-2:
+    prefixes:
+    - 10.0.1.0/24
+`)
+
+	_, err := server.Request(ctx, newRequest("ns-1", nil))
+	require.Error(t, err)
+
+	_, err = server.Request(ctx, newRequest("ns-2", nil))
+	require.NoError(t, err)
+}
+
+func TestConfigIPAMServer_StaticReservations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, _ := newConfigIPAMServer(ctx, t, `
+prefixes:
+- 10.0.0.0/24
+reservations:
+- labels:
+    app: gateway
+  srcAddr: 10.0.0.1/32
+  dstAddr: 10.0.0.0/32
+`)
+	gateway := map[string]string{"app": "gateway", "podName": "gateway-1"}
+
+	conn, err := server.Request(ctx, newRequest("ns-1", map[string]string{"app": "client"}))
+	require.NoError(t, err)
+	validateConn(t, conn, "10.0.0.2/32", "10.0.0.3/32")
+
+	gatewayConn, err := server.Request(ctx, newRequest("ns-1", gateway))
+	require.NoError(t, err)
+	validateConn(t, gatewayConn, "10.0.0.0/32", "10.0.0.1/32")
+
+	// Refresh keeps the reserved addresses
+	gatewayConn, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: gatewayConn})
+	require.NoError(t, err)
+	validateConn(t, gatewayConn, "10.0.0.0/32", "10.0.0.1/32")
+
+	// The reserved addresses are held by the first gateway connection
+	conn, err = server.Request(ctx, newRequest("ns-1", gateway))
+	require.NoError(t, err)
+	validateConn(t, conn, "10.0.0.4/32", "10.0.0.5/32")
+
+	_, err = server.Close(ctx, gatewayConn)
+	require.NoError(t, err)
+
+	gatewayConn, err = server.Request(ctx, newRequest("ns-1", gateway))
+	require.NoError(t, err)
+	validateConn(t, gatewayConn, "10.0.0.0/32", "10.0.0.1/32")
+}
+
+func TestConfigIPAMServer_StaticReservationExcluded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, _ := newConfigIPAMServer(ctx, t, `
+prefixes:
+- 10.0.0.0/24
+reservations:
+- labels:
+    app: gateway
+  srcAddr: 10.0.0.1/32
+  dstAddr: 10.0.0.0/32
+`)
+	gateway := map[string]string{"app": "gateway"}
+
+	// The reserved address is excluded by the client, so the connection gets the addresses from the pool
+	request := newRequest("ns-1", gateway)
+	request.GetConnection().GetContext().GetIpContext().ExcludedPrefixes = []string{"10.0.0.6/32", "10.0.0.1/32"}
+
+	conn, err := server.Request(ctx, request)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.2/32"}, conn.GetContext().GetIpContext().GetDstIpAddrs())
+	require.Equal(t, []string{"10.0.0.3/32"}, conn.GetContext().GetIpContext().GetSrcIpAddrs())
+
+	// The reservation is not held by the connection not having the reserved addresses
+	conn, err = server.Request(ctx, newRequest("ns-1", gateway))
+	require.NoError(t, err)
+	validateConn(t, conn, "10.0.0.0/32", "10.0.0.1/32")
+}
+
+func TestConfigIPAMServer_ReservationRemoved(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, configPath := newConfigIPAMServer(ctx, t, `
+prefixes:
+- 10.0.0.0/24
+reservations:
+- labels:
+    app: gateway
+  srcAddr: 10.0.0.1/32
+  dstAddr: 10.0.0.0/32
+`)
+
+	gatewayConn, err := server.Request(ctx, newRequest("ns-1", map[string]string{"app": "gateway"}))
+	require.NoError(t, err)
+	validateConn(t, gatewayConn, "10.0.0.0/32", "10.0.0.1/32")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+prefixes:
+- 10.0.0.0/24
+`), 0o600))
+
+	_, err = server.Close(ctx, gatewayConn)
+	require.NoError(t, err)
+
+	// The addresses of the removed reservation are handed out to any client
+	require.Eventually(t, func() bool {
+		conn, err := server.Request(ctx, newRequest("ns-1", nil))
+		require.NoError(t, err)
+		_, err = server.Close(ctx, conn)
+		require.NoError(t, err)
+		return conn.GetContext().GetIpContext().GetSrcIpAddrs()[0] == "10.0.0.1/32"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestConfigIPAMServer_SinglePoint(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, _ := newConfigIPAMServer(ctx, t, `
+prefixes:
+- 10.0.0.0/24
+reserved:
+- 10.0.0.1/32
+reservations:
+- labels:
+    app: gateway
+  srcAddr: 10.0.0.2/32
+`, configipam.WithCustomIPAMServer(singlepointipam.NewServer))
+
+	conn, err := server.Request(ctx, newRequest("ns-1", nil))
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.3/24"}, conn.GetContext().GetIpContext().GetSrcIpAddrs())
+
+	conn, err = server.Request(ctx, newRequest("ns-1", map[string]string{"app": "gateway"}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.2/24"}, conn.GetContext().GetIpContext().GetSrcIpAddrs())
+	require.Equal(t, []string{"10.0.0.6/32"}, conn.GetContext().GetIpContext().GetExcludedPrefixes())
+}
+
+func TestConfigIPAMServer_Reload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, configPath := newConfigIPAMServer(ctx, t, `
+prefixes:
+- 10.0.0.0/24
+`)
+
+	conn, err := server.Request(ctx, newRequest("ns-1", nil))
+	require.NoError(t, err)
+	validateConn(t, conn, "10.0.0.0/32", "10.0.0.1/32")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+prefixes:
+- 10.0.0.0/24
+reserved:
+- 10.0.0.1/32
+networkServices:
+  ns-2:
+    prefixes:
+    - 10.0.1.0/24
+`), 0o600))
+
+	require.Eventually(t, func() bool {
+		newConn, err := server.Request(ctx, newRequest("ns-2", nil))
+		require.NoError(t, err)
+		_, err = server.Close(ctx, newConn)
+		require.NoError(t, err)
+		_, ipNet, _ := net.ParseCIDR("10.0.1.0/24")
+		ip, _, _ := net.ParseCIDR(newConn.GetContext().GetIpContext().GetSrcIpAddrs()[0])
+		return ipNet.Contains(ip)
+	}, time.Second, 10*time.Millisecond)
+
+	// The existing connection gets the new addresses on refresh, the reserved address is never handed out
+	conn, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	validateConn(t, conn, "10.0.0.0/32", "10.0.0.2/32")
+}
+
+func TestParseConfig(t *testing.T) {
+	_, err := configipam.ParseConfig([]byte(`
+prefixes:
+- 10.0.0.0/24
+reservations:
+- labels:
+    app: gateway
+  srcAddr: 10.0.1.1/32
+`))
+	require.Error(t, err)
+
+	_, err = configipam.ParseConfig([]byte(`
+prefixes:
+- 10.0.0.0/24
+reservations:
+- srcAddr: 10.0.0.1/32
+`))
+	require.Error(t, err)
+
+	_, err = configipam.ParseConfig([]byte(`
+networkServices:
+  ns-1: {}
+`))
+	require.Error(t, err)
+
+	config, err := configipam.ParseConfig([]byte(`
+prefixes:
+- 10.0.0.0/24
+reserved:
+- 10.0.0.0/30
+networkServices:
+  ns-1:
+    prefixes:
+    - 10.0.1.0/24
+reservations:
+- labels:
+    app: gateway
+  srcAddr: 10.0.1.1/32
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.0/30"}, config.Reserved)
+	require.Equal(t, "10.0.1.1/32", config.Reservations[0].SrcAddr)
+}
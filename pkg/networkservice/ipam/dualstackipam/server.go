@@ -0,0 +1,150 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dualstackipam provides a chain element allocating the addresses of the both IP families for the connection.
+// The addresses of each family are allocated by the separate IPAM server, if any of them fails the already allocated
+// addresses are rolled back. The families can be limited per connection by the address families of the extra prefix
+// requests in the IP context.
+package dualstackipam
+
+import (
+	"context"
+	"net"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/ipaminfo"
+)
+
+type dualStackServer struct {
+	networkservice.NetworkServiceServer
+	families map[networkservice.IpFamily_Family]networkservice.NetworkServiceServer
+}
+
+type familyServer struct {
+	family networkservice.IpFamily_Family
+	server networkservice.NetworkServiceServer
+}
+
+// NewServer - returns a new ipam networkservice.NetworkServiceServer creating the IPAM server with newIPAMServer for
+// each IP family of the prefixes. The returned server implements ipaminfo.Introspector if the created servers do.
+func NewServer(newIPAMServer func(...*net.IPNet) networkservice.NetworkServiceServer, prefixes ...*net.IPNet) networkservice.NetworkServiceServer {
+	if newIPAMServer == nil {
+		panic("newIPAMServer should not be nil")
+	}
+
+	var ip4Prefixes, ip6Prefixes []*net.IPNet
+	for _, prefix := range prefixes {
+		if prefix != nil && prefix.IP.To4() == nil {
+			ip6Prefixes = append(ip6Prefixes, prefix)
+		} else {
+			ip4Prefixes = append(ip4Prefixes, prefix)
+		}
+	}
+
+	s := &dualStackServer{
+		families: make(map[networkservice.IpFamily_Family]networkservice.NetworkServiceServer),
+	}
+	var servers []networkservice.NetworkServiceServer
+	for family, familyPrefixes := range [][]*net.IPNet{ip4Prefixes, ip6Prefixes} {
+		if len(familyPrefixes) == 0 {
+			continue
+		}
+		f := networkservice.IpFamily_Family(family)
+		s.families[f] = newIPAMServer(familyPrefixes...)
+		servers = append(servers, &familyServer{
+			family: f,
+			server: s.families[f],
+		})
+	}
+	if len(servers) == 0 {
+		// Let the IPAM server report the missing prefixes
+		servers = append(servers, newIPAMServer())
+	}
+	s.NetworkServiceServer = next.NewNetworkServiceServer(servers...)
+
+	return s
+}
+
+func (s *dualStackServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	for family := range requestedFamilies(request.GetConnection()) {
+		if _, ok := s.families[family]; !ok {
+			return nil, errors.Errorf("no %s prefixes are configured", family.String())
+		}
+	}
+	return s.NetworkServiceServer.Request(ctx, request)
+}
+
+// Allocations returns the addresses of all the families allocated to the connections
+func (s *dualStackServer) Allocations() []*ipaminfo.Allocation {
+	var allocations []*ipaminfo.Allocation
+	byConnection := make(map[string]*ipaminfo.Allocation)
+	for _, family := range []networkservice.IpFamily_Family{networkservice.IpFamily_IPV4, networkservice.IpFamily_IPV6} {
+		introspector, ok := s.families[family].(ipaminfo.Introspector)
+		if !ok {
+			continue
+		}
+		for _, allocation := range introspector.Allocations() {
+			if existing, ok := byConnection[allocation.ConnectionID]; ok && allocation.ConnectionID != "" {
+				existing.Addresses = append(existing.Addresses, allocation.Addresses...)
+				continue
+			}
+			byConnection[allocation.ConnectionID] = allocation
+			allocations = append(allocations, allocation)
+		}
+	}
+	return ipaminfo.SortAllocations(allocations)
+}
+
+// Usage returns the usage of the prefixes of all the families
+func (s *dualStackServer) Usage() []*ipaminfo.PrefixUsage {
+	var usage []*ipaminfo.PrefixUsage
+	for _, family := range []networkservice.IpFamily_Family{networkservice.IpFamily_IPV4, networkservice.IpFamily_IPV6} {
+		if introspector, ok := s.families[family].(ipaminfo.Introspector); ok {
+			usage = append(usage, introspector.Usage()...)
+		}
+	}
+	return usage
+}
+
+func (s *familyServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	if families := requestedFamilies(request.GetConnection()); len(families) != 0 {
+		if _, ok := families[s.family]; !ok {
+			return next.Server(ctx).Request(ctx, request)
+		}
+	}
+	return s.server.Request(ctx, request)
+}
+
+func (s *familyServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return s.server.Close(ctx, conn)
+}
+
+// requestedFamilies returns the address families of the extra prefix requests, all the families are requested if there
+// are no such hints
+func requestedFamilies(conn *networkservice.Connection) map[networkservice.IpFamily_Family]struct{} {
+	families := make(map[networkservice.IpFamily_Family]struct{})
+	for _, r := range conn.GetContext().GetIpContext().GetExtraPrefixRequest() {
+		if r.GetAddrFamily() != nil {
+			families[r.GetAddrFamily().GetFamily()] = struct{}{}
+		}
+	}
+	return families
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dualstackipam_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/updatepath"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/dualstackipam"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/point2pointipam"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/ipaminfo"
+)
+
+func newIpamServer(t *testing.T, prefixes ...string) (networkservice.NetworkServiceServer, ipaminfo.Introspector) {
+	var ipNets []*net.IPNet
+	for _, prefix := range prefixes {
+		_, ipNet, err := net.ParseCIDR(prefix)
+		require.NoError(t, err)
+		ipNets = append(ipNets, ipNet)
+	}
+
+	ipamServer := dualstackipam.NewServer(point2pointipam.NewServer, ipNets...)
+	introspector, ok := ipamServer.(ipaminfo.Introspector)
+	require.True(t, ok)
+
+	return next.NewNetworkServiceServer(
+		updatepath.NewServer("ipam"),
+		metadata.NewServer(),
+		ipamServer,
+	), introspector
+}
+
+func newRequest(families ...networkservice.IpFamily_Family) *networkservice.NetworkServiceRequest {
+	ipContext := new(networkservice.IPContext)
+	for _, family := range families {
+		ipContext.ExtraPrefixRequest = append(ipContext.ExtraPrefixRequest, &networkservice.ExtraPrefixRequest{
+			AddrFamily: &networkservice.IpFamily{Family: family},
+		})
+	}
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Context: &networkservice.ConnectionContext{
+				IpContext: ipContext,
+			},
+		},
+	}
+}
+
+func validateConn(t *testing.T, conn *networkservice.Connection, dsts, srcs []string) {
+	ipContext := conn.GetContext().GetIpContext()
+	require.Equal(t, dsts, ipContext.GetDstIpAddrs())
+	require.Equal(t, srcs, ipContext.GetSrcIpAddrs())
+
+	var srcRoutes, dstRoutes []string
+	for _, route := range ipContext.GetSrcRoutes() {
+		srcRoutes = append(srcRoutes, route.GetPrefix())
+	}
+	for _, route := range ipContext.GetDstRoutes() {
+		dstRoutes = append(dstRoutes, route.GetPrefix())
+	}
+	require.Equal(t, dsts, srcRoutes)
+	require.Equal(t, srcs, dstRoutes)
+}
+
+func TestDualStack(t *testing.T) {
+	srv, introspector := newIpamServer(t, "fe80::/64", "10.0.0.0/24")
+
+	conn, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+	validateConn(t, conn, []string{"10.0.0.0/32", "fe80::/128"}, []string{"10.0.0.1/32", "fe80::1/128"})
+
+	// Refresh keeps the addresses
+	conn, err = srv.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	validateConn(t, conn, []string{"10.0.0.0/32", "fe80::/128"}, []string{"10.0.0.1/32", "fe80::1/128"})
+
+	allocations := introspector.Allocations()
+	require.Len(t, allocations, 1)
+	require.Equal(t, conn.GetId(), allocations[0].ConnectionID)
+	require.Equal(t, []string{"10.0.0.1/32", "10.0.0.0/32", "fe80::1/128", "fe80::/128"}, allocations[0].Addresses)
+	require.Len(t, introspector.Usage(), 2)
+}
+
+func TestDualStack_FamilyHints(t *testing.T) {
+	srv, _ := newIpamServer(t, "10.0.0.0/24", "fe80::/64")
+
+	conn, err := srv.Request(context.Background(), newRequest(networkservice.IpFamily_IPV6))
+	require.NoError(t, err)
+	validateConn(t, conn, []string{"fe80::/128"}, []string{"fe80::1/128"})
+
+	conn, err = srv.Request(context.Background(), newRequest(networkservice.IpFamily_IPV4))
+	require.NoError(t, err)
+	validateConn(t, conn, []string{"10.0.0.0/32"}, []string{"10.0.0.1/32"})
+
+	srv, _ = newIpamServer(t, "10.0.0.0/24")
+
+	_, err = srv.Request(context.Background(), newRequest(networkservice.IpFamily_IPV6))
+	require.Error(t, err)
+}
+
+func TestDualStack_Rollback(t *testing.T) {
+	srv, introspector := newIpamServer(t, "10.0.0.0/24", "fe80::/127")
+
+	conn1, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+	validateConn(t, conn1, []string{"10.0.0.0/32", "fe80::/128"}, []string{"10.0.0.1/32", "fe80::1/128"})
+
+	// There are no IPv6 addresses, so the allocated IPv4 addresses are rolled back
+	_, err = srv.Request(context.Background(), newRequest())
+	require.Error(t, err)
+	require.Len(t, introspector.Allocations(), 1)
+
+	conn2, err := srv.Request(context.Background(), newRequest(networkservice.IpFamily_IPV4))
+	require.NoError(t, err)
+	validateConn(t, conn2, []string{"10.0.0.2/32"}, []string{"10.0.0.3/32"})
+
+	_, err = srv.Close(context.Background(), conn1)
+	require.NoError(t, err)
+
+	conn3, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+	validateConn(t, conn3, []string{"10.0.0.0/32", "fe80::/128"}, []string{"10.0.0.1/32", "fe80::1/128"})
+}
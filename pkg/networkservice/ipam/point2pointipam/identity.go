@@ -0,0 +1,65 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package point2pointipam
+
+import (
+	"context"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+const podNameLabel = "podName"
+
+// IdentityFunc returns the identity of the client requesting the connection. The empty identity disables the sticky
+// address assignment for the connection.
+type IdentityFunc func(ctx context.Context, conn *networkservice.Connection) string
+
+// SpiffeIDIdentity identifies the client by the spiffe ID from the token of the first path segment
+func SpiffeIDIdentity(_ context.Context, conn *networkservice.Connection) string {
+	segments := conn.GetPath().GetPathSegments()
+	if len(segments) == 0 {
+		return ""
+	}
+
+	// The token is validated by the authorize chain element, so there is no need to verify it here
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(segments[0].GetToken(), &claims); err != nil {
+		return ""
+	}
+	id, err := spiffeid.FromString(claims.Subject)
+	if err != nil {
+		return ""
+	}
+	return id.String()
+}
+
+// PodNameIdentity identifies the client by the "podName" label set by the clientinfo. The label is set by the client,
+// so any client can take the reservation of another one: use it only if all the clients are trusted.
+func PodNameIdentity(ctx context.Context, conn *networkservice.Connection) string {
+	return LabelIdentity(podNameLabel)(ctx, conn)
+}
+
+// LabelIdentity identifies the client by the value of the connection label. The label is set by the client, so any
+// client can take the reservation of another one: use it only if all the clients are trusted.
+func LabelIdentity(label string) IdentityFunc {
+	return func(_ context.Context, conn *networkservice.Connection) string {
+		return conn.GetLabels()[label]
+	}
+}
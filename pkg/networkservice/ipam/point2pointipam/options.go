@@ -0,0 +1,67 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package point2pointipam
+
+import (
+	"context"
+	"time"
+)
+
+const defaultRetention = 10 * time.Minute
+
+// Option is an option pattern for NewServerWithOptions
+type Option func(s *ipamServer)
+
+// WithContext sets the context used to get the clock and the logger. Default: context.Background()
+func WithContext(ctx context.Context) Option {
+	return func(s *ipamServer) {
+		s.ctx = ctx
+	}
+}
+
+// WithIdentity enables the sticky address assignment: the addresses of the closed connection are reserved for the
+// client identity for the retention period, so the new connection of the same client gets the same src/dst pair.
+// The reservation is bound to the network service, the connection requesting the reserved addresses while they are
+// still used by another connection of the same client gets the new addresses.
+func WithIdentity(identity IdentityFunc) Option {
+	return func(s *ipamServer) {
+		s.identity = identity
+	}
+}
+
+// WithRetention sets the time the released addresses are reserved for the client identity. Default: 10 minutes
+func WithRetention(retention time.Duration) Option {
+	return func(s *ipamServer) {
+		s.retention = retention
+	}
+}
+
+// WithDualStack enables the dual-stack mode: the connection gets the addresses and the routes of the both IP families
+// of the prefixes or fails. The families are limited by the address families of the extra prefix requests if any.
+func WithDualStack() Option {
+	return func(s *ipamServer) {
+		s.dualStack = true
+	}
+}
+
+// WithReservationStore sets the store for the reservations. The server restores the reservations on the first use,
+// all the restored addresses are released after the retention period unless reclaimed by the clients.
+func WithReservationStore(store ReservationStore) Option {
+	return func(s *ipamServer) {
+		s.store = store
+	}
+}
@@ -1,6 +1,6 @@
 // Copyright (c) 2020-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2022-2023 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -23,6 +23,7 @@ import (
 	"context"
 	"net"
 	"sync"
+	"time"
 
 	"github.com/edwarnicke/genericsync"
 	"github.com/golang/protobuf/ptypes/empty"
@@ -31,6 +32,8 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/dualstackipam"
+	"github.com/networkservicemesh/sdk/pkg/tools/ipaminfo"
 	"github.com/networkservicemesh/sdk/pkg/tools/ippool"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
 )
@@ -41,12 +44,21 @@ type ipamServer struct {
 	prefixes []*net.IPNet
 	once     sync.Once
 	initErr  error
+
+	ctx            context.Context
+	dualStack      bool
+	identity       IdentityFunc
+	retention      time.Duration
+	store          ReservationStore
+	reservations   map[reservationKey]*reservation
+	reservationsMu sync.Mutex
 }
 
 type connectionInfo struct {
-	ipPool  *ippool.IPPool
-	srcAddr string
-	dstAddr string
+	ipPool      *ippool.IPPool
+	srcAddr     string
+	dstAddr     string
+	reservation reservationKey
 }
 
 func (i *connectionInfo) shouldUpdate(exclude *ippool.IPPool) bool {
@@ -57,10 +69,30 @@ func (i *connectionInfo) shouldUpdate(exclude *ippool.IPPool) bool {
 }
 
 // NewServer - creates a new NetworkServiceServer chain element that implements IPAM service.
+// The returned server implements ipaminfo.Introspector.
 func NewServer(prefixes ...*net.IPNet) networkservice.NetworkServiceServer {
-	return &ipamServer{
-		prefixes: prefixes,
+	return NewServerWithOptions(prefixes)
+}
+
+// NewServerWithOptions - creates a new NetworkServiceServer chain element that implements IPAM service with the
+// options. The returned server implements ipaminfo.Introspector.
+func NewServerWithOptions(prefixes []*net.IPNet, options ...Option) networkservice.NetworkServiceServer {
+	s := &ipamServer{
+		prefixes:     prefixes,
+		ctx:          context.Background(),
+		retention:    defaultRetention,
+		reservations: make(map[reservationKey]*reservation),
+	}
+	for _, opt := range options {
+		opt(s)
 	}
+	if s.dualStack {
+		familyOptions := append(append([]Option(nil), options...), func(s *ipamServer) { s.dualStack = false })
+		return dualstackipam.NewServer(func(familyPrefixes ...*net.IPNet) networkservice.NetworkServiceServer {
+			return NewServerWithOptions(familyPrefixes, familyOptions...)
+		}, prefixes...)
+	}
+	return s
 }
 
 func (s *ipamServer) init() {
@@ -76,6 +108,13 @@ func (s *ipamServer) init() {
 		}
 		s.ipPools = append(s.ipPools, ippool.NewWithNet(prefix))
 	}
+
+	if s.identity != nil && s.store != nil {
+		s.reservationsMu.Lock()
+		defer s.reservationsMu.Unlock()
+
+		s.restore()
+	}
 }
 
 func (s *ipamServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
@@ -103,16 +142,22 @@ func (s *ipamServer) Request(ctx context.Context, request *networkservice.Networ
 		deleteAddr(&ipContext.DstIpAddrs, connInfo.dstAddr)
 		deleteRoute(&ipContext.SrcRoutes, connInfo.dstAddr)
 		deleteRoute(&ipContext.DstRoutes, connInfo.srcAddr)
+		s.unreserve(conn.GetId(), connInfo)
 		s.free(connInfo)
 		loaded = false
 	}
-	// If not loaded, we are primarily trying to recover addresses from the IpContext. In case of an error, allocate new ones.
+	// If not loaded, we are primarily trying to recover addresses from the IpContext, then to reclaim the addresses
+	// reserved for the client. In case of an error, allocate new ones.
 	if !loaded {
+		key := s.reservationKey(ctx, conn)
 		if connInfo, err = s.recoverAddrs(ipContext.GetSrcIpAddrs(), ipContext.GetDstIpAddrs(), excludeIP4, excludeIP6); err == nil {
 			log.FromContext(ctx).Infof("addresses have been recovered - srcIP: %v, dstIP: %v", connInfo.srcAddr, connInfo.dstAddr)
+		} else if connInfo, err = s.reclaim(key, conn.GetId(), excludeIP4, excludeIP6); err == nil {
+			log.FromContext(ctx).Infof("addresses have been reclaimed for %v - srcIP: %v, dstIP: %v", key.identity, connInfo.srcAddr, connInfo.dstAddr)
 		} else if connInfo, err = s.getP2PAddrs(excludeIP4, excludeIP6); err != nil {
 			return nil, err
 		}
+		s.reserve(key, conn.GetId(), connInfo)
 		s.Store(conn.GetId(), connInfo)
 	}
 
@@ -125,7 +170,8 @@ func (s *ipamServer) Request(ctx context.Context, request *networkservice.Networ
 	conn, err = next.Server(ctx).Request(ctx, request)
 	if err != nil {
 		if !loaded {
-			s.free(connInfo)
+			s.Delete(request.GetConnection().GetId())
+			s.release(request.GetConnection().GetId(), connInfo)
 		}
 		return nil, err
 	}
@@ -156,6 +202,13 @@ func (s *ipamServer) recoverAddrs(srcAddrs, dstAddrs []string, excludeIP4, exclu
 				dstAddr: dstAddr.String(),
 			}, nil
 		}
+		// Only the pair can be recovered, the single recovered address is returned to the pool
+		if srcAddr != nil {
+			ipPool.AddNetString(srcAddr.String())
+		}
+		if dstAddr != nil {
+			ipPool.AddNetString(dstAddr.String())
+		}
 	}
 
 	return nil, errors.Errorf("unable to recover: %+v, %+v", srcAddrs, dstAddrs)
@@ -220,7 +273,7 @@ func (s *ipamServer) Close(ctx context.Context, conn *networkservice.Connection)
 	}
 
 	if connInfo, ok := s.LoadAndDelete(conn.GetId()); ok {
-		s.free(connInfo)
+		s.release(conn.GetId(), connInfo)
 	}
 
 	return next.Server(ctx).Close(ctx, conn)
@@ -230,3 +283,41 @@ func (s *ipamServer) free(connInfo *connectionInfo) {
 	connInfo.ipPool.AddNetString(connInfo.srcAddr)
 	connInfo.ipPool.AddNetString(connInfo.dstAddr)
 }
+
+// Allocations returns the addresses allocated to the connections
+func (s *ipamServer) Allocations() []*ipaminfo.Allocation {
+	var allocations []*ipaminfo.Allocation
+	s.Range(func(id string, connInfo *connectionInfo) bool {
+		allocations = append(allocations, &ipaminfo.Allocation{
+			ConnectionID: id,
+			Addresses:    []string{connInfo.srcAddr, connInfo.dstAddr},
+		})
+		return true
+	})
+
+	s.reservationsMu.Lock()
+	defer s.reservationsMu.Unlock()
+
+	for _, r := range s.reservations {
+		if r.connID == "" {
+			allocations = append(allocations, &ipaminfo.Allocation{
+				Addresses: []string{r.connInfo.srcAddr, r.connInfo.dstAddr},
+			})
+		}
+	}
+	return ipaminfo.SortAllocations(allocations)
+}
+
+// Usage returns the usage of the prefixes
+func (s *ipamServer) Usage() []*ipaminfo.PrefixUsage {
+	s.once.Do(s.init)
+	if s.initErr != nil {
+		return nil
+	}
+
+	var usage []*ipaminfo.PrefixUsage
+	for i, ipPool := range s.ipPools {
+		usage = append(usage, ipaminfo.NewPrefixUsage(s.prefixes[i], ipPool.AddressCount()))
+	}
+	return usage
+}
@@ -1,6 +1,6 @@
 // Copyright (c) 2020-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2022 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -34,6 +34,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/point2pointipam"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/ipaminfo"
 )
 
 func newIpamServer(prefixes ...*net.IPNet) networkservice.NetworkServiceServer {
@@ -462,6 +463,33 @@ func TestRecoveryServer(t *testing.T) {
 	validateConn(t, conn3, "192.168.10.0/32", "192.168.10.1/32")
 }
 
+func TestPartialRecoveryServer(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("192.168.3.4/16")
+	require.NoError(t, err)
+
+	srv := newIpamServer(ipNet)
+
+	conn1, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+	validateConn(t, conn1, "192.168.0.0/32", "192.168.0.1/32")
+
+	// The src address is excluded, so the recovery fails and the dst address is not used
+	request2 := newRequest()
+	request2.Connection.Context.IpContext.ExcludedPrefixes = []string{"192.168.0.5/32"}
+	request2.Connection.Context.IpContext.DstIpAddrs = []string{"192.168.0.4/32"}
+	request2.Connection.Context.IpContext.SrcIpAddrs = []string{"192.168.0.5/32"}
+
+	conn2, err := srv.Request(context.Background(), request2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.168.0.4/32", "192.168.0.2/32"}, conn2.GetContext().GetIpContext().GetDstIpAddrs())
+	require.Equal(t, []string{"192.168.0.5/32", "192.168.0.3/32"}, conn2.GetContext().GetIpContext().GetSrcIpAddrs())
+
+	// The dst address is returned to the pool
+	conn3, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+	validateConn(t, conn3, "192.168.0.4/32", "192.168.0.5/32")
+}
+
 //nolint:dupl
 func TestRecoveryServerIPv6(t *testing.T) {
 	_, ipNet, err := net.ParseCIDR("fe80::/64")
@@ -536,3 +564,49 @@ func TestRecoveryServers(t *testing.T) {
 	require.NoError(t, err)
 	validateConns(t, conn3, []string{"192.168.10.0/32", "fe80::fa00/128"}, []string{"192.168.10.1/32", "fe80::fa01/128"})
 }
+
+func TestIntrospection(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("192.168.3.4/29")
+	require.NoError(t, err)
+
+	ipamServer := point2pointipam.NewServer(ipNet)
+	introspector, ok := ipamServer.(ipaminfo.Introspector)
+	require.True(t, ok)
+
+	srv := next.NewNetworkServiceServer(
+		updatepath.NewServer("ipam"),
+		metadata.NewServer(),
+		ipamServer,
+	)
+
+	conn1, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+	conn2, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+
+	allocations := introspector.Allocations()
+	require.Len(t, allocations, 2)
+	for _, allocation := range allocations {
+		conn := conn1
+		if allocation.ConnectionID == conn2.GetId() {
+			conn = conn2
+		}
+		require.Equal(t, conn.GetId(), allocation.ConnectionID)
+		require.Equal(t, []string{conn.GetContext().GetIpContext().GetSrcIpAddrs()[0], conn.GetContext().GetIpContext().GetDstIpAddrs()[0]}, allocation.Addresses)
+	}
+
+	usage := introspector.Usage()
+	require.Len(t, usage, 1)
+	require.Equal(t, "192.168.3.0/29", usage[0].Prefix)
+	require.Equal(t, int64(8), usage[0].Total.Int64())
+	require.Equal(t, int64(4), usage[0].Free.Int64())
+	require.Equal(t, 50., usage[0].Utilization())
+
+	_, err = srv.Close(context.Background(), conn1)
+	require.NoError(t, err)
+
+	allocations = introspector.Allocations()
+	require.Len(t, allocations, 1)
+	require.Equal(t, conn2.GetId(), allocations[0].ConnectionID)
+	require.Equal(t, int64(6), introspector.Usage()[0].Free.Int64())
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package point2pointipam
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/ippool"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type reservationKey struct {
+	networkService string
+	identity       string
+}
+
+type reservation struct {
+	connInfo       *connectionInfo
+	connID         string
+	expirationTime time.Time
+	timer          clock.Timer
+}
+
+// reservationKey returns the zero key if the sticky address assignment is disabled for the connection
+func (s *ipamServer) reservationKey(ctx context.Context, conn *networkservice.Connection) reservationKey {
+	if s.identity == nil {
+		return reservationKey{}
+	}
+	return reservationKey{
+		networkService: conn.GetNetworkService(),
+		identity:       s.identity(ctx, conn),
+	}
+}
+
+// reclaim returns the addresses released by the connection of the same client if they are still reserved
+func (s *ipamServer) reclaim(key reservationKey, connID string, excludeIP4, excludeIP6 *ippool.IPPool) (*connectionInfo, error) {
+	if key.identity == "" {
+		return nil, errors.New("sticky address assignment is disabled for the connection")
+	}
+
+	s.reservationsMu.Lock()
+	defer s.reservationsMu.Unlock()
+
+	r, ok := s.reservations[key]
+	if !ok || r.connID != "" {
+		return nil, errors.Errorf("no reserved addresses for %s", key.identity)
+	}
+	r.timer.Stop()
+	if r.connInfo.shouldUpdate(excludeIP4) || r.connInfo.shouldUpdate(excludeIP6) {
+		s.deleteReservation(key)
+		s.free(r.connInfo)
+		return nil, errors.Errorf("reserved addresses for %s are excluded", key.identity)
+	}
+
+	r.connID = connID
+	r.expirationTime = time.Time{}
+	s.storeReservation(key, r)
+	return r.connInfo, nil
+}
+
+// reserve reserves the addresses for the client unless its reservation is held by another connection
+func (s *ipamServer) reserve(key reservationKey, connID string, connInfo *connectionInfo) {
+	if key.identity == "" {
+		return
+	}
+
+	s.reservationsMu.Lock()
+	defer s.reservationsMu.Unlock()
+
+	if r, ok := s.reservations[key]; ok {
+		if r.connInfo == connInfo || r.connID != "" && r.connID != connID {
+			return
+		}
+		if r.connID == "" {
+			r.timer.Stop()
+			s.free(r.connInfo)
+		}
+	}
+
+	connInfo.reservation = key
+	r := &reservation{
+		connInfo: connInfo,
+		connID:   connID,
+	}
+	s.reservations[key] = r
+	s.storeReservation(key, r)
+}
+
+// release keeps the addresses of the closed connection reserved for the retention period
+func (s *ipamServer) release(connID string, connInfo *connectionInfo) {
+	s.reservationsMu.Lock()
+	defer s.reservationsMu.Unlock()
+
+	key := connInfo.reservation
+	r, ok := s.reservations[key]
+	if !ok || r.connID != connID || r.connInfo != connInfo {
+		s.free(connInfo)
+		return
+	}
+	if s.retention <= 0 {
+		s.deleteReservation(key)
+		s.free(connInfo)
+		return
+	}
+
+	r.connID = ""
+	s.expireReservation(key, r, clock.FromContext(s.ctx).Now().Add(s.retention))
+	s.storeReservation(key, r)
+}
+
+// unreserve deletes the reservation held by the connection
+func (s *ipamServer) unreserve(connID string, connInfo *connectionInfo) {
+	s.reservationsMu.Lock()
+	defer s.reservationsMu.Unlock()
+
+	if r, ok := s.reservations[connInfo.reservation]; ok && r.connID == connID && r.connInfo == connInfo {
+		s.deleteReservation(connInfo.reservation)
+	}
+}
+
+// expireReservation should be called under the reservationsMu
+func (s *ipamServer) expireReservation(key reservationKey, r *reservation, expirationTime time.Time) {
+	clockTime := clock.FromContext(s.ctx)
+
+	r.expirationTime = expirationTime
+	r.timer = clockTime.AfterFunc(clockTime.Until(expirationTime), func() {
+		s.reservationsMu.Lock()
+		defer s.reservationsMu.Unlock()
+
+		if s.reservations[key] != r || r.connID != "" || !r.expirationTime.Equal(expirationTime) {
+			return
+		}
+		s.deleteReservation(key)
+		s.free(r.connInfo)
+	})
+}
+
+// restore should be called under the reservationsMu
+func (s *ipamServer) restore() {
+	logger := log.FromContext(s.ctx).WithField("point2pointipam", "restore")
+
+	reservations, err := s.store.Load()
+	if err != nil {
+		logger.Errorf("failed to load reservations from the store: %s", err.Error())
+		return
+	}
+
+	now := clock.FromContext(s.ctx).Now()
+	for _, stored := range reservations {
+		if !s.containsAddr(stored.SrcAddr) {
+			// The reservation belongs to another server sharing the store, e.g. of another IP family
+			continue
+		}
+		if !stored.ExpirationTime.IsZero() && !now.Before(stored.ExpirationTime) {
+			s.deleteStored(stored)
+			continue
+		}
+		connInfo, err := s.pullReserved(stored.SrcAddr, stored.DstAddr)
+		if err != nil {
+			logger.Warnf("failed to restore reservation for %s: %s", stored.Identity, err.Error())
+			s.deleteStored(stored)
+			continue
+		}
+		key := reservationKey{networkService: stored.NetworkService, identity: stored.Identity}
+		connInfo.reservation = key
+
+		// The restored reservations are not used by the connections until reclaimed
+		expirationTime := stored.ExpirationTime
+		if expirationTime.IsZero() {
+			expirationTime = now.Add(s.retention)
+		}
+		r := &reservation{connInfo: connInfo}
+		s.reservations[key] = r
+		s.expireReservation(key, r, expirationTime)
+		s.storeReservation(key, r)
+	}
+}
+
+func (s *ipamServer) containsAddr(addr string) bool {
+	ip, _, err := net.ParseCIDR(addr)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range s.prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ipamServer) pullReserved(srcAddr, dstAddr string) (*connectionInfo, error) {
+	srcIP, _, err := net.ParseCIDR(srcAddr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", srcAddr)
+	}
+	dstIP, _, err := net.ParseCIDR(dstAddr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", dstAddr)
+	}
+
+	for i, prefix := range s.prefixes {
+		if !prefix.Contains(srcIP) || !prefix.Contains(dstIP) {
+			continue
+		}
+		ipPool := s.ipPools[i]
+		src, err := ipPool.PullIPString(srcAddr)
+		if err != nil {
+			return nil, err
+		}
+		dst, err := ipPool.PullIPString(dstAddr)
+		if err != nil {
+			ipPool.AddNetString(src.String())
+			return nil, err
+		}
+		return &connectionInfo{
+			ipPool:  ipPool,
+			srcAddr: src.String(),
+			dstAddr: dst.String(),
+		}, nil
+	}
+	return nil, errors.Errorf("addresses %s, %s are out of the prefixes", srcAddr, dstAddr)
+}
+
+// deleteReservation should be called under the reservationsMu
+func (s *ipamServer) deleteReservation(key reservationKey) {
+	r, ok := s.reservations[key]
+	if !ok {
+		return
+	}
+	delete(s.reservations, key)
+	if s.store != nil {
+		s.deleteStored(toStored(key, r))
+	}
+}
+
+func (s *ipamServer) deleteStored(stored *Reservation) {
+	if err := s.store.Delete(stored); err != nil {
+		log.FromContext(s.ctx).WithField("point2pointipam", "store").Errorf("failed to delete reservation for %s: %s", stored.Identity, err.Error())
+	}
+}
+
+// storeReservation should be called under the reservationsMu
+func (s *ipamServer) storeReservation(key reservationKey, r *reservation) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.Store(toStored(key, r)); err != nil {
+		log.FromContext(s.ctx).WithField("point2pointipam", "store").Errorf("failed to store reservation for %s: %s", key.identity, err.Error())
+	}
+}
+
+func toStored(key reservationKey, r *reservation) *Reservation {
+	return &Reservation{
+		NetworkService: key.networkService,
+		Identity:       key.identity,
+		SrcAddr:        r.connInfo.srcAddr,
+		DstAddr:        r.connInfo.dstAddr,
+		ExpirationTime: r.expirationTime,
+	}
+}
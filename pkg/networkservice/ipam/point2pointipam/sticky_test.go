@@ -0,0 +1,253 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package point2pointipam_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/updatepath"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/point2pointipam"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
+)
+
+func newStickyIpamServer(ctx context.Context, ipNet *net.IPNet, options ...point2pointipam.Option) networkservice.NetworkServiceServer {
+	return next.NewNetworkServiceServer(
+		updatepath.NewServer("ipam"),
+		metadata.NewServer(),
+		point2pointipam.NewServerWithOptions([]*net.IPNet{ipNet}, append([]point2pointipam.Option{
+			point2pointipam.WithContext(ctx),
+			point2pointipam.WithIdentity(point2pointipam.PodNameIdentity),
+		}, options...)...),
+	)
+}
+
+func newPodRequest(podName string) *networkservice.NetworkServiceRequest {
+	request := newRequest()
+	request.Connection.NetworkService = "ns"
+	request.Connection.Labels = map[string]string{"podName": podName}
+	return request
+}
+
+func TestStickyServer(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	_, ipNet, err := net.ParseCIDR("192.168.3.4/16")
+	require.NoError(t, err)
+
+	srv := newStickyIpamServer(ctx, ipNet, point2pointipam.WithRetention(time.Minute))
+
+	conn, err := srv.Request(ctx, newPodRequest("pod-1"))
+	require.NoError(t, err)
+	validateConn(t, conn, "192.168.0.0/32", "192.168.0.1/32")
+
+	_, err = srv.Close(ctx, conn)
+	require.NoError(t, err)
+
+	// The addresses are reserved for pod-1
+	conn, err = srv.Request(ctx, newPodRequest("pod-2"))
+	require.NoError(t, err)
+	validateConn(t, conn, "192.168.0.2/32", "192.168.0.3/32")
+
+	// The new connection of pod-1 gets the same addresses
+	conn, err = srv.Request(ctx, newPodRequest("pod-1"))
+	require.NoError(t, err)
+	validateConn(t, conn, "192.168.0.0/32", "192.168.0.1/32")
+
+	// The addresses are used by the alive connection of pod-1
+	conn2, err := srv.Request(ctx, newPodRequest("pod-1"))
+	require.NoError(t, err)
+	validateConn(t, conn2, "192.168.0.4/32", "192.168.0.5/32")
+
+	_, err = srv.Close(ctx, conn2)
+	require.NoError(t, err)
+
+	// The client without the identity is not affected by the reservations
+	conn2, err = srv.Request(ctx, newRequest())
+	require.NoError(t, err)
+	validateConn(t, conn2, "192.168.0.4/32", "192.168.0.5/32")
+
+	_, err = srv.Close(ctx, conn)
+	require.NoError(t, err)
+
+	clockMock.Add(time.Minute)
+
+	// The addresses are returned to the pool after the retention period
+	require.Eventually(t, func() bool {
+		conn, err = srv.Request(ctx, newPodRequest("pod-3"))
+		require.NoError(t, err)
+		_, err = srv.Close(ctx, conn)
+		require.NoError(t, err)
+		return conn.GetContext().GetIpContext().GetDstIpAddrs()[0] == "192.168.0.0/32"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStickyServer_ExcludedPrefixes(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("192.168.3.4/16")
+	require.NoError(t, err)
+
+	srv := newStickyIpamServer(context.Background(), ipNet)
+
+	conn, err := srv.Request(context.Background(), newPodRequest("pod-1"))
+	require.NoError(t, err)
+	validateConn(t, conn, "192.168.0.0/32", "192.168.0.1/32")
+
+	_, err = srv.Close(context.Background(), conn)
+	require.NoError(t, err)
+
+	request := newPodRequest("pod-1")
+	request.Connection.Context.IpContext.ExcludedPrefixes = []string{"192.168.0.1/32"}
+
+	conn, err = srv.Request(context.Background(), request)
+	require.NoError(t, err)
+	validateConn(t, conn, "192.168.0.0/32", "192.168.0.2/32")
+}
+
+func TestStickyServer_Store(t *testing.T) {
+	store := point2pointipam.NewFileReservationStore(filepath.Join(t.TempDir(), "reservations.json"))
+
+	_, ipNet, err := net.ParseCIDR("192.168.3.4/16")
+	require.NoError(t, err)
+
+	srv := newStickyIpamServer(context.Background(), ipNet, point2pointipam.WithReservationStore(store))
+
+	conn, err := srv.Request(context.Background(), newPodRequest("pod-1"))
+	require.NoError(t, err)
+	validateConn(t, conn, "192.168.0.0/32", "192.168.0.1/32")
+
+	reservations, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, reservations, 1)
+	require.Equal(t, "pod-1", reservations[0].Identity)
+	require.True(t, reservations[0].ExpirationTime.IsZero())
+
+	// The restarted server restores the reservations
+	srv = newStickyIpamServer(context.Background(), ipNet, point2pointipam.WithReservationStore(store))
+
+	conn, err = srv.Request(context.Background(), newPodRequest("pod-2"))
+	require.NoError(t, err)
+	validateConn(t, conn, "192.168.0.2/32", "192.168.0.3/32")
+
+	conn, err = srv.Request(context.Background(), newPodRequest("pod-1"))
+	require.NoError(t, err)
+	validateConn(t, conn, "192.168.0.0/32", "192.168.0.1/32")
+
+	reservations, err = store.Load()
+	require.NoError(t, err)
+	require.Len(t, reservations, 2)
+}
+
+func TestFileReservationStore_DeleteStale(t *testing.T) {
+	store := point2pointipam.NewFileReservationStore(filepath.Join(t.TempDir(), "reservations.json"))
+
+	stale := &point2pointipam.Reservation{NetworkService: "ns", Identity: "pod-1", SrcAddr: "192.168.0.0/32", DstAddr: "192.168.0.1/32"}
+	require.NoError(t, store.Store(stale))
+
+	fresh := &point2pointipam.Reservation{NetworkService: "ns", Identity: "pod-1", SrcAddr: "192.168.0.2/32", DstAddr: "192.168.0.3/32"}
+	require.NoError(t, store.Store(fresh))
+
+	// The stale reservation is already replaced, deleting it keeps the fresh one
+	require.NoError(t, store.Delete(stale))
+
+	reservations, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, []*point2pointipam.Reservation{fresh}, reservations)
+
+	require.NoError(t, store.Delete(fresh))
+
+	reservations, err = store.Load()
+	require.NoError(t, err)
+	require.Empty(t, reservations)
+}
+
+func TestSpiffeIDIdentity(t *testing.T) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject: "spiffe://test.com/client",
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	conn := &networkservice.Connection{
+		Path: &networkservice.Path{
+			PathSegments: []*networkservice.PathSegment{{Token: token}, {}},
+		},
+	}
+	require.Equal(t, "spiffe://test.com/client", point2pointipam.SpiffeIDIdentity(context.Background(), conn))
+
+	conn.Path.PathSegments[0].Token = "invalid"
+	require.Empty(t, point2pointipam.SpiffeIDIdentity(context.Background(), conn))
+}
+
+func TestStickyServer_DualStack(t *testing.T) {
+	store := point2pointipam.NewFileReservationStore(filepath.Join(t.TempDir(), "reservations.json"))
+
+	_, ipNet, err := net.ParseCIDR("192.168.3.4/16")
+	require.NoError(t, err)
+	_, ipNet6, err := net.ParseCIDR("fe80::/64")
+	require.NoError(t, err)
+
+	newServer := func() networkservice.NetworkServiceServer {
+		return next.NewNetworkServiceServer(
+			updatepath.NewServer("ipam"),
+			metadata.NewServer(),
+			point2pointipam.NewServerWithOptions([]*net.IPNet{ipNet, ipNet6},
+				point2pointipam.WithDualStack(),
+				point2pointipam.WithIdentity(point2pointipam.PodNameIdentity),
+				point2pointipam.WithReservationStore(store)),
+		)
+	}
+	srv := newServer()
+
+	conn, err := srv.Request(context.Background(), newPodRequest("pod-1"))
+	require.NoError(t, err)
+	validateConns(t, conn, []string{"192.168.0.0/32", "fe80::/128"}, []string{"192.168.0.1/32", "fe80::1/128"})
+
+	_, err = srv.Close(context.Background(), conn)
+	require.NoError(t, err)
+
+	reservations, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, reservations, 2)
+
+	// The restarted server restores the reservations of the both families
+	srv = newServer()
+
+	conn, err = srv.Request(context.Background(), newPodRequest("pod-2"))
+	require.NoError(t, err)
+	validateConns(t, conn, []string{"192.168.0.2/32", "fe80::2/128"}, []string{"192.168.0.3/32", "fe80::3/128"})
+
+	conn, err = srv.Request(context.Background(), newPodRequest("pod-1"))
+	require.NoError(t, err)
+	validateConns(t, conn, []string{"192.168.0.0/32", "fe80::/128"}, []string{"192.168.0.1/32", "fe80::1/128"})
+}
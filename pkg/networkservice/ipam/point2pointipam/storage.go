@@ -0,0 +1,171 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package point2pointipam
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/fs"
+)
+
+// Reservation is the src/dst addresses pair reserved for the client identity
+type Reservation struct {
+	NetworkService string `json:"networkService"`
+	Identity       string `json:"identity"`
+	SrcAddr        string `json:"srcAddr"`
+	DstAddr        string `json:"dstAddr"`
+	// ExpirationTime is the time when the released addresses return to the pool, it is zero while the client is connected
+	ExpirationTime time.Time `json:"expirationTime,omitempty"`
+}
+
+// ReservationStore persists the reservations, so the server can restore them after restart
+type ReservationStore interface {
+	// Load returns all the stored reservations
+	Load() ([]*Reservation, error)
+	// Store stores or replaces the reservation with the same network service, identity and IP family
+	Store(reservation *Reservation) error
+	// Delete deletes the reservation with the same network service, identity and addresses. The reservation of the
+	// same client with the other addresses is kept, so deleting the stale reservation never drops the newer one.
+	Delete(reservation *Reservation) error
+}
+
+func (r *Reservation) isIPv4() bool {
+	ip, _, err := net.ParseCIDR(r.SrcAddr)
+	return err == nil && ip.To4() != nil
+}
+
+const filePerm = 0o600
+
+type fileReservationStore struct {
+	path         string
+	reservations []*Reservation
+	loaded       bool
+	mu           sync.Mutex
+}
+
+// NewFileReservationStore returns ReservationStore keeping the reservations in the JSON file. The file is read once
+// and then kept in memory, so it must not be shared by several stores. Each Store and Delete rewrites the whole file,
+// it is fine for the thousands of reservations, but not for the larger pools with the high connection churn.
+func NewFileReservationStore(path string) ReservationStore {
+	return &fileReservationStore{
+		path: path,
+	}
+}
+
+func (s *fileReservationStore) Load() ([]*Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	reservations := make([]*Reservation, 0, len(s.reservations))
+	for _, r := range s.reservations {
+		reservation := *r
+		reservations = append(reservations, &reservation)
+	}
+	return reservations, nil
+}
+
+func (s *fileReservationStore) Store(reservation *Reservation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	stored := *reservation
+	return s.write(append(deleteReservation(s.reservations, reservation), &stored))
+}
+
+func (s *fileReservationStore) Delete(reservation *Reservation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	for _, r := range s.reservations {
+		if r.NetworkService == reservation.NetworkService && r.Identity == reservation.Identity &&
+			r.SrcAddr == reservation.SrcAddr && r.DstAddr == reservation.DstAddr {
+			return s.write(deleteReservation(s.reservations, reservation))
+		}
+	}
+	return nil
+}
+
+func (s *fileReservationStore) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", s.path)
+	}
+
+	if err := json.Unmarshal(data, &s.reservations); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal %s", s.path)
+	}
+	s.loaded = true
+	return nil
+}
+
+func (s *fileReservationStore) write(reservations []*Reservation) error {
+	sort.Slice(reservations, func(i, j int) bool {
+		if reservations[i].NetworkService != reservations[j].NetworkService {
+			return reservations[i].NetworkService < reservations[j].NetworkService
+		}
+		if reservations[i].Identity != reservations[j].Identity {
+			return reservations[i].Identity < reservations[j].Identity
+		}
+		return reservations[i].SrcAddr < reservations[j].SrcAddr
+	})
+	data, err := json.Marshal(reservations)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal reservations")
+	}
+	if err := fs.WriteFileAtomic(s.path, data, filePerm); err != nil {
+		return err
+	}
+	s.reservations = reservations
+	return nil
+}
+
+// deleteReservation returns the copy of the reservations without the one with the same network service, identity and
+// IP family, so the stored reservations stay untouched if the write fails
+func deleteReservation(reservations []*Reservation, reservation *Reservation) []*Reservation {
+	result := make([]*Reservation, 0, len(reservations)+1)
+	for _, r := range reservations {
+		if r.NetworkService == reservation.NetworkService && r.Identity == reservation.Identity && r.isIPv4() == reservation.isIPv4() {
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package singlepointipam
+
+// Option is an option pattern for NewServerWithOptions
+type Option func(sipam *singlePIpam)
+
+// WithDualStack enables the dual-stack mode: the connection gets the addresses of the both IP families of the prefixes
+// or fails. The families are limited by the address families of the extra prefix requests if any.
+func WithDualStack() Option {
+	return func(sipam *singlePIpam) {
+		sipam.dualStack = true
+	}
+}
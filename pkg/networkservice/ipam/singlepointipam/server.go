@@ -1,6 +1,6 @@
 // Copyright (c) 2020-2022 Nordix and its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -32,18 +32,22 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/dualstackipam"
 	"github.com/networkservicemesh/sdk/pkg/tools/cidr"
+	"github.com/networkservicemesh/sdk/pkg/tools/ipaminfo"
 	"github.com/networkservicemesh/sdk/pkg/tools/ippool"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
 )
 
 type singlePIpam struct {
 	genericsync.Map[string, *connectionInfo]
-	ipPools  []*ippool.IPPool
-	prefixes []*net.IPNet
-	myIPs    []string
-	masks    []string
-	once     sync.Once
-	initErr  error
+	ipPools   []*ippool.IPPool
+	prefixes  []*net.IPNet
+	myIPs     []string
+	masks     []string
+	once      sync.Once
+	initErr   error
+	dualStack bool
 }
 
 type connectionInfo struct {
@@ -60,11 +64,29 @@ func (i *connectionInfo) shouldUpdate(exclude *ippool.IPPool) bool {
 }
 
 // NewServer - creates a new NetworkServiceServer chain element that implements IPAM service.
+// The returned server implements ipaminfo.Introspector.
 func NewServer(prefixes ...*net.IPNet) networkservice.NetworkServiceServer {
-	return &singlePIpam{
+	return NewServerWithOptions(prefixes)
+}
+
+// NewServerWithOptions - creates a new NetworkServiceServer chain element that implements IPAM service with the
+// options. The returned server implements ipaminfo.Introspector.
+func NewServerWithOptions(prefixes []*net.IPNet, options ...Option) networkservice.NetworkServiceServer {
+	sipam := &singlePIpam{
 		prefixes: prefixes,
 	}
+	for _, opt := range options {
+		opt(sipam)
+	}
+	if sipam.dualStack {
+		familyOptions := append(append([]Option(nil), options...), func(sipam *singlePIpam) { sipam.dualStack = false })
+		return dualstackipam.NewServer(func(familyPrefixes ...*net.IPNet) networkservice.NetworkServiceServer {
+			return NewServerWithOptions(familyPrefixes, familyOptions...)
+		}, prefixes...)
+	}
+	return sipam
 }
+
 func (sipam *singlePIpam) init() {
 	if len(sipam.prefixes) == 0 {
 		sipam.initErr = errors.New("required one or more prefixes")
@@ -117,8 +139,11 @@ func (sipam *singlePIpam) Request(ctx context.Context, request *networkservice.N
 		loaded = false
 	}
 	var err error
+	// If not loaded, we are primarily trying to recover the address from the IpContext. In case of an error, allocate a new one.
 	if !loaded {
-		if connInfo, err = sipam.getAddrs(excludeIP4, excludeIP6); err != nil {
+		if connInfo, err = sipam.recoverAddrs(ipContext.GetSrcIpAddrs(), excludeIP4, excludeIP6); err == nil {
+			log.FromContext(ctx).Infof("address has been recovered - srcIP: %v", connInfo.srcAddr)
+		} else if connInfo, err = sipam.getAddrs(excludeIP4, excludeIP6); err != nil {
 			return nil, err
 		}
 		sipam.Store(conn.GetId(), connInfo)
@@ -144,7 +169,7 @@ func (sipam *singlePIpam) Close(
 		return nil, errors.Wrap(sipam.initErr, "failed to init IPAM server during close")
 	}
 
-	if connInfo, ok := sipam.Load(conn.GetId()); ok {
+	if connInfo, ok := sipam.LoadAndDelete(conn.GetId()); ok {
 		sipam.free(connInfo)
 	}
 	return next.Server(ctx).Close(ctx, conn)
@@ -177,6 +202,34 @@ func (sipam *singlePIpam) setMyIP(i int) error {
 	return nil
 }
 
+func (sipam *singlePIpam) recoverAddrs(srcAddrs []string, excludeIP4, excludeIP6 *ippool.IPPool) (*connectionInfo, error) {
+	if len(srcAddrs) == 0 {
+		return nil, errors.New("addresses cannot be empty for recovery")
+	}
+	for i, ipPool := range sipam.ipPools {
+		if i >= len(sipam.myIPs) {
+			if err := sipam.setMyIP(i); err != nil {
+				continue
+			}
+		}
+		myIP, _, _ := net.ParseCIDR(sipam.myIPs[i])
+		if excludeIP4.ContainsString(myIP.String()) || excludeIP6.ContainsString(myIP.String()) {
+			continue
+		}
+		for _, addr := range srcAddrs {
+			if srcAddr, err := ipPool.PullIPString(addr, excludeIP4, excludeIP6); err == nil {
+				return &connectionInfo{
+					ipPool:  ipPool,
+					srcAddr: srcAddr.IP.String() + sipam.masks[i],
+					dstAddr: sipam.myIPs[i],
+				}, nil
+			}
+		}
+	}
+
+	return nil, errors.Errorf("unable to recover: %+v", srcAddrs)
+}
+
 func (sipam *singlePIpam) getAddrs(excludeIP4, excludeIP6 *ippool.IPPool) (connInfo *connectionInfo, err error) {
 	var dstAddr, srcAddr net.IP
 
@@ -202,19 +255,31 @@ func (sipam *singlePIpam) getAddrs(excludeIP4, excludeIP6 *ippool.IPPool) (connI
 				break
 			}
 		}
+		if !dstSet {
+			continue
+		}
+		// The excluded addresses are returned to the pool, they can be requested by another connection
+		var excluded []net.IP
 		for {
 			if srcAddr, err = sipam.ipPools[i].Pull(); err != nil {
 				break
 			}
-			if !excludeIP4.ContainsString(srcAddr.String()) && !excludeIP6.ContainsString(srcAddr.String()) {
-				if dstSet {
-					return &connectionInfo{
-						ipPool:  sipam.ipPools[i],
-						srcAddr: srcAddr.String() + sipam.masks[i],
-						dstAddr: dstAddr.String() + sipam.masks[i],
-					}, nil
-				}
+			if excludeIP4.ContainsString(srcAddr.String()) || excludeIP6.ContainsString(srcAddr.String()) {
+				excluded = append(excluded, srcAddr)
+				continue
+			}
+			connInfo = &connectionInfo{
+				ipPool:  sipam.ipPools[i],
+				srcAddr: srcAddr.String() + sipam.masks[i],
+				dstAddr: dstAddr.String() + sipam.masks[i],
 			}
+			break
+		}
+		for _, ip := range excluded {
+			sipam.ipPools[i].Add(ip)
+		}
+		if connInfo != nil {
+			return connInfo, nil
 		}
 	}
 	return nil, err
@@ -250,3 +315,30 @@ func addAddr(addrs *[]string, addr string) {
 	}
 	*addrs = append(*addrs, addr)
 }
+
+// Allocations returns the addresses allocated to the connections
+func (sipam *singlePIpam) Allocations() []*ipaminfo.Allocation {
+	var allocations []*ipaminfo.Allocation
+	sipam.Range(func(id string, connInfo *connectionInfo) bool {
+		allocations = append(allocations, &ipaminfo.Allocation{
+			ConnectionID: id,
+			Addresses:    []string{connInfo.srcAddr},
+		})
+		return true
+	})
+	return ipaminfo.SortAllocations(allocations)
+}
+
+// Usage returns the usage of the prefixes
+func (sipam *singlePIpam) Usage() []*ipaminfo.PrefixUsage {
+	sipam.once.Do(sipam.init)
+	if sipam.initErr != nil {
+		return nil
+	}
+
+	var usage []*ipaminfo.PrefixUsage
+	for i, ipPool := range sipam.ipPools {
+		usage = append(usage, ipaminfo.NewPrefixUsage(sipam.prefixes[i], ipPool.AddressCount()))
+	}
+	return usage
+}
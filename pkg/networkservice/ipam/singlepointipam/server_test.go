@@ -1,6 +1,8 @@
 // Copyright (c) 2020-2021 Doc.ai and/or its affiliates.
 // Copyright (c) 2020-2023 Nordix and its affiliates.
 //
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
 // SPDX-License-Identifier: Apache-2.0
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -32,6 +34,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/ipam/singlepointipam"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/ipaminfo"
 )
 
 func newIpamServer(prefixes ...*net.IPNet) networkservice.NetworkServiceServer {
@@ -430,3 +433,113 @@ func TestRefreshRequestMultiServer(t *testing.T) {
 	require.NoError(t, err)
 	validateConns(t, conn, []string{"192.168.0.5/16", "fe80::5/64"})
 }
+
+func TestRecoveryServer(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("192.168.3.4/16")
+	require.NoError(t, err)
+
+	srv := newIpamServer(ipNet)
+
+	req := newRequest()
+	req.Connection.Context.IpContext.SrcIpAddrs = []string{"192.168.0.5/16"}
+
+	conn1, err := srv.Request(context.Background(), req)
+	require.NoError(t, err)
+	validateConn(t, conn1, "192.168.0.5/16")
+
+	conn2, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+	validateConn(t, conn2, "192.168.0.1/16")
+}
+
+func TestExcludedAddressesNotLost(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("192.168.3.4/16")
+	require.NoError(t, err)
+
+	srv := newIpamServer(ipNet)
+
+	req := newRequest()
+	req.Connection.Context.IpContext.ExcludedPrefixes = []string{"192.168.0.1/32"}
+
+	conn1, err := srv.Request(context.Background(), req)
+	require.NoError(t, err)
+	validateConn(t, conn1, "192.168.0.2/16")
+
+	// The address excluded by the first client is handed out to the next one
+	conn2, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+	validateConn(t, conn2, "192.168.0.1/16")
+}
+
+func TestIntrospection(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("192.168.3.4/29")
+	require.NoError(t, err)
+
+	ipamServer := singlepointipam.NewServer(ipNet)
+	introspector, ok := ipamServer.(ipaminfo.Introspector)
+	require.True(t, ok)
+
+	srv := next.NewNetworkServiceServer(
+		updatepath.NewServer("ipam"),
+		metadata.NewServer(),
+		ipamServer,
+	)
+
+	conn1, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+	conn2, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+
+	allocations := introspector.Allocations()
+	require.Len(t, allocations, 2)
+	for _, allocation := range allocations {
+		conn := conn1
+		if allocation.ConnectionID == conn2.GetId() {
+			conn = conn2
+		}
+		require.Equal(t, conn.GetId(), allocation.ConnectionID)
+		require.Equal(t, conn.GetContext().GetIpContext().GetSrcIpAddrs(), allocation.Addresses)
+	}
+
+	// 8 addresses: the broadcast address, the NSE address and 2 connection addresses are allocated
+	usage := introspector.Usage()
+	require.Len(t, usage, 1)
+	require.Equal(t, "192.168.3.0/29", usage[0].Prefix)
+	require.Equal(t, int64(8), usage[0].Total.Int64())
+	require.Equal(t, int64(4), usage[0].Free.Int64())
+
+	_, err = srv.Close(context.Background(), conn1)
+	require.NoError(t, err)
+
+	allocations = introspector.Allocations()
+	require.Len(t, allocations, 1)
+	require.Equal(t, conn2.GetId(), allocations[0].ConnectionID)
+	require.Equal(t, int64(5), introspector.Usage()[0].Free.Int64())
+}
+
+func TestCloseForgetsConnection(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("192.168.3.4/16")
+	require.NoError(t, err)
+
+	srv := newIpamServer(ipNet)
+
+	req := newRequest()
+	req.Connection.Id = "id-1"
+
+	conn1, err := srv.Request(context.Background(), req.Clone())
+	require.NoError(t, err)
+	validateConn(t, conn1, "192.168.0.1/16")
+
+	_, err = srv.Close(context.Background(), conn1)
+	require.NoError(t, err)
+
+	// The closed connection address is returned to the pool and allocated to another connection
+	conn2, err := srv.Request(context.Background(), newRequest())
+	require.NoError(t, err)
+	validateConn(t, conn2, "192.168.0.1/16")
+
+	// The new connection with the same ID must not get the address of the closed one
+	conn1, err = srv.Request(context.Background(), req.Clone())
+	require.NoError(t, err)
+	validateConn(t, conn1, "192.168.0.2/16")
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/registry/common/dial"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/heal"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/healthgate"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/null"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/refresh"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/retry"
@@ -50,6 +51,11 @@ func NewNetworkServiceEndpointRegistryClient(ctx context.Context, opts ...Option
 		opt(clientOpts)
 	}
 
+	healthGateClient := null.NewNetworkServiceEndpointRegistryClient()
+	if clientOpts.nseHealthGate {
+		healthGateClient = healthgate.NewNetworkServiceEndpointRegistryClient(ctx, clientOpts.nseHealthGateOptions...)
+	}
+
 	return chain.NewNetworkServiceEndpointRegistryClient(
 		append(
 			[]registry.NetworkServiceEndpointRegistryClient{
@@ -57,7 +63,8 @@ func NewNetworkServiceEndpointRegistryClient(ctx context.Context, opts ...Option
 				metadata.NewNetworkServiceEndpointClient(),
 				retry.NewNetworkServiceEndpointRegistryClient(ctx),
 				heal.NewNetworkServiceEndpointRegistryClient(ctx),
-				refresh.NewNetworkServiceEndpointRegistryClient(ctx),
+				healthGateClient,
+				refresh.NewNetworkServiceEndpointRegistryClient(ctx, clientOpts.nseRefreshOptions...),
 				clientOpts.authorizeNSERegistryClient,
 				clientOpts.nseClientURLResolver,
 				clientconn.NewNetworkServiceEndpointRegistryClient(),
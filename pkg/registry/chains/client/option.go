@@ -27,6 +27,8 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/registry"
 
 	"github.com/networkservicemesh/sdk/pkg/registry/common/clienturl"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/healthgate"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/refresh"
 )
 
 // Option is an option pattern for NewNetworkServiceRegistryClient, NewNetworkServiceEndpointRegistryClient
@@ -88,6 +90,21 @@ func WithNSEAdditionalFunctionality(additionalFunctionality ...registry.NetworkS
 	}
 }
 
+// WithNSERefreshOptions sets options for the NSE registration refresh
+func WithNSERefreshOptions(refreshOptions ...refresh.Option) Option {
+	return func(clientOpts *clientOptions) {
+		clientOpts.nseRefreshOptions = refreshOptions
+	}
+}
+
+// WithNSEHealthGate enables unregistering the NSE while it is unhealthy, see healthgate.NewNetworkServiceEndpointRegistryClient
+func WithNSEHealthGate(healthGateOptions ...healthgate.Option) Option {
+	return func(clientOpts *clientOptions) {
+		clientOpts.nseHealthGate = true
+		clientOpts.nseHealthGateOptions = healthGateOptions
+	}
+}
+
 // WithDialOptions sets dial options
 func WithDialOptions(dialOptions ...grpc.DialOption) Option {
 	return func(clientOpts *clientOptions) {
@@ -109,6 +126,9 @@ type clientOptions struct {
 	authorizeNSERegistryClient registry.NetworkServiceEndpointRegistryClient
 	nsAdditionalFunctionality  []registry.NetworkServiceRegistryClient
 	nseAdditionalFunctionality []registry.NetworkServiceEndpointRegistryClient
+	nseRefreshOptions          []refresh.Option
+	nseHealthGate              bool
+	nseHealthGateOptions       []healthgate.Option
 	dialOptions                []grpc.DialOption
 	dialTimeout                time.Duration
 }
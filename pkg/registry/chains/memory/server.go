@@ -1,6 +1,6 @@
 // Copyright (c) 2020-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -29,6 +29,7 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/registry"
 
 	registryserver "github.com/networkservicemesh/sdk/pkg/registry"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/admission"
 	registryauthorize "github.com/networkservicemesh/sdk/pkg/registry/common/authorize"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/updatepath"
@@ -40,8 +41,12 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/registry/common/dial"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/expire"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/quota"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/replicate"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/setpayload"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/setregistrationtime"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/validatematches"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
 	"github.com/networkservicemesh/sdk/pkg/registry/core/chain"
 	"github.com/networkservicemesh/sdk/pkg/registry/switchcase"
 	"github.com/networkservicemesh/sdk/pkg/registry/utils/metadata"
@@ -57,6 +62,12 @@ type serverOptions struct {
 	defaultExpiration          time.Duration
 	proxyRegistryURL           *url.URL
 	dialOptions                []grpc.DialOption
+	nseStorage                 memory.Storage[*registry.NetworkServiceEndpoint]
+	nsStorage                  memory.Storage[*registry.NetworkService]
+	peerRegistryURLs           []*url.URL
+	replicateOptions           []replicate.Option
+	admissionOptions           []admission.Option
+	quotaOptions               []quota.Option
 }
 
 // Option modifies server option value
@@ -123,6 +134,49 @@ func WithDialOptions(dialOptions ...grpc.DialOption) Option {
 	}
 }
 
+// WithNSEStorage sets the storage persisting NSEs between the registry restarts
+func WithNSEStorage(storage memory.Storage[*registry.NetworkServiceEndpoint]) Option {
+	return func(o *serverOptions) {
+		o.nseStorage = storage
+	}
+}
+
+// WithNSStorage sets the storage persisting NSs between the registry restarts
+func WithNSStorage(storage memory.Storage[*registry.NetworkService]) Option {
+	return func(o *serverOptions) {
+		o.nsStorage = storage
+	}
+}
+
+// WithPeerRegistryURLs sets URLs of the peer registries to replicate NSEs and NSs with. The requests replicated by the
+// peer registries are accepted only from the spiffe IDs set with WithReplicateOptions(replicate.WithPeerSpiffeIDs(...)).
+func WithPeerRegistryURLs(peerRegistryURLs ...*url.URL) Option {
+	return func(o *serverOptions) {
+		o.peerRegistryURLs = peerRegistryURLs
+	}
+}
+
+// WithReplicateOptions sets additional options for the replication to the peer registries
+func WithReplicateOptions(replicateOptions ...replicate.Option) Option {
+	return func(o *serverOptions) {
+		o.replicateOptions = replicateOptions
+	}
+}
+
+// WithAdmissionOptions sets admission hooks for NSE and NS registrations
+func WithAdmissionOptions(admissionOptions ...admission.Option) Option {
+	return func(o *serverOptions) {
+		o.admissionOptions = admissionOptions
+	}
+}
+
+// WithQuotaOptions sets quotas for NSE and NS registrations. The NSEs and NSs restored from the storage take quota too.
+func WithQuotaOptions(quotaOptions ...quota.Option) Option {
+	return func(o *serverOptions) {
+		o.quotaOptions = quotaOptions
+	}
+}
+
 // NewServer creates new registry server based on memory storage
 func NewServer(ctx context.Context, tokenGenerator token.GeneratorFunc, options ...Option) registryserver.Registry {
 	opts := &serverOptions{
@@ -137,12 +191,30 @@ func NewServer(ctx context.Context, tokenGenerator token.GeneratorFunc, options
 		opt(opts)
 	}
 
+	nseMemory := memory.NewNetworkServiceEndpointRegistryServer(memory.WithContext(ctx), memory.WithNetworkServiceEndpointStorage(ctx, opts.nseStorage))
+	nsMemory := memory.NewNetworkServiceRegistryServer(memory.WithContext(ctx), memory.WithNetworkServiceStorage(ctx, opts.nsStorage))
+
+	nseMemoryClient := adapters.NetworkServiceEndpointServerToClient(nseMemory)
+	nsMemoryClient := adapters.NetworkServiceServerToClient(nsMemory)
+
+	replicateOptions := append([]replicate.Option{
+		replicate.WithPeerURLs(opts.peerRegistryURLs...),
+		replicate.WithDialOptions(opts.dialOptions...),
+		replicate.WithLocalNetworkServiceEndpointRegistryClient(nseMemoryClient),
+		replicate.WithLocalNetworkServiceRegistryClient(nsMemoryClient),
+	}, opts.replicateOptions...)
+	quotaOptions := append([]quota.Option{
+		quota.WithLocalNetworkServiceEndpointRegistryClient(ctx, nseMemoryClient),
+		quota.WithLocalNetworkServiceRegistryClient(ctx, nsMemoryClient),
+	}, opts.quotaOptions...)
+
 	nseChain := chain.NewNetworkServiceEndpointRegistryServer(
 		grpcmetadata.NewNetworkServiceEndpointRegistryServer(),
 		updatepath.NewNetworkServiceEndpointRegistryServer(tokenGenerator),
 		opts.authorizeNSERegistryServer,
 		begin.NewNetworkServiceEndpointRegistryServer(),
 		metadata.NewNetworkServiceEndpointServer(),
+		admission.NewNetworkServiceEndpointRegistryServer(opts.admissionOptions...),
 		switchcase.NewNetworkServiceEndpointRegistryServer(switchcase.NSEServerCase{
 			Condition: func(c context.Context, nse *registry.NetworkServiceEndpoint) bool {
 				if interdomain.Is(nse.GetName()) {
@@ -174,9 +246,11 @@ func NewServer(ctx context.Context, tokenGenerator token.GeneratorFunc, options
 			switchcase.NSEServerCase{
 				Condition: func(c context.Context, nse *registry.NetworkServiceEndpoint) bool { return true },
 				Action: chain.NewNetworkServiceEndpointRegistryServer(
+					replicate.NewNetworkServiceEndpointRegistryServer(ctx, replicateOptions...),
 					setregistrationtime.NewNetworkServiceEndpointRegistryServer(),
 					expire.NewNetworkServiceEndpointRegistryServer(ctx, expire.WithDefaultExpiration(opts.defaultExpiration)),
-					memory.NewNetworkServiceEndpointRegistryServer(),
+					quota.NewNetworkServiceEndpointRegistryServer(quotaOptions...),
+					nseMemory,
 				),
 			},
 		),
@@ -187,6 +261,8 @@ func NewServer(ctx context.Context, tokenGenerator token.GeneratorFunc, options
 		opts.authorizeNSRegistryServer,
 		metadata.NewNetworkServiceServer(),
 		setpayload.NewNetworkServiceRegistryServer(),
+		validatematches.NewNetworkServiceRegistryServer(),
+		admission.NewNetworkServiceRegistryServer(opts.admissionOptions...),
 		switchcase.NewNetworkServiceRegistryServer(
 			switchcase.NSServerCase{
 				Condition: func(c context.Context, ns *registry.NetworkService) bool {
@@ -210,7 +286,11 @@ func NewServer(ctx context.Context, tokenGenerator token.GeneratorFunc, options
 				Condition: func(c context.Context, ns *registry.NetworkService) bool {
 					return true
 				},
-				Action: memory.NewNetworkServiceRegistryServer(),
+				Action: chain.NewNetworkServiceRegistryServer(
+					replicate.NewNetworkServiceRegistryServer(ctx, replicateOptions...),
+					quota.NewNetworkServiceRegistryServer(quotaOptions...),
+					nsMemory,
+				),
 			},
 		),
 	)
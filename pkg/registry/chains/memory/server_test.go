@@ -1,6 +1,6 @@
 // Copyright (c) 2021 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2022 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -27,13 +27,18 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice/payload"
 	"github.com/networkservicemesh/api/pkg/api/registry"
 
 	registryclient "github.com/networkservicemesh/sdk/pkg/registry/chains/client"
-
+	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
 	"github.com/networkservicemesh/sdk/pkg/tools/sandbox"
 )
 
@@ -68,3 +73,206 @@ func Test_RegistryMemory_ShouldSetDefaultPayload(t *testing.T) {
 
 	require.NoError(t, ctx.Err())
 }
+
+func Test_RegistryMemory_ShouldReplicateBetweenReplicas(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	domain := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(0).
+		SetRegistryProxySupplier(nil).
+		SetNSMgrProxySupplier(nil).
+		SetRegistryReplicasCount(2).
+		Build()
+	require.Len(t, domain.RegistryReplicas, 2)
+
+	nsrc := registryclient.NewNetworkServiceRegistryClient(ctx,
+		registryclient.WithDialOptions(sandbox.DialOptions()...),
+		registryclient.WithClientURL(domain.RegistryReplicas[1].URL))
+	_, err := nsrc.Register(ctx, &registry.NetworkService{Name: "ns-1"})
+	require.NoError(t, err)
+
+	nserc := registryclient.NewNetworkServiceEndpointRegistryClient(ctx,
+		registryclient.WithDialOptions(sandbox.DialOptions()...),
+		registryclient.WithClientURL(domain.RegistryReplicas[0].URL))
+	nse, err := nserc.Register(ctx, &registry.NetworkServiceEndpoint{
+		Name:                "nse-1",
+		NetworkServiceNames: []string{"ns-1"},
+		Url:                 "tcp://1.1.1.1",
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(findNSs(ctx, domain.RegistryReplicas[0])) == 1
+	}, time.Second, time.Millisecond*10)
+	require.Eventually(t, func() bool {
+		nses := findNSEs(ctx, domain.RegistryReplicas[1])
+		return len(nses) == 1 && nses[0].GetUrl() == "tcp://1.1.1.1"
+	}, time.Second, time.Millisecond*10)
+
+	_, err = nserc.Unregister(ctx, nse)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(findNSEs(ctx, domain.RegistryReplicas[1])) == 0
+	}, time.Second, time.Millisecond*10)
+}
+
+func Test_RegistryMemory_ShouldResyncLateJoiningReplica(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	domain := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(0).
+		SetRegistryProxySupplier(nil).
+		SetNSMgrProxySupplier(nil).
+		SetRegistryReplicasCount(2).
+		Build()
+
+	// 1. The second replica is not started yet
+	domain.RegistryReplicas[1].Cancel()
+
+	_, err := nseClient(ctx, t, domain.RegistryReplicas[0]).Register(ctx, &registry.NetworkServiceEndpoint{
+		Name: "nse-1",
+		Url:  "tcp://1.1.1.1",
+	})
+	require.NoError(t, err)
+
+	nsrc := registryclient.NewNetworkServiceRegistryClient(ctx,
+		registryclient.WithDialOptions(sandbox.DialOptions()...),
+		registryclient.WithClientURL(domain.RegistryReplicas[0].URL))
+	_, err = nsrc.Register(ctx, &registry.NetworkService{Name: "ns-1"})
+	require.NoError(t, err)
+
+	// 2. The second replica joins late and gets the NSE and NS registered before
+	domain.RegistryReplicas[1].Restart()
+
+	require.Eventually(t, func() bool {
+		return len(findNSEs(ctx, domain.RegistryReplicas[1])) == 1 && len(findNSs(ctx, domain.RegistryReplicas[1])) == 1
+	}, time.Second*4, time.Millisecond*10)
+
+	// 3. The restarted replica loses its state and gets it back on the resync
+	domain.RegistryReplicas[1].Restart()
+
+	require.Eventually(t, func() bool {
+		nses := findNSEs(ctx, domain.RegistryReplicas[1])
+		return len(nses) == 1 && nses[0].GetUrl() == "tcp://1.1.1.1" && len(findNSs(ctx, domain.RegistryReplicas[1])) == 1
+	}, time.Second*4, time.Millisecond*10)
+}
+
+func Test_RegistryMemory_ReplicationConflicts(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	domain := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(0).
+		SetRegistryProxySupplier(nil).
+		SetNSMgrProxySupplier(nil).
+		SetRegistryReplicasCount(2).
+		Build()
+
+	registrationTime := time.Now()
+
+	// 1. Register NSE on the second replica
+	_, err := nseClient(ctx, t, domain.RegistryReplicas[1]).Register(ctx, &registry.NetworkServiceEndpoint{
+		Name:                    "nse-1",
+		Url:                     "tcp://2.2.2.2",
+		InitialRegistrationTime: timestamppb.New(registrationTime),
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(findNSEs(ctx, domain.RegistryReplicas[0])) == 1
+	}, time.Second, time.Millisecond*10)
+
+	// 2. Replicated registration from not a peer registry should be rejected
+	replicaCtx := grpcmetadata.PathWithContext(ctx, &grpcmetadata.Path{
+		PathSegments: []*grpcmetadata.PathSegment{{ReplicatedBy: "peer"}},
+	})
+	_, err = nseClient(ctx, t, domain.RegistryReplicas[0]).Register(replicaCtx, &registry.NetworkServiceEndpoint{
+		Name:                    "nse-1",
+		Url:                     "tcp://1.1.1.1",
+		InitialRegistrationTime: timestamppb.New(registrationTime.Add(time.Second)),
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	nses := findNSEs(ctx, domain.RegistryReplicas[0])
+	require.Len(t, nses, 1)
+	require.Equal(t, "tcp://2.2.2.2", nses[0].GetUrl())
+
+	// 3. Replicated older registration should be ignored
+	replicaCtx = grpcmetadata.PathWithContext(ctx, &grpcmetadata.Path{
+		PathSegments: []*grpcmetadata.PathSegment{{ReplicatedBy: "peer"}},
+	})
+	_, err = nseClient(ctx, t, domain.RegistryReplicas[0], sandbox.WithRegistryReplicaIdentity()).Register(replicaCtx, &registry.NetworkServiceEndpoint{
+		Name:                    "nse-1",
+		Url:                     "tcp://1.1.1.1",
+		InitialRegistrationTime: timestamppb.New(registrationTime.Add(-time.Second)),
+	})
+	require.NoError(t, err)
+
+	nses = findNSEs(ctx, domain.RegistryReplicas[0])
+	require.Len(t, nses, 1)
+	require.Equal(t, "tcp://2.2.2.2", nses[0].GetUrl())
+
+	// 4. Replicated registration should not be replicated again
+	replicaCtx = grpcmetadata.PathWithContext(ctx, &grpcmetadata.Path{
+		PathSegments: []*grpcmetadata.PathSegment{{ReplicatedBy: "peer"}},
+	})
+	_, err = nseClient(ctx, t, domain.RegistryReplicas[0], sandbox.WithRegistryReplicaIdentity()).Register(replicaCtx, &registry.NetworkServiceEndpoint{
+		Name: "nse-2",
+		Url:  "tcp://3.3.3.3",
+	})
+	require.NoError(t, err)
+	require.Len(t, findNSEs(ctx, domain.RegistryReplicas[0]), 2)
+	require.Never(t, func() bool {
+		return len(findNSEs(ctx, domain.RegistryReplicas[1])) != 1
+	}, time.Millisecond*200, time.Millisecond*20)
+}
+
+func nseClient(ctx context.Context, t *testing.T, entry *sandbox.RegistryEntry, dialOptions ...grpc.DialOption) registry.NetworkServiceEndpointRegistryClient {
+	cc, err := grpc.DialContext(ctx, grpcutils.URLToTarget(entry.URL), append(sandbox.DialOptions(), dialOptions...)...)
+	require.NoError(t, err)
+	go func() {
+		<-ctx.Done()
+		_ = cc.Close()
+	}()
+
+	return chain.NewNetworkServiceEndpointRegistryClient(
+		grpcmetadata.NewNetworkServiceEndpointRegistryClient(),
+		registry.NewNetworkServiceEndpointRegistryClient(cc),
+	)
+}
+
+func findNSEs(ctx context.Context, entry *sandbox.RegistryEntry) []*registry.NetworkServiceEndpoint {
+	c := registryclient.NewNetworkServiceEndpointRegistryClient(ctx,
+		registryclient.WithDialOptions(sandbox.DialOptions()...),
+		registryclient.WithClientURL(entry.URL))
+
+	stream, err := c.Find(ctx, &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+	})
+	if err != nil {
+		return nil
+	}
+	return registry.ReadNetworkServiceEndpointList(stream)
+}
+
+func findNSs(ctx context.Context, entry *sandbox.RegistryEntry) []*registry.NetworkService {
+	c := registryclient.NewNetworkServiceRegistryClient(ctx,
+		registryclient.WithDialOptions(sandbox.DialOptions()...),
+		registryclient.WithClientURL(entry.URL))
+
+	stream, err := c.Find(ctx, &registry.NetworkServiceQuery{
+		NetworkService: new(registry.NetworkService),
+	})
+	if err != nil {
+		return nil
+	}
+	return registry.ReadNetworkServiceList(stream)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admission provides registry server chain elements running admission hooks on the NSE and NS registrations.
+// Mutating hooks run first and may modify the registered entity, validating hooks run after them on the mutated
+// entity. Any hook returning an error rejects the registration: gRPC status errors are returned as is, other errors
+// are returned with InvalidArgument code.
+package admission
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NSEHook is an admission hook for the NSE registrations. Mutating hooks may modify the NSE, validating hooks get
+// a copy of it.
+type NSEHook func(ctx context.Context, nse *registry.NetworkServiceEndpoint) error
+
+// NSHook is an admission hook for the NS registrations. Mutating hooks may modify the NS, validating hooks get
+// a copy of it.
+type NSHook func(ctx context.Context, ns *registry.NetworkService) error
+
+type options struct {
+	nseMutators   []NSEHook
+	nseValidators []NSEHook
+	nsMutators    []NSHook
+	nsValidators  []NSHook
+}
+
+// Option is an option pattern for admission chain elements
+type Option func(*options)
+
+// WithNSEMutators appends mutating hooks for the NSE registrations
+func WithNSEMutators(hooks ...NSEHook) Option {
+	return func(o *options) {
+		o.nseMutators = append(o.nseMutators, hooks...)
+	}
+}
+
+// WithNSEValidators appends validating hooks for the NSE registrations
+func WithNSEValidators(hooks ...NSEHook) Option {
+	return func(o *options) {
+		o.nseValidators = append(o.nseValidators, hooks...)
+	}
+}
+
+// WithNSMutators appends mutating hooks for the NS registrations
+func WithNSMutators(hooks ...NSHook) Option {
+	return func(o *options) {
+		o.nsMutators = append(o.nsMutators, hooks...)
+	}
+}
+
+// WithNSValidators appends validating hooks for the NS registrations
+func WithNSValidators(hooks ...NSHook) Option {
+	return func(o *options) {
+		o.nsValidators = append(o.nsValidators, hooks...)
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func denied(kind, name string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Errorf(codes.InvalidArgument, "%s %s is denied by admission hook: %s", kind, name, err.Error())
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/interdomain"
+)
+
+// NewGRPCNSEHook returns an NSE hook delegating the admission to the hook service implementing the
+// NetworkServiceEndpointRegistry API: the service gets the NSE with Register and either rejects it with an error or
+// returns the admitted, possibly modified, NSE. If the hook service is unavailable, the registration is rejected.
+func NewGRPCNSEHook(client registry.NetworkServiceEndpointRegistryClient) NSEHook {
+	return func(ctx context.Context, nse *registry.NetworkServiceEndpoint) error {
+		resp, err := client.Register(ctx, nse.Clone())
+		if err != nil {
+			return err
+		}
+		proto.Reset(nse)
+		proto.Merge(nse, resp)
+		return nil
+	}
+}
+
+// NewGRPCNSHook returns an NS hook delegating the admission to the hook service implementing the
+// NetworkServiceRegistry API: the service gets the NS with Register and either rejects it with an error or returns
+// the admitted, possibly modified, NS. If the hook service is unavailable, the registration is rejected.
+func NewGRPCNSHook(client registry.NetworkServiceRegistryClient) NSHook {
+	return func(ctx context.Context, ns *registry.NetworkService) error {
+		resp, err := client.Register(ctx, ns.Clone())
+		if err != nil {
+			return err
+		}
+		proto.Reset(ns)
+		proto.Merge(ns, resp)
+		return nil
+	}
+}
+
+// MatchNSEName returns an NSE hook rejecting NSEs with names not matching the pattern
+func MatchNSEName(pattern *regexp.Regexp) NSEHook {
+	return func(_ context.Context, nse *registry.NetworkServiceEndpoint) error {
+		if !pattern.MatchString(nse.GetName()) {
+			return errors.Errorf("name doesn't match %s", pattern.String())
+		}
+		return nil
+	}
+}
+
+// MatchNSName returns an NS hook rejecting NSs with names not matching the pattern
+func MatchNSName(pattern *regexp.Regexp) NSHook {
+	return func(_ context.Context, ns *registry.NetworkService) error {
+		if !pattern.MatchString(ns.GetName()) {
+			return errors.Errorf("name doesn't match %s", pattern.String())
+		}
+		return nil
+	}
+}
+
+// RequireNSELabels returns an NSE hook rejecting NSEs missing any of the labels for any of their network services
+func RequireNSELabels(keys ...string) NSEHook {
+	return func(_ context.Context, nse *registry.NetworkServiceEndpoint) error {
+		for _, ns := range nse.GetNetworkServiceNames() {
+			labels := nse.GetNetworkServiceLabels()[ns].GetLabels()
+			for _, key := range keys {
+				if _, ok := labels[key]; !ok {
+					return errors.Errorf("label %s is missing for network service %s", key, ns)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// AllowNSEURLs returns an NSE hook rejecting NSEs with URLs not starting with any of the prefixes
+func AllowNSEURLs(prefixes ...string) NSEHook {
+	return func(_ context.Context, nse *registry.NetworkServiceEndpoint) error {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(nse.GetUrl(), prefix) {
+				return nil
+			}
+		}
+		return errors.Errorf("url %s is not allowed", nse.GetUrl())
+	}
+}
+
+// NetworkServicesExist returns an NSE hook rejecting NSEs providing network services not registered in the NS
+// registry. Interdomain network services are not checked.
+func NetworkServicesExist(client registry.NetworkServiceRegistryClient) NSEHook {
+	return func(ctx context.Context, nse *registry.NetworkServiceEndpoint) error {
+		for _, ns := range nse.GetNetworkServiceNames() {
+			if interdomain.Is(ns) {
+				continue
+			}
+			stream, err := client.Find(ctx, &registry.NetworkServiceQuery{
+				NetworkService: &registry.NetworkService{Name: ns},
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to find network service %s", ns)
+			}
+			if len(registry.ReadNetworkServiceList(stream)) == 0 {
+				return errors.Errorf("network service %s is not registered", ns)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+)
+
+type admissionNSServer struct {
+	mutators   []NSHook
+	validators []NSHook
+}
+
+// NewNetworkServiceRegistryServer creates a new NetworkServiceRegistryServer chain element running the NS admission
+// hooks before passing the registration to the next chain elements
+func NewNetworkServiceRegistryServer(opts ...Option) registry.NetworkServiceRegistryServer {
+	o := newOptions(opts...)
+	return &admissionNSServer{
+		mutators:   o.nsMutators,
+		validators: o.nsValidators,
+	}
+}
+
+func (s *admissionNSServer) Register(ctx context.Context, ns *registry.NetworkService) (*registry.NetworkService, error) {
+	for _, hook := range s.mutators {
+		if err := hook(ctx, ns); err != nil {
+			return nil, denied("network service", ns.GetName(), err)
+		}
+	}
+	for _, hook := range s.validators {
+		if err := hook(ctx, ns.Clone()); err != nil {
+			return nil, denied("network service", ns.GetName(), err)
+		}
+	}
+	return next.NetworkServiceRegistryServer(ctx).Register(ctx, ns)
+}
+
+func (s *admissionNSServer) Find(query *registry.NetworkServiceQuery, server registry.NetworkServiceRegistry_FindServer) error {
+	return next.NetworkServiceRegistryServer(server.Context()).Find(query, server)
+}
+
+func (s *admissionNSServer) Unregister(ctx context.Context, ns *registry.NetworkService) (*empty.Empty, error) {
+	return next.NetworkServiceRegistryServer(ctx).Unregister(ctx, ns)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/admission"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+)
+
+func TestAdmissionNSServer_MutateAndValidate(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := admission.NewNetworkServiceRegistryServer(
+		admission.WithNSMutators(func(_ context.Context, ns *registry.NetworkService) error {
+			if ns.GetPayload() == "" {
+				ns.Payload = "IP"
+			}
+			return nil
+		}),
+		admission.WithNSValidators(admission.MatchNSName(regexp.MustCompile("^[a-z0-9-]+$"))),
+	)
+
+	ns, err := s.Register(context.Background(), &registry.NetworkService{Name: "ns-1"})
+	require.NoError(t, err)
+	require.Equal(t, "IP", ns.GetPayload())
+
+	_, err = s.Register(context.Background(), &registry.NetworkService{Name: "NS_1"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAdmissionNSServer_GRPCHook(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	hook := admission.NewGRPCNSHook(adapters.NetworkServiceServerToClient(next.NewNetworkServiceRegistryServer(
+		admission.NewNetworkServiceRegistryServer(
+			admission.WithNSValidators(admission.MatchNSName(regexp.MustCompile("^ns-"))),
+		),
+	)))
+	s := admission.NewNetworkServiceRegistryServer(admission.WithNSValidators(hook))
+
+	_, err := s.Register(context.Background(), &registry.NetworkService{Name: "ns-1"})
+	require.NoError(t, err)
+
+	_, err = s.Register(context.Background(), &registry.NetworkService{Name: "typo-1"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
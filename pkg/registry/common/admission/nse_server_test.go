@@ -0,0 +1,195 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission_test
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/admission"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+)
+
+func TestAdmissionNSEServer_MutateAndValidate(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceEndpointRegistryServer(
+		admission.NewNetworkServiceEndpointRegistryServer(
+			admission.WithNSEMutators(func(_ context.Context, nse *registry.NetworkServiceEndpoint) error {
+				nse.Url = "tcp://" + nse.GetUrl()
+				return nil
+			}),
+			admission.WithNSEValidators(
+				admission.MatchNSEName(regexp.MustCompile("^nse-")),
+				admission.AllowNSEURLs("tcp://"),
+				func(_ context.Context, nse *registry.NetworkServiceEndpoint) error {
+					nse.Name = "changed"
+					return nil
+				},
+			),
+		),
+		memory.NewNetworkServiceEndpointRegistryServer(),
+	)
+
+	nse, err := s.Register(context.Background(), &registry.NetworkServiceEndpoint{Name: "nse-1", Url: "1.1.1.1:5000"})
+	require.NoError(t, err)
+	require.Equal(t, "nse-1", nse.GetName())
+	require.Equal(t, "tcp://1.1.1.1:5000", nse.GetUrl())
+
+	_, err = s.Register(context.Background(), &registry.NetworkServiceEndpoint{Name: "typo-nse", Url: "1.1.1.1:5000"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAdmissionNSEServer_StatusError(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := admission.NewNetworkServiceEndpointRegistryServer(
+		admission.WithNSEValidators(func(context.Context, *registry.NetworkServiceEndpoint) error {
+			return status.Error(codes.PermissionDenied, "denied")
+		}),
+	)
+
+	_, err := s.Register(context.Background(), &registry.NetworkServiceEndpoint{Name: "nse-1"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAdmissionNSEServer_RequireNSELabels(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := admission.NewNetworkServiceEndpointRegistryServer(
+		admission.WithNSEValidators(admission.RequireNSELabels("app")),
+	)
+
+	_, err := s.Register(context.Background(), &registry.NetworkServiceEndpoint{
+		Name:                "nse-1",
+		NetworkServiceNames: []string{"ns-1"},
+		NetworkServiceLabels: map[string]*registry.NetworkServiceLabels{
+			"ns-1": {Labels: map[string]string{"app": "firewall"}},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = s.Register(context.Background(), &registry.NetworkServiceEndpoint{
+		Name:                "nse-2",
+		NetworkServiceNames: []string{"ns-1", "ns-2"},
+		NetworkServiceLabels: map[string]*registry.NetworkServiceLabels{
+			"ns-1": {Labels: map[string]string{"app": "firewall"}},
+		},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAdmissionNSEServer_NetworkServicesExist(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nsServer := memory.NewNetworkServiceRegistryServer()
+	_, err := nsServer.Register(ctx, &registry.NetworkService{Name: "ns-1"})
+	require.NoError(t, err)
+
+	s := admission.NewNetworkServiceEndpointRegistryServer(
+		admission.WithNSEValidators(admission.NetworkServicesExist(adapters.NetworkServiceServerToClient(nsServer))),
+	)
+
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{
+		Name:                "nse-1",
+		NetworkServiceNames: []string{"ns-1", "ns-1@remote.domain"},
+	})
+	require.NoError(t, err)
+
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{
+		Name:                "nse-2",
+		NetworkServiceNames: []string{"ns-l"},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+type hookNSEServer struct{}
+
+func (s *hookNSEServer) Register(_ context.Context, nse *registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, error) {
+	if nse.GetUrl() == "" {
+		return nil, status.Error(codes.FailedPrecondition, "url is required")
+	}
+	if nse.NetworkServiceLabels == nil {
+		nse.NetworkServiceLabels = make(map[string]*registry.NetworkServiceLabels)
+	}
+	for _, ns := range nse.GetNetworkServiceNames() {
+		nse.NetworkServiceLabels[ns] = &registry.NetworkServiceLabels{Labels: map[string]string{"admitted": "true"}}
+	}
+	return nse, nil
+}
+
+func (s *hookNSEServer) Find(*registry.NetworkServiceEndpointQuery, registry.NetworkServiceEndpointRegistry_FindServer) error {
+	return errors.New("not implemented")
+}
+
+func (s *hookNSEServer) Unregister(context.Context, *registry.NetworkServiceEndpoint) (*empty.Empty, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestAdmissionNSEServer_GRPCHook(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	registry.RegisterNetworkServiceEndpointRegistryServer(server, new(hookNSEServer))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	cc, err := grpc.DialContext(ctx, listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() { _ = cc.Close() }()
+
+	s := admission.NewNetworkServiceEndpointRegistryServer(
+		admission.WithNSEMutators(admission.NewGRPCNSEHook(registry.NewNetworkServiceEndpointRegistryClient(cc))),
+	)
+
+	nse, err := s.Register(ctx, &registry.NetworkServiceEndpoint{
+		Name:                "nse-1",
+		Url:                 "tcp://1.1.1.1:5000",
+		NetworkServiceNames: []string{"ns-1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "true", nse.GetNetworkServiceLabels()["ns-1"].GetLabels()["admitted"])
+
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
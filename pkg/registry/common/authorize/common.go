@@ -20,9 +20,7 @@ import (
 	"context"
 
 	"github.com/edwarnicke/genericsync"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
-	"github.com/spiffe/go-spiffe/v2/spiffeid"
 
 	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
@@ -74,39 +72,6 @@ func getRawMap(m *genericsync.Map[string, []string]) map[string][]string {
 	return rawMap
 }
 
-func getSpiffeIDFromPath(ctx context.Context, path *grpcmetadata.Path) spiffeid.ID {
-	if len(path.PathSegments) == 0 {
-		log.FromContext(ctx).Warn("can't get spiffe id from empty path")
-		return spiffeid.ID{}
-	}
-	tokenString := path.PathSegments[0].Token
-
-	claims := jwt.MapClaims{}
-	_, _, err := jwt.NewParser().ParseUnverified(tokenString, &claims)
-	if err != nil {
-		log.FromContext(ctx).Warnf("failed to parse jwt token: %s", err.Error())
-		return spiffeid.ID{}
-	}
-
-	sub, ok := claims["sub"]
-	if !ok {
-		log.FromContext(ctx).Warn("failed to get field 'sub' from jwt token payload")
-		return spiffeid.ID{}
-	}
-	subString, ok := sub.(string)
-	if !ok {
-		log.FromContext(ctx).Warn("failed to convert field 'sub' from jwt token payload to string")
-		return spiffeid.ID{}
-	}
-
-	id, err := spiffeid.FromString(subString)
-	if err != nil {
-		log.FromContext(ctx).Warnf("failed to parse spiffeid from string: %s", err.Error())
-		return spiffeid.ID{}
-	}
-	return id
-}
-
 func getLeftSideOfPath(path *grpcmetadata.Path) *grpcmetadata.Path {
 	if len(path.PathSegments) == 0 {
 		return &grpcmetadata.Path{
@@ -78,7 +78,7 @@ func (c *authorizeNSClient) Register(ctx context.Context, ns *registry.NetworkSe
 	}
 
 	path = grpcmetadata.PathFromContext(ctx)
-	spiffeID := getSpiffeIDFromPath(ctx, path)
+	spiffeID := grpcmetadata.SpiffeIDFromPath(ctx, path)
 	rawMap := getRawMap(c.nsPathIdsMap)
 
 	input := RegistryOpaInput{
@@ -129,7 +129,7 @@ func (c *authorizeNSClient) Unregister(ctx context.Context, ns *registry.Network
 		ctx = peer.NewContext(ctx, &p)
 	}
 
-	spiffeID := getSpiffeIDFromPath(ctx, path)
+	spiffeID := grpcmetadata.SpiffeIDFromPath(ctx, path)
 	rawMap := getRawMap(c.nsPathIdsMap)
 
 	input := RegistryOpaInput{
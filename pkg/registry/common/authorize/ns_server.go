@@ -57,7 +57,7 @@ func (s *authorizeNSServer) Register(ctx context.Context, ns *registry.NetworkSe
 	}
 
 	path := grpcmetadata.PathFromContext(ctx)
-	spiffeID := getSpiffeIDFromPath(ctx, path)
+	spiffeID := grpcmetadata.SpiffeIDFromPath(ctx, path)
 	leftSide := getLeftSideOfPath(path)
 
 	rawMap := getRawMap(s.nsPathIdsMap)
@@ -90,7 +90,7 @@ func (s *authorizeNSServer) Unregister(ctx context.Context, ns *registry.Network
 	}
 
 	path := grpcmetadata.PathFromContext(ctx)
-	spiffeID := getSpiffeIDFromPath(ctx, path)
+	spiffeID := grpcmetadata.SpiffeIDFromPath(ctx, path)
 	leftSide := getLeftSideOfPath(path)
 
 	rawMap := getRawMap(s.nsPathIdsMap)
@@ -78,7 +78,7 @@ func (c *authorizeNSEClient) Register(ctx context.Context, nse *registry.Network
 		ctx = peer.NewContext(ctx, &p)
 	}
 
-	spiffeID := getSpiffeIDFromPath(ctx, path)
+	spiffeID := grpcmetadata.SpiffeIDFromPath(ctx, path)
 	rawMap := getRawMap(c.nsePathIdsMap)
 	input := RegistryOpaInput{
 		ResourceID:         spiffeID.String(),
@@ -128,7 +128,7 @@ func (c *authorizeNSEClient) Unregister(ctx context.Context, nse *registry.Netwo
 		ctx = peer.NewContext(ctx, &p)
 	}
 
-	spiffeID := getSpiffeIDFromPath(ctx, path)
+	spiffeID := grpcmetadata.SpiffeIDFromPath(ctx, path)
 	rawMap := getRawMap(c.nsePathIdsMap)
 	input := RegistryOpaInput{
 		ResourceID:         spiffeID.String(),
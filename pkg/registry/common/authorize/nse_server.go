@@ -57,7 +57,7 @@ func (s *authorizeNSEServer) Register(ctx context.Context, nse *registry.Network
 	}
 
 	path := grpcmetadata.PathFromContext(ctx)
-	spiffeID := getSpiffeIDFromPath(ctx, path)
+	spiffeID := grpcmetadata.SpiffeIDFromPath(ctx, path)
 	leftSide := getLeftSideOfPath(path)
 
 	rawMap := getRawMap(s.nsePathIdsMap)
@@ -91,7 +91,7 @@ func (s *authorizeNSEServer) Unregister(ctx context.Context, nse *registry.Netwo
 	}
 
 	path := grpcmetadata.PathFromContext(ctx)
-	spiffeID := getSpiffeIDFromPath(ctx, path)
+	spiffeID := grpcmetadata.SpiffeIDFromPath(ctx, path)
 	leftSide := getLeftSideOfPath(path)
 
 	rawMap := getRawMap(s.nsePathIdsMap)
@@ -31,6 +31,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/registry/common/begin"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/updatepath"
 	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/drain"
 	"github.com/networkservicemesh/sdk/pkg/tools/clock"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
 	"github.com/networkservicemesh/sdk/pkg/tools/token"
@@ -78,6 +79,12 @@ func (s *expireNSEServer) Register(ctx context.Context, nse *registry.NetworkSer
 		logger.Infof("selected expiration time %v for %v", expirationTime, nse.GetName())
 	}
 
+	// Draining NSE expires not later than the drain deadline
+	if deadline, ok := drain.Deadline(nse); ok && (nse.GetExpirationTime() == nil || deadline.Before(nse.GetExpirationTime().AsTime())) {
+		nse.ExpirationTime = timestamppb.New(deadline)
+		logger.Infof("selected drain deadline %v as expiration time for %v", deadline, nse.GetName())
+	}
+
 	resp, err := next.NetworkServiceEndpointRegistryServer(ctx).Register(ctx, nse)
 	if err != nil {
 		return nil, err
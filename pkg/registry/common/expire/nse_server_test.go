@@ -43,6 +43,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/registry/common/updatepath"
 	"github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
 	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/drain"
 	"github.com/networkservicemesh/sdk/pkg/registry/utils/inject/injecterror"
 	"github.com/networkservicemesh/sdk/pkg/registry/utils/inject/injectpeertoken"
 	"github.com/networkservicemesh/sdk/pkg/tools/clock"
@@ -116,6 +117,37 @@ func TestExpireNSEServer_ShouldCorrectlySetExpirationTime_InRemoteCase(t *testin
 	require.Equal(t, expireTimeout, clockMock.Until(resp.ExpirationTime.AsTime().Local()))
 }
 
+func TestExpireNSEServer_ShouldExpireOnDrainDeadline(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	mem := memory.NewNetworkServiceEndpointRegistryServer()
+
+	s := next.NewNetworkServiceEndpointRegistryServer(
+		begin.NewNetworkServiceEndpointRegistryServer(),
+		expire.NewNetworkServiceEndpointRegistryServer(ctx, expire.WithDefaultExpiration(expireTimeout)),
+		mem,
+	)
+
+	nse := &registry.NetworkServiceEndpoint{Name: nseName}
+	drain.Set(nse, clockMock.Now().Add(expireTimeout/2))
+
+	resp, err := s.Register(ctx, nse)
+	require.NoError(t, err)
+	require.Equal(t, expireTimeout/2, clockMock.Until(resp.ExpirationTime.AsTime()))
+
+	clockMock.Add(expireTimeout / 2)
+	require.Eventually(t, func() bool {
+		nses, err := find(ctx, adapters.NetworkServiceEndpointServerToClient(mem))
+		return err == nil && len(nses) == 0
+	}, testWait, testTick)
+}
+
 func TestExpireNSEServer_ShouldUseLessExpirationTimeFromInput_AndWork(t *testing.T) {
 	t.Cleanup(func() { goleak.VerifyNone(t) })
 
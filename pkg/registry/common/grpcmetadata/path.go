@@ -1,4 +1,4 @@
-// Copyright (c) 2022 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -16,6 +16,15 @@
 
 package grpcmetadata
 
+import (
+	"context"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
 // Path represents a private path that is passed via grpcmetadata during NS and NSE registration
 type Path struct {
 	Index        uint32
@@ -25,6 +34,9 @@ type Path struct {
 // PathSegment represents segment of a private path
 type PathSegment struct {
 	Token string `json:"token"`
+	// ReplicatedBy is the name of the registry that has replicated the request to its peers, if any. It is set by the
+	// client, so the receiving registry should trust it only from an authenticated peer registry.
+	ReplicatedBy string `json:"replicatedBy,omitempty"`
 }
 
 // GetCurrentPathSegment returns path.Index segment if it exists
@@ -49,9 +61,45 @@ func (p *Path) Clone() *Path {
 
 	for _, s := range p.PathSegments {
 		result.PathSegments = append(result.PathSegments, &PathSegment{
-			Token: s.Token,
+			Token:        s.Token,
+			ReplicatedBy: s.ReplicatedBy,
 		})
 	}
 
 	return result
 }
+
+// SpiffeIDFromPath returns spiffe ID of the client started the request: the subject of the first path segment token.
+// The token is not verified here, so the ID should be trusted only after the path tokens are checked by authorize.
+func SpiffeIDFromPath(ctx context.Context, path *Path) spiffeid.ID {
+	if len(path.PathSegments) == 0 {
+		log.FromContext(ctx).Warn("can't get spiffe id from empty path")
+		return spiffeid.ID{}
+	}
+	tokenString := path.PathSegments[0].Token
+
+	claims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(tokenString, &claims)
+	if err != nil {
+		log.FromContext(ctx).Warnf("failed to parse jwt token: %s", err.Error())
+		return spiffeid.ID{}
+	}
+
+	sub, ok := claims["sub"]
+	if !ok {
+		log.FromContext(ctx).Warn("failed to get field 'sub' from jwt token payload")
+		return spiffeid.ID{}
+	}
+	subString, ok := sub.(string)
+	if !ok {
+		log.FromContext(ctx).Warn("failed to convert field 'sub' from jwt token payload to string")
+		return spiffeid.ID{}
+	}
+
+	id, err := spiffeid.FromString(subString)
+	if err != nil {
+		log.FromContext(ctx).Warnf("failed to parse spiffeid from string: %s", err.Error())
+		return spiffeid.ID{}
+	}
+	return id
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthgate
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/edwarnicke/genericsync"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/begin"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type gateState struct {
+	// healthy is changed by the monitor goroutine
+	healthy atomic.Bool
+	nse     atomic.Pointer[registry.NetworkServiceEndpoint]
+	// registered is changed by Register, Unregister, they are serialized by begin
+	registered bool
+	cancel     context.CancelFunc
+}
+
+type healthGateNSEClient struct {
+	ctx    context.Context
+	opts   *options
+	states genericsync.Map[string, *gateState]
+}
+
+// NewNetworkServiceEndpointRegistryClient creates new NetworkServiceEndpointRegistryClient probing the health of the
+// registered NSEs. When the probe fails in a row more than the unhealthy threshold, the NSE is unregistered; when it
+// succeeds in a row more than the healthy threshold, the NSE is registered back.
+// While the NSE is unhealthy, Register requests (e.g. refresh or heal) don't reach the registry and return the
+// requested NSE with no error: the NSE is not lost, it is kept to be probed and registered back on recovery. Failing
+// them instead would make the refresh and the NSE application treat a temporarily unhealthy NSE as a broken
+// registration.
+// The element should be placed after begin and before refresh.
+func NewNetworkServiceEndpointRegistryClient(ctx context.Context, opts ...Option) registry.NetworkServiceEndpointRegistryClient {
+	c := &healthGateNSEClient{
+		ctx: ctx,
+		opts: &options{
+			interval:           5 * time.Second,
+			probeTimeout:       time.Second,
+			unhealthyThreshold: 3,
+			healthyThreshold:   2,
+		},
+	}
+	for _, opt := range opts {
+		opt(c.opts)
+	}
+	if c.opts.probe == nil {
+		c.opts.probe = NewGRPCProbe("", c.opts.dialOptions...)
+	}
+	return c
+}
+
+func (c *healthGateNSEClient) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*registry.NetworkServiceEndpoint, error) {
+	state, loaded := c.states.Load(nse.GetName())
+	if loaded && !state.healthy.Load() {
+		state.nse.Store(nse.Clone())
+		if state.registered {
+			state.registered = false
+			if _, err := next.NetworkServiceEndpointRegistryClient(ctx).Unregister(ctx, nse.Clone(), opts...); err != nil {
+				log.FromContext(ctx).WithField("healthGateNSEClient", "Register").
+					Warnf("failed to unregister unhealthy NSE %s: %s", nse.GetName(), err.Error())
+			}
+		}
+		log.FromContext(ctx).WithField("healthGateNSEClient", "Register").
+			Debugf("NSE %s is unhealthy, it will be registered on recovery", nse.GetName())
+		return nse, nil
+	}
+
+	resp, err := next.NetworkServiceEndpointRegistryClient(ctx).Register(ctx, nse, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if loaded {
+		state.registered = true
+		state.nse.Store(resp.Clone())
+		return resp, nil
+	}
+
+	state = &gateState{registered: true}
+	state.healthy.Store(true)
+	state.nse.Store(resp.Clone())
+
+	var monitorCtx context.Context
+	monitorCtx, state.cancel = context.WithCancel(c.ctx)
+	c.states.Store(nse.GetName(), state)
+	go c.monitor(monitorCtx, begin.FromContext(ctx), state)
+
+	return resp, nil
+}
+
+func (c *healthGateNSEClient) monitor(ctx context.Context, factory begin.EventFactory, state *gateState) {
+	logger := log.FromContext(ctx).WithField("healthGateNSEClient", "monitor")
+	clockTime := clock.FromContext(ctx)
+
+	var failures, successes int
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-clockTime.After(c.opts.interval):
+		}
+
+		nse := state.nse.Load()
+		probeCtx, cancel := clockTime.WithTimeout(ctx, c.opts.probeTimeout)
+		err := c.opts.probe(probeCtx, nse)
+		cancel()
+
+		if err != nil {
+			failures, successes = failures+1, 0
+			if failures < c.opts.unhealthyThreshold || !state.healthy.Load() {
+				continue
+			}
+			logger.Warnf("NSE %s is unhealthy, unregistering: %s", nse.GetName(), err.Error())
+			state.healthy.Store(false)
+		} else {
+			failures, successes = 0, successes+1
+			if successes < c.opts.healthyThreshold || state.healthy.Load() {
+				continue
+			}
+			logger.Infof("NSE %s is healthy again, registering", nse.GetName())
+			state.healthy.Store(true)
+		}
+		<-factory.Register(begin.CancelContext(ctx))
+	}
+}
+
+func (c *healthGateNSEClient) Find(ctx context.Context, query *registry.NetworkServiceEndpointQuery, opts ...grpc.CallOption) (registry.NetworkServiceEndpointRegistry_FindClient, error) {
+	return next.NetworkServiceEndpointRegistryClient(ctx).Find(ctx, query, opts...)
+}
+
+func (c *healthGateNSEClient) Unregister(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*empty.Empty, error) {
+	if state, ok := c.states.LoadAndDelete(nse.GetName()); ok {
+		state.cancel()
+	}
+	return next.NetworkServiceEndpointRegistryClient(ctx).Unregister(ctx, nse, opts...)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthgate_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/begin"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/healthgate"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
+)
+
+const (
+	interval = time.Second
+	testWait = 100 * time.Millisecond
+	testTick = testWait / 100
+)
+
+type countClient struct {
+	registerCount   int32
+	unregisterCount int32
+	lastRegistered  atomic.Pointer[registry.NetworkServiceEndpoint]
+}
+
+func (c *countClient) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*registry.NetworkServiceEndpoint, error) {
+	atomic.AddInt32(&c.registerCount, 1)
+	c.lastRegistered.Store(nse.Clone())
+	return next.NetworkServiceEndpointRegistryClient(ctx).Register(ctx, nse, opts...)
+}
+
+func (c *countClient) Find(ctx context.Context, query *registry.NetworkServiceEndpointQuery, opts ...grpc.CallOption) (registry.NetworkServiceEndpointRegistry_FindClient, error) {
+	return next.NetworkServiceEndpointRegistryClient(ctx).Find(ctx, query, opts...)
+}
+
+func (c *countClient) Unregister(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*empty.Empty, error) {
+	atomic.AddInt32(&c.unregisterCount, 1)
+	return next.NetworkServiceEndpointRegistryClient(ctx).Unregister(ctx, nse, opts...)
+}
+
+func (c *countClient) requireCounts(t *testing.T, registerCount, unregisterCount int32) {
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&c.registerCount) == registerCount && atomic.LoadInt32(&c.unregisterCount) == unregisterCount
+	}, testWait, testTick)
+}
+
+func Test_HealthGateNSEClient_ShouldUnregisterUnhealthyNSE(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	var healthy atomic.Bool
+	healthy.Store(true)
+	var probeCount int32
+	var probedURL atomic.Value
+
+	counter := new(countClient)
+	client := next.NewNetworkServiceEndpointRegistryClient(
+		begin.NewNetworkServiceEndpointRegistryClient(),
+		healthgate.NewNetworkServiceEndpointRegistryClient(ctx,
+			healthgate.WithInterval(interval),
+			healthgate.WithThresholds(3, 2),
+			healthgate.WithProbe(func(_ context.Context, nse *registry.NetworkServiceEndpoint) error {
+				require.Equal(t, "nse-1", nse.GetName())
+				probedURL.Store(nse.GetUrl())
+				atomic.AddInt32(&probeCount, 1)
+				if !healthy.Load() {
+					return errors.New("NSE is broken")
+				}
+				return nil
+			}),
+		),
+		counter,
+	)
+
+	reg, err := client.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-1", Url: "tcp://1.1.1.1"})
+	require.NoError(t, err)
+	counter.requireCounts(t, 1, 0)
+
+	probe := func() {
+		count := atomic.LoadInt32(&probeCount)
+		clockMock.Add(interval)
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&probeCount) == count+1
+		}, testWait, testTick)
+		// Wait for the next probe timer to be set
+		time.Sleep(testWait)
+	}
+
+	// Healthy NSE stays registered
+	probe()
+	counter.requireCounts(t, 1, 0)
+
+	// 3 failed probes in a row are needed to unregister
+	healthy.Store(false)
+	probe()
+	probe()
+	counter.requireCounts(t, 1, 0)
+	probe()
+	counter.requireCounts(t, 1, 1)
+
+	// Register of the unhealthy NSE doesn't reach the registry, but it doesn't fail and the NSE is kept for the recovery
+	update := reg.Clone()
+	update.Url = "tcp://2.2.2.2"
+	reg, err = client.Register(ctx, update)
+	require.NoError(t, err)
+	require.Equal(t, "tcp://2.2.2.2", reg.GetUrl())
+	counter.requireCounts(t, 1, 1)
+
+	// Flapping doesn't register the NSE back
+	healthy.Store(true)
+	probe()
+	healthy.Store(false)
+	probe()
+	counter.requireCounts(t, 1, 1)
+
+	// 2 successful probes in a row are needed to register back
+	healthy.Store(true)
+	probe()
+	counter.requireCounts(t, 1, 1)
+	probe()
+	counter.requireCounts(t, 2, 1)
+	require.Equal(t, "tcp://2.2.2.2", probedURL.Load())
+	require.Equal(t, "tcp://2.2.2.2", counter.lastRegistered.Load().GetUrl())
+
+	_, err = client.Unregister(ctx, reg)
+	require.NoError(t, err)
+	counter.requireCounts(t, 2, 2)
+
+	// Probes are stopped on Unregister
+	clockMock.Add(interval)
+	require.Never(t, func() bool {
+		return atomic.LoadInt32(&probeCount) > 8
+	}, testWait, testTick)
+}
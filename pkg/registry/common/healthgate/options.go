@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthgate
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+type options struct {
+	probe              Probe
+	dialOptions        []grpc.DialOption
+	interval           time.Duration
+	probeTimeout       time.Duration
+	unhealthyThreshold int
+	healthyThreshold   int
+}
+
+// Option is an option pattern for NewNetworkServiceEndpointRegistryClient
+type Option func(o *options)
+
+// WithProbe sets the NSE health probe. By default the gRPC health service of the NSE server is checked, see
+// NewGRPCProbe.
+func WithProbe(probe Probe) Option {
+	return func(o *options) {
+		o.probe = probe
+	}
+}
+
+// WithDialOptions sets dial options for the default gRPC health probe
+func WithDialOptions(dialOptions ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = dialOptions
+	}
+}
+
+// WithInterval sets the interval between the health probes
+func WithInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.interval = interval
+	}
+}
+
+// WithProbeTimeout sets the timeout for a single health probe
+func WithProbeTimeout(probeTimeout time.Duration) Option {
+	return func(o *options) {
+		o.probeTimeout = probeTimeout
+	}
+}
+
+// WithThresholds sets the hysteresis thresholds: the NSE is unregistered after unhealthy failed probes in a row and
+// registered back after healthy successful probes in a row.
+func WithThresholds(unhealthy, healthy int) Option {
+	return func(o *options) {
+		o.unhealthyThreshold = unhealthy
+		o.healthyThreshold = healthy
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthgate
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
+)
+
+const defaultHealthService = "networkservice.NetworkService"
+
+// Probe checks the NSE health, it returns nil if the NSE is healthy
+type Probe func(ctx context.Context, nse *registry.NetworkServiceEndpoint) error
+
+// NewGRPCProbe returns Probe checking the serving status of the service with the gRPC health service on the NSE URL,
+// as registered by grpcutils.RegisterHealthServices. If service is empty, networkservice.NetworkService is checked.
+func NewGRPCProbe(service string, dialOptions ...grpc.DialOption) Probe {
+	if service == "" {
+		service = defaultHealthService
+	}
+	return func(ctx context.Context, nse *registry.NetworkServiceEndpoint) error {
+		u, err := url.Parse(nse.GetUrl())
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse NSE URL: %s", nse.GetUrl())
+		}
+
+		cc, err := grpc.DialContext(ctx, grpcutils.URLToTarget(u), append(dialOptions, grpc.WithBlock())...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to dial NSE: %s", nse.GetUrl())
+		}
+		defer func() { _ = cc.Close() }()
+
+		resp, err := grpc_health_v1.NewHealthClient(cc).Check(ctx, &grpc_health_v1.HealthCheckRequest{
+			Service: service,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to check NSE health: %s", nse.GetUrl())
+		}
+		if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+			return errors.Errorf("NSE service %s is %s", service, resp.GetStatus())
+		}
+		return nil
+	}
+}
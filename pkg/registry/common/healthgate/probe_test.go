@@ -0,0 +1,71 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthgate_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/healthgate"
+)
+
+func Test_GRPCProbe(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	healthServer := health.NewServer()
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	probe := healthgate.NewGRPCProbe("", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	nse := &registry.NetworkServiceEndpoint{
+		Name: "nse-1",
+		Url:  "tcp://" + listener.Addr().String(),
+	}
+
+	healthServer.SetServingStatus("networkservice.NetworkService", grpc_health_v1.HealthCheckResponse_SERVING)
+	require.NoError(t, probe(ctx, nse))
+
+	healthServer.SetServingStatus("networkservice.NetworkService", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	require.Error(t, probe(ctx, nse))
+
+	server.Stop()
+
+	probeCtx, probeCancel := context.WithTimeout(ctx, testWait)
+	defer probeCancel()
+	require.Error(t, probe(probeCtx, nse))
+}
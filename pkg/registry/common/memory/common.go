@@ -1,5 +1,7 @@
 // Copyright (c) 2020 Doc.ai and/or its affiliates.
 //
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
 // SPDX-License-Identifier: Apache-2.0
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -16,4 +18,22 @@
 
 package memory
 
-const defaultEventChannelSize = 10
+import (
+	"context"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+)
+
+const (
+	defaultEventChannelSize    = 10
+	defaultRevisionHistorySize = 1000
+)
+
+// startRevision returns the first revision of the memory server. It is based on the current time, so the revisions
+// issued by the previous instance of the server are out of the history and the resuming watchers are rejected.
+func startRevision(ctx context.Context) uint64 {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return uint64(clock.FromContext(ctx).Now().UnixNano())
+}
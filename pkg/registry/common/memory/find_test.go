@@ -0,0 +1,182 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/streamchannel"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/pagination"
+)
+
+func findNSEs(ctx context.Context, t *testing.T, s registry.NetworkServiceEndpointRegistryServer, query *registry.NetworkServiceEndpointQuery) (names []string, token string) {
+	ch := make(chan *registry.NetworkServiceEndpointResponse, 100)
+	require.NoError(t, s.Find(query, streamchannel.NewNetworkServiceEndpointFindServer(ctx, ch)))
+	close(ch)
+	for nseResp := range ch {
+		names = append(names, nseResp.GetNetworkServiceEndpoint().GetName())
+		token = pagination.ContinueToken(nseResp)
+	}
+	return names, token
+}
+
+func TestNetworkServiceEndpointRegistryServer_Pagination(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := next.NewNetworkServiceEndpointRegistryServer(memory.NewNetworkServiceEndpointRegistryServer(
+		memory.WithMaxPageSize(4),
+	))
+
+	var expected []string
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("nse-%d", i)
+		_, err := s.Register(ctx, &registry.NetworkServiceEndpoint{Name: name, NetworkServiceNames: []string{"ns"}})
+		require.NoError(t, err)
+		expected = append(expected, name)
+	}
+
+	var actual []string
+	var pages int
+	var token string
+	for {
+		query := &registry.NetworkServiceEndpointQuery{
+			NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns"}},
+		}
+		// Requested limit is capped by the max page size
+		pagination.SetLimit(query, 5)
+		pagination.SetContinueToken(query, token)
+
+		var names []string
+		names, token = findNSEs(ctx, t, s, query)
+		require.LessOrEqual(t, len(names), 4)
+		actual = append(actual, names...)
+		pages++
+
+		if token == "" {
+			break
+		}
+	}
+	require.Equal(t, expected, actual)
+	require.Equal(t, 3, pages)
+
+	// Find without a limit is not paginated
+	names, token := findNSEs(ctx, t, s, &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+	})
+	require.Len(t, names, 10)
+	require.Empty(t, token)
+}
+
+func TestNetworkServiceEndpointRegistryServer_InvalidContinueToken(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := memory.NewNetworkServiceEndpointRegistryServer()
+
+	query := &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+	}
+	pagination.SetLimit(query, 1)
+	pagination.SetContinueToken(query, "#invalid")
+
+	err := s.Find(query, streamchannel.NewNetworkServiceEndpointFindServer(context.Background(), nil))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestNetworkServiceEndpointRegistryServer_IndexedFind(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := next.NewNetworkServiceEndpointRegistryServer(memory.NewNetworkServiceEndpointRegistryServer())
+
+	for _, nse := range []*registry.NetworkServiceEndpoint{
+		{Name: "nse-1", NetworkServiceNames: []string{"ns-1"}, Url: "tcp://1.1.1.1:5001"},
+		{Name: "nse-2", NetworkServiceNames: []string{"ns-1", "ns-2"}, Url: "tcp://1.1.1.1:5001"},
+		{Name: "nse-3", NetworkServiceNames: []string{"ns-2"}, Url: "tcp://1.1.1.1:50011"},
+		{Name: "nse-4", NetworkServiceNames: []string{"ns-3"}, Url: "tcp://2.2.2.2:5001"},
+	} {
+		_, err := s.Register(ctx, nse)
+		require.NoError(t, err)
+	}
+
+	// Re-registration updates the indexes
+	_, err := s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-4", NetworkServiceNames: []string{"ns-1"}, Url: "tcp://2.2.2.2:5001"})
+	require.NoError(t, err)
+
+	for _, sample := range []struct {
+		query    *registry.NetworkServiceEndpoint
+		expected []string
+	}{
+		{query: &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns-1"}}, expected: []string{"nse-1", "nse-2", "nse-4"}},
+		{query: &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns-1", "ns-2"}}, expected: []string{"nse-2"}},
+		{query: &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns-3"}}, expected: nil},
+		{query: &registry.NetworkServiceEndpoint{Url: "tcp://1.1.1.1:5001"}, expected: []string{"nse-1", "nse-2", "nse-3"}},
+		{query: &registry.NetworkServiceEndpoint{Url: "1.1.1.1", NetworkServiceNames: []string{"ns-2"}}, expected: []string{"nse-2", "nse-3"}},
+		{query: &registry.NetworkServiceEndpoint{Url: "3.3.3.3"}, expected: nil},
+		{query: &registry.NetworkServiceEndpoint{Name: "nse-3", NetworkServiceNames: []string{"ns-2"}}, expected: []string{"nse-3"}},
+	} {
+		names, _ := findNSEs(ctx, t, s, &registry.NetworkServiceEndpointQuery{NetworkServiceEndpoint: sample.query})
+		require.ElementsMatch(t, sample.expected, names, sample.query.String())
+	}
+
+	_, err = s.Unregister(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.NoError(t, err)
+
+	names, _ := findNSEs(ctx, t, s, &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns-2"}},
+	})
+	require.Equal(t, []string{"nse-3"}, names)
+}
+
+func BenchmarkNetworkServiceEndpointRegistryServer_FindByNetworkService(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := memory.NewNetworkServiceEndpointRegistryServer()
+	for i := 0; i < 10000; i++ {
+		_, err := s.Register(ctx, &registry.NetworkServiceEndpoint{
+			Name:                fmt.Sprintf("nse-%d", i),
+			NetworkServiceNames: []string{fmt.Sprintf("ns-%d", i%100)},
+			Url:                 fmt.Sprintf("tcp://10.0.0.%d:5001", i%10),
+		})
+		require.NoError(b, err)
+	}
+	query := &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns-1"}},
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch := make(chan *registry.NetworkServiceEndpointResponse, 100)
+		require.NoError(b, s.Find(query, streamchannel.NewNetworkServiceEndpointFindServer(ctx, ch)))
+		require.Len(b, ch, 100)
+	}
+}
@@ -1,6 +1,6 @@
 // Copyright (c) 2020-2021 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -27,36 +27,87 @@ import (
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/networkservicemesh/api/pkg/api/registry"
 
 	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/revision"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
 	"github.com/networkservicemesh/sdk/pkg/tools/matchutils"
 )
 
 type memoryNSServer struct {
 	networkServices  genericsync.Map[string, *registry.NetworkService]
 	executor         serialize.Executor
-	eventChannels    map[string]chan *registry.NetworkService
+	watchers         genericsync.Map[string, *watchQueue[*registry.NetworkServiceResponse]]
 	eventChannelSize int
+	overflowPolicy   OverflowPolicy
+	maxPageSize      int
+	metrics          *watchMetrics
+	ctx              context.Context
+	storage          Storage[*registry.NetworkService]
+	historySize      int
+	history          *revisionHistory[*registry.NetworkServiceResponse]
 }
 
 // NewNetworkServiceRegistryServer creates new memory based NetworkServiceRegistryServer
 func NewNetworkServiceRegistryServer(options ...Option) registry.NetworkServiceRegistryServer {
 	s := &memoryNSServer{
 		eventChannelSize: defaultEventChannelSize,
-		eventChannels:    make(map[string]chan *registry.NetworkService),
+		historySize:      defaultRevisionHistorySize,
 	}
 	for _, o := range options {
 		o.apply(s)
 	}
+	s.metrics = newWatchMetrics(s.ctx, "ns", func(observe func(int)) {
+		s.watchers.Range(func(_ string, watcher *watchQueue[*registry.NetworkServiceResponse]) bool {
+			observe(watcher.depth())
+			return true
+		})
+	})
+	s.history = newRevisionHistory[*registry.NetworkServiceResponse](startRevision(s.ctx), s.historySize)
+	if s.storage != nil {
+		s.restore()
+	}
 	return s
 }
 
+func (s *memoryNSServer) restore() {
+	logger := log.FromContext(s.ctx).WithField("memoryNSServer", "restore")
+
+	nss, err := s.storage.Load()
+	if err != nil {
+		logger.Errorf("failed to load NSs from the storage: %s", err.Error())
+		return
+	}
+	for _, ns := range nss {
+		s.networkServices.Store(ns.GetName(), ns)
+	}
+	logger.Infof("restored %d NSs", len(nss))
+}
+
+func (s *memoryNSServer) setContext(ctx context.Context) {
+	s.ctx = ctx
+}
+
 func (s *memoryNSServer) setEventChannelSize(l int) {
 	s.eventChannelSize = l
 }
 
+func (s *memoryNSServer) setRevisionHistorySize(size int) {
+	s.historySize = size
+}
+
+func (s *memoryNSServer) setOverflowPolicy(policy OverflowPolicy) {
+	s.overflowPolicy = policy
+}
+
+func (s *memoryNSServer) setMaxPageSize(size int) {
+	s.maxPageSize = size
+}
+
 func (s *memoryNSServer) Register(ctx context.Context, ns *registry.NetworkService) (*registry.NetworkService, error) {
 	r, err := next.NetworkServiceRegistryServer(ctx).Register(ctx, ns)
 	if err != nil {
@@ -64,28 +115,40 @@ func (s *memoryNSServer) Register(ctx context.Context, ns *registry.NetworkServi
 	}
 
 	s.networkServices.Store(r.Name, r.Clone())
+	if s.storage != nil {
+		if err := s.storage.Store(r); err != nil {
+			log.FromContext(ctx).WithField("memoryNSServer", "storage").Errorf("failed to store NS %s: %s", r.Name, err.Error())
+		}
+	}
 
-	s.sendEvent(r)
+	s.sendEvent(&registry.NetworkServiceResponse{NetworkService: r})
 
 	return r, nil
 }
 
-func (s *memoryNSServer) sendEvent(event *registry.NetworkService) {
+func (s *memoryNSServer) sendEvent(event *registry.NetworkServiceResponse) {
 	event = event.Clone()
 	s.executor.AsyncExec(func() {
-		for _, ch := range s.eventChannels {
-			ch <- event.Clone()
-		}
+		name := event.GetNetworkService().GetName()
+		s.history.add(name, event.GetDeleted(), event)
+		s.watchers.Range(func(_ string, watcher *watchQueue[*registry.NetworkServiceResponse]) bool {
+			if watcher.push(name, event.Clone()) {
+				s.metrics.overflow(s.overflowPolicy)
+			}
+			return true
+		})
 	})
 }
 
 func (s *memoryNSServer) Find(query *registry.NetworkServiceQuery, server registry.NetworkServiceRegistry_FindServer) error {
-	if !query.Watch {
-		for _, ns := range s.allMatches(query) {
-			nsResp := &registry.NetworkServiceResponse{
-				NetworkService: ns,
-			}
+	resumeRevision, resume := revision.ResumeFrom(server.Context(), query)
 
+	if !query.Watch {
+		nsResps, err := paginate(query, s.maxPageSize, s.findMatches(query, resume), nsResponseName)
+		if err != nil {
+			return err
+		}
+		for _, nsResp := range nsResps {
 			if err := server.Send(nsResp); err != nil {
 				return errors.Wrapf(err, "NetworkServiceRegistry find server failed to send a response %s", nsResp.String())
 			}
@@ -93,19 +156,40 @@ func (s *memoryNSServer) Find(query *registry.NetworkServiceQuery, server regist
 		return next.NetworkServiceRegistryServer(server.Context()).Find(query, server)
 	}
 
-	eventCh := make(chan *registry.NetworkService, s.eventChannelSize)
 	id := uuid.New().String()
+	q := newWatchQueue(s.eventChannelSize, s.overflowPolicy, func() []*registry.NetworkServiceResponse {
+		return s.allMatchesWithRevisions(query)
+	}, nsResponseName, deletedNSResponse)
 
-	s.executor.AsyncExec(func() {
-		s.eventChannels[id] = eventCh
-		for _, entity := range s.allMatches(query) {
-			eventCh <- entity
+	var compacted bool
+	<-s.executor.AsyncExec(func() {
+		var events []*registry.NetworkServiceResponse
+		if resume && resumeRevision != 0 {
+			var ok bool
+			if events, ok = s.history.since(resumeRevision); !ok {
+				compacted = true
+				return
+			}
+		} else {
+			events = s.allMatchesWithRevisions(query)
 		}
+		s.watchers.Store(id, q)
+		names := make([]string, 0, len(events))
+		for i := range events {
+			events[i] = events[i].Clone()
+			names = append(names, nsResponseName(events[i]))
+		}
+		q.pushAll(names, events)
+	})
+	if compacted {
+		return status.Errorf(codes.OutOfRange, "revision %d is out of the history", resumeRevision)
+	}
+	defer s.executor.AsyncExec(func() {
+		s.watchers.Delete(id)
 	})
-	defer s.closeEventChannel(id, eventCh)
 
 	var err error
-	for ; err == nil; err = s.receiveEvent(query, server, eventCh) {
+	for ; err == nil; err = s.receiveEvents(query, server, q, resume) {
 	}
 	if !errors.Is(err, io.EOF) {
 		return err
@@ -114,6 +198,12 @@ func (s *memoryNSServer) Find(query *registry.NetworkServiceQuery, server regist
 }
 
 func (s *memoryNSServer) allMatches(query *registry.NetworkServiceQuery) (matches []*registry.NetworkService) {
+	if name := query.GetNetworkService().GetName(); name != "" {
+		if ns, ok := s.networkServices.Load(name); ok && matchutils.MatchNetworkServices(query.NetworkService, ns) {
+			matches = append(matches, ns.Clone())
+		}
+		return matches
+	}
 	s.networkServices.Range(func(_ string, ns *registry.NetworkService) bool {
 		if matchutils.MatchNetworkServices(query.NetworkService, ns) {
 			matches = append(matches, ns.Clone())
@@ -123,50 +213,78 @@ func (s *memoryNSServer) allMatches(query *registry.NetworkServiceQuery) (matche
 	return matches
 }
 
-func (s *memoryNSServer) closeEventChannel(id string, eventCh <-chan *registry.NetworkService) {
-	ctx, cancel := context.WithCancel(context.Background())
-
-	s.executor.AsyncExec(func() {
-		delete(s.eventChannels, id)
-		cancel()
+func (s *memoryNSServer) findMatches(query *registry.NetworkServiceQuery, withRevisions bool) (events []*registry.NetworkServiceResponse) {
+	if !withRevisions {
+		for _, ns := range s.allMatches(query) {
+			events = append(events, &registry.NetworkServiceResponse{NetworkService: ns})
+		}
+		return events
+	}
+	<-s.executor.AsyncExec(func() {
+		events = s.allMatchesWithRevisions(query)
 	})
+	return events
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-eventCh:
-		}
+// allMatchesWithRevisions should be called under the executor
+func (s *memoryNSServer) allMatchesWithRevisions(query *registry.NetworkServiceQuery) (events []*registry.NetworkServiceResponse) {
+	for _, ns := range s.allMatches(query) {
+		event := &registry.NetworkServiceResponse{NetworkService: ns}
+		revision.Set(event, s.history.revisionOf(ns.GetName()))
+		events = append(events, event)
 	}
+	return events
 }
 
-func (s *memoryNSServer) receiveEvent(
+func (s *memoryNSServer) receiveEvents(
 	query *registry.NetworkServiceQuery,
 	server registry.NetworkServiceRegistry_FindServer,
-	eventCh <-chan *registry.NetworkService,
+	q *watchQueue[*registry.NetworkServiceResponse],
+	withRevisions bool,
 ) error {
 	select {
 	case <-server.Context().Done():
 		return errors.WithStack(io.EOF)
-	case event := <-eventCh:
-		if matchutils.MatchNetworkServices(query.NetworkService, event) {
-			nse := &registry.NetworkServiceResponse{
-				NetworkService: event,
-			}
+	case <-q.wait():
+	}
 
-			if err := server.Send(nse); err != nil {
-				if server.Context().Err() != nil {
-					return errors.WithStack(io.EOF)
-				}
-				return errors.Wrapf(err, "NetworkServiceRegistry find server failed to send a response %s", nse.String())
+	events, err := q.popAll()
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if !matchutils.MatchNetworkServices(query.NetworkService, event.NetworkService) {
+			continue
+		}
+		if !withRevisions {
+			revision.Clear(event)
+		}
+		if err := server.Send(event); err != nil {
+			if server.Context().Err() != nil {
+				return errors.WithStack(io.EOF)
 			}
+			return errors.Wrapf(err, "NetworkServiceRegistry find server failed to send a response %s", event.String())
 		}
-		return nil
 	}
+	return nil
 }
 
 func (s *memoryNSServer) Unregister(ctx context.Context, ns *registry.NetworkService) (*empty.Empty, error) {
-	s.networkServices.Delete(ns.Name)
+	if _, ok := s.networkServices.LoadAndDelete(ns.Name); ok && s.storage != nil {
+		if err := s.storage.Delete(ns.Name); err != nil {
+			log.FromContext(ctx).WithField("memoryNSServer", "storage").Errorf("failed to delete NS %s: %s", ns.Name, err.Error())
+		}
+	}
 
 	return next.NetworkServiceRegistryServer(ctx).Unregister(ctx, ns)
 }
+
+func nsResponseName(resp *registry.NetworkServiceResponse) string {
+	return resp.GetNetworkService().GetName()
+}
+
+func deletedNSResponse(resp *registry.NetworkServiceResponse) *registry.NetworkServiceResponse {
+	resp = resp.Clone()
+	resp.Deleted = true
+	return resp
+}
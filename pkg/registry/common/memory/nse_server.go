@@ -1,6 +1,6 @@
 // Copyright (c) 2020-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -21,49 +21,176 @@ package memory
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/edwarnicke/genericsync"
 	"github.com/edwarnicke/serialize"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/networkservicemesh/api/pkg/api/registry"
 
 	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/revision"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
 	"github.com/networkservicemesh/sdk/pkg/tools/matchutils"
 )
 
 type memoryNSEServer struct {
-	networkServiceEndpoints genericsync.Map[string, *registry.NetworkServiceEndpoint]
+	networkServiceEndpoints nseStore
 	executor                serialize.Executor
-	eventChannels           map[string]chan *registry.NetworkServiceEndpointResponse
+	watchers                genericsync.Map[string, *watchQueue[*registry.NetworkServiceEndpointResponse]]
 	eventChannelSize        int
+	overflowPolicy          OverflowPolicy
+	maxPageSize             int
+	metrics                 *watchMetrics
+	ctx                     context.Context
+	storage                 Storage[*registry.NetworkServiceEndpoint]
+	historySize             int
+	history                 *revisionHistory[*registry.NetworkServiceEndpointResponse]
+	restoredTimers          genericsync.Map[string, clock.Timer]
 }
 
 // NewNetworkServiceEndpointRegistryServer creates new memory based NetworkServiceEndpointRegistryServer
 func NewNetworkServiceEndpointRegistryServer(options ...Option) registry.NetworkServiceEndpointRegistryServer {
 	s := &memoryNSEServer{
 		eventChannelSize: defaultEventChannelSize,
-		eventChannels:    make(map[string]chan *registry.NetworkServiceEndpointResponse),
+		historySize:      defaultRevisionHistorySize,
 	}
 	for _, o := range options {
 		o.apply(s)
 	}
+	s.metrics = newWatchMetrics(s.ctx, "nse", func(observe func(int)) {
+		s.watchers.Range(func(_ string, watcher *watchQueue[*registry.NetworkServiceEndpointResponse]) bool {
+			observe(watcher.depth())
+			return true
+		})
+	})
+	s.history = newRevisionHistory[*registry.NetworkServiceEndpointResponse](startRevision(s.ctx), s.historySize)
+	if s.storage != nil {
+		s.restore()
+	}
 	return s
 }
 
+func (s *memoryNSEServer) restore() {
+	logger := log.FromContext(s.ctx).WithField("memoryNSEServer", "restore")
+
+	nses, err := s.storage.Load()
+	if err != nil {
+		logger.Errorf("failed to load NSEs from the storage: %s", err.Error())
+		return
+	}
+
+	timeClock := clock.FromContext(s.ctx)
+	var restored int
+	for _, nse := range nses {
+		if nse.GetExpirationTime() != nil && !timeClock.Now().Before(nse.GetExpirationTime().AsTime()) {
+			if err := s.storage.Delete(nse.GetName()); err != nil {
+				logger.Errorf("failed to delete expired NSE %s from the storage: %s", nse.GetName(), err.Error())
+			}
+			continue
+		}
+		s.networkServiceEndpoints.Store(nse.GetName(), nse)
+		if nse.GetExpirationTime() != nil {
+			s.expireRestored(nse.GetName(), nse.GetExpirationTime().AsTime())
+		}
+		restored++
+	}
+	logger.Infof("restored %d NSEs", restored)
+
+	go func() {
+		<-s.ctx.Done()
+		s.restoredTimers.Range(func(name string, _ clock.Timer) bool {
+			s.stopExpireRestored(name)
+			return true
+		})
+	}()
+}
+
+// expireRestored unregisters the restored NSE when it expires. There is no expire chain element tracking the restored
+// NSE until it is registered again, so the memory server takes care of it.
+func (s *memoryNSEServer) expireRestored(name string, expirationTime time.Time) {
+	timeClock := clock.FromContext(s.ctx)
+	isExpired := func(nse *registry.NetworkServiceEndpoint) bool {
+		return nse.GetExpirationTime() != nil && !timeClock.Now().Before(nse.GetExpirationTime().AsTime())
+	}
+	s.restoredTimers.Store(name, timeClock.AfterFunc(timeClock.Until(expirationTime), func() {
+		s.restoredTimers.Delete(name)
+		if s.ctx.Err() != nil {
+			return
+		}
+		if nse, ok := s.networkServiceEndpoints.Load(name); !ok || !isExpired(nse) {
+			return
+		}
+		nse, ok := s.networkServiceEndpoints.LoadAndDelete(name)
+		if !ok {
+			return
+		}
+		if !isExpired(nse) {
+			// NSE has been registered again right now
+			s.networkServiceEndpoints.LoadOrStore(name, nse)
+			return
+		}
+		s.deleteFromStorage(s.ctx, name)
+		s.sendEvent(&registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: nse.Clone(), Deleted: true})
+	}))
+}
+
+// stopExpireRestored stops the expiration of the restored NSE, it is not needed after the NSE is registered again or
+// unregistered
+func (s *memoryNSEServer) stopExpireRestored(name string) {
+	if timer, ok := s.restoredTimers.LoadAndDelete(name); ok {
+		timer.Stop()
+	}
+}
+
+func (s *memoryNSEServer) deleteFromStorage(ctx context.Context, name string) {
+	if s.storage == nil {
+		return
+	}
+	if err := s.storage.Delete(name); err != nil {
+		log.FromContext(ctx).WithField("memoryNSEServer", "storage").Errorf("failed to delete NSE %s: %s", name, err.Error())
+	}
+}
+
+func (s *memoryNSEServer) setContext(ctx context.Context) {
+	s.ctx = ctx
+}
+
 func (s *memoryNSEServer) setEventChannelSize(l int) {
 	s.eventChannelSize = l
 }
 
+func (s *memoryNSEServer) setRevisionHistorySize(size int) {
+	s.historySize = size
+}
+
+func (s *memoryNSEServer) setOverflowPolicy(policy OverflowPolicy) {
+	s.overflowPolicy = policy
+}
+
+func (s *memoryNSEServer) setMaxPageSize(size int) {
+	s.maxPageSize = size
+}
+
 func (s *memoryNSEServer) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, error) {
 	r, err := next.NetworkServiceEndpointRegistryServer(ctx).Register(ctx, nse)
 	if err != nil {
 		return nil, err
 	}
 
+	s.stopExpireRestored(r.Name)
 	s.networkServiceEndpoints.Store(r.Name, r.Clone())
+	if s.storage != nil {
+		if err := s.storage.Store(r); err != nil {
+			log.FromContext(ctx).WithField("memoryNSEServer", "storage").Errorf("failed to store NSE %s: %s", r.Name, err.Error())
+		}
+	}
 
 	s.sendEvent(&registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: r})
 
@@ -73,18 +200,26 @@ func (s *memoryNSEServer) Register(ctx context.Context, nse *registry.NetworkSer
 func (s *memoryNSEServer) sendEvent(event *registry.NetworkServiceEndpointResponse) {
 	event = event.Clone()
 	s.executor.AsyncExec(func() {
-		for _, ch := range s.eventChannels {
-			ch <- event.Clone()
-		}
+		name := event.GetNetworkServiceEndpoint().GetName()
+		s.history.add(name, event.GetDeleted(), event)
+		s.watchers.Range(func(_ string, watcher *watchQueue[*registry.NetworkServiceEndpointResponse]) bool {
+			if watcher.push(name, event.Clone()) {
+				s.metrics.overflow(s.overflowPolicy)
+			}
+			return true
+		})
 	})
 }
 
 func (s *memoryNSEServer) Find(query *registry.NetworkServiceEndpointQuery, server registry.NetworkServiceEndpointRegistry_FindServer) error {
+	resumeRevision, resume := revision.ResumeFrom(server.Context(), query)
+
 	if !query.Watch {
-		for _, nse := range s.allMatches(query) {
-			nseResp := &registry.NetworkServiceEndpointResponse{
-				NetworkServiceEndpoint: nse,
-			}
+		nseResps, err := paginate(query, s.maxPageSize, s.findMatches(query, resume), nseResponseName)
+		if err != nil {
+			return err
+		}
+		for _, nseResp := range nseResps {
 			if err := server.Send(nseResp); err != nil {
 				return errors.Wrapf(err, "NetworkServiceRegistry find server failed to send a response %s", nseResp.String())
 			}
@@ -96,19 +231,40 @@ func (s *memoryNSEServer) Find(query *registry.NetworkServiceEndpointQuery, serv
 		return err
 	}
 
-	eventCh := make(chan *registry.NetworkServiceEndpointResponse, s.eventChannelSize)
 	id := uuid.New().String()
+	q := newWatchQueue(s.eventChannelSize, s.overflowPolicy, func() []*registry.NetworkServiceEndpointResponse {
+		return s.allMatchesWithRevisions(query)
+	}, nseResponseName, deletedNSEResponse)
 
-	s.executor.AsyncExec(func() {
-		s.eventChannels[id] = eventCh
-		for _, entity := range s.allMatches(query) {
-			eventCh <- &registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: entity}
+	var compacted bool
+	<-s.executor.AsyncExec(func() {
+		var events []*registry.NetworkServiceEndpointResponse
+		if resume && resumeRevision != 0 {
+			var ok bool
+			if events, ok = s.history.since(resumeRevision); !ok {
+				compacted = true
+				return
+			}
+		} else {
+			events = s.allMatchesWithRevisions(query)
+		}
+		s.watchers.Store(id, q)
+		names := make([]string, 0, len(events))
+		for i := range events {
+			events[i] = events[i].Clone()
+			names = append(names, nseResponseName(events[i]))
 		}
+		q.pushAll(names, events)
+	})
+	if compacted {
+		return status.Errorf(codes.OutOfRange, "revision %d is out of the history", resumeRevision)
+	}
+	defer s.executor.AsyncExec(func() {
+		s.watchers.Delete(id)
 	})
-	defer s.closeEventChannel(id, eventCh)
 
 	var err error
-	for ; err == nil; err = s.receiveEvent(query, server, eventCh) {
+	for ; err == nil; err = s.receiveEvents(query, server, q, resume) {
 	}
 	if !errors.Is(err, io.EOF) {
 		return err
@@ -117,57 +273,84 @@ func (s *memoryNSEServer) Find(query *registry.NetworkServiceEndpointQuery, serv
 }
 
 func (s *memoryNSEServer) allMatches(query *registry.NetworkServiceEndpointQuery) (matches []*registry.NetworkServiceEndpoint) {
-	s.networkServiceEndpoints.Range(func(_ string, nse *registry.NetworkServiceEndpoint) bool {
-		if matchutils.MatchNetworkServiceEndpoints(query.NetworkServiceEndpoint, nse) {
-			matches = append(matches, nse.Clone())
-		}
-		return true
-	})
+	for _, nse := range s.networkServiceEndpoints.Find(query.NetworkServiceEndpoint) {
+		matches = append(matches, nse.Clone())
+	}
 	return matches
 }
 
-func (s *memoryNSEServer) closeEventChannel(id string, eventCh <-chan *registry.NetworkServiceEndpointResponse) {
-	ctx, cancel := context.WithCancel(context.Background())
-
-	s.executor.AsyncExec(func() {
-		delete(s.eventChannels, id)
-		cancel()
+func (s *memoryNSEServer) findMatches(query *registry.NetworkServiceEndpointQuery, withRevisions bool) (events []*registry.NetworkServiceEndpointResponse) {
+	if !withRevisions {
+		for _, nse := range s.allMatches(query) {
+			events = append(events, &registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: nse})
+		}
+		return events
+	}
+	<-s.executor.AsyncExec(func() {
+		events = s.allMatchesWithRevisions(query)
 	})
+	return events
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-eventCh:
-		}
+// allMatchesWithRevisions should be called under the executor
+func (s *memoryNSEServer) allMatchesWithRevisions(query *registry.NetworkServiceEndpointQuery) (events []*registry.NetworkServiceEndpointResponse) {
+	for _, nse := range s.allMatches(query) {
+		event := &registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: nse}
+		revision.Set(event, s.history.revisionOf(nse.GetName()))
+		events = append(events, event)
 	}
+	return events
 }
 
-func (s *memoryNSEServer) receiveEvent(
+func (s *memoryNSEServer) receiveEvents(
 	query *registry.NetworkServiceEndpointQuery,
 	server registry.NetworkServiceEndpointRegistry_FindServer,
-	eventCh <-chan *registry.NetworkServiceEndpointResponse,
+	q *watchQueue[*registry.NetworkServiceEndpointResponse],
+	withRevisions bool,
 ) error {
 	select {
 	case <-server.Context().Done():
 		return errors.WithStack(io.EOF)
-	case event := <-eventCh:
-		if matchutils.MatchNetworkServiceEndpoints(query.NetworkServiceEndpoint, event.NetworkServiceEndpoint) {
-			if err := server.Send(event); err != nil {
-				if server.Context().Err() != nil {
-					return errors.WithStack(io.EOF)
-				}
-				return errors.Wrapf(err, "NetworkServiceRegistry find server failed to send a response %s", event.String())
+	case <-q.wait():
+	}
+
+	events, err := q.popAll()
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if !matchutils.MatchNetworkServiceEndpoints(query.NetworkServiceEndpoint, event.NetworkServiceEndpoint) {
+			continue
+		}
+		if !withRevisions {
+			revision.Clear(event)
+		}
+		if err := server.Send(event); err != nil {
+			if server.Context().Err() != nil {
+				return errors.WithStack(io.EOF)
 			}
+			return errors.Wrapf(err, "NetworkServiceRegistry find server failed to send a response %s", event.String())
 		}
-		return nil
 	}
+	return nil
 }
 
 func (s *memoryNSEServer) Unregister(ctx context.Context, nse *registry.NetworkServiceEndpoint) (*empty.Empty, error) {
 	if unregisterNSE, ok := s.networkServiceEndpoints.LoadAndDelete(nse.GetName()); ok {
+		s.stopExpireRestored(nse.GetName())
+		s.deleteFromStorage(ctx, nse.GetName())
 		unregisterNSE = unregisterNSE.Clone()
 		s.sendEvent(&registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: unregisterNSE, Deleted: true})
 	}
 	return next.NetworkServiceEndpointRegistryServer(ctx).Unregister(ctx, nse)
 }
+
+func nseResponseName(resp *registry.NetworkServiceEndpointResponse) string {
+	return resp.GetNetworkServiceEndpoint().GetName()
+}
+
+func deletedNSEResponse(resp *registry.NetworkServiceEndpointResponse) *registry.NetworkServiceEndpointResponse {
+	resp = resp.Clone()
+	resp.Deleted = true
+	return resp
+}
@@ -1,6 +1,6 @@
 // Copyright (c) 2020-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco Systems, Inc.
+// Copyright (c) 2023-2024 Cisco Systems, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -35,6 +35,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
 	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
 	"github.com/networkservicemesh/sdk/pkg/registry/core/streamchannel"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/drain"
 )
 
 func TestNetworkServiceEndpointRegistryServer_RegisterAndFind(t *testing.T) {
@@ -117,6 +118,33 @@ func TestNetworkServiceEndpointRegistryServer_RegisterAndFindWatch(t *testing.T)
 	require.True(t, proto.Equal(&registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: expected}, <-ch))
 }
 
+func TestNetworkServiceEndpointRegistryServer_ShouldPropagateDrainingToWatchers(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+	s := next.NewNetworkServiceEndpointRegistryServer(memory.NewNetworkServiceEndpointRegistryServer())
+
+	nse, err := s.Register(context.Background(), &registry.NetworkServiceEndpoint{
+		Name: "a",
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan *registry.NetworkServiceEndpointResponse, 1)
+	defer close(ch)
+	go func() {
+		_ = s.Find(&registry.NetworkServiceEndpointQuery{
+			Watch:                  true,
+			NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{Name: "a"},
+		}, streamchannel.NewNetworkServiceEndpointFindServer(ctx, ch))
+	}()
+	require.False(t, drain.IsDraining((<-ch).GetNetworkServiceEndpoint()))
+
+	drain.Set(nse, time.Time{})
+	_, err = s.Register(context.Background(), nse)
+	require.NoError(t, err)
+	require.True(t, drain.IsDraining((<-ch).GetNetworkServiceEndpoint()))
+}
+
 func TestNetworkServiceEndpointRegistryServer_RegisterAndFindByLabel(t *testing.T) {
 	t.Cleanup(func() { goleak.VerifyNone(t) })
 	s := next.NewNetworkServiceEndpointRegistryServer(memory.NewNetworkServiceEndpointRegistryServer())
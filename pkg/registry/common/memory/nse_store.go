@@ -0,0 +1,201 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/matchutils"
+)
+
+type nameSet map[string]struct{}
+
+// nseStore is a map of the NSEs by name indexed by the network service names and URLs, so the queries with these
+// fields set don't need to iterate over all the NSEs. Zero value is ready to use.
+type nseStore struct {
+	mu               sync.RWMutex
+	nses             map[string]*registry.NetworkServiceEndpoint
+	byNetworkService map[string]nameSet
+	byURL            map[string]nameSet
+}
+
+func (s *nseStore) Load(name string) (*registry.NetworkServiceEndpoint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	nse, ok := s.nses[name]
+	return nse, ok
+}
+
+func (s *nseStore) Store(name string, nse *registry.NetworkServiceEndpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store(name, nse)
+}
+
+func (s *nseStore) LoadOrStore(name string, nse *registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if actual, ok := s.nses[name]; ok {
+		return actual, true
+	}
+	s.store(name, nse)
+	return nse, false
+}
+
+func (s *nseStore) LoadAndDelete(name string) (*registry.NetworkServiceEndpoint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nse, ok := s.nses[name]
+	if ok {
+		s.unindex(name, nse)
+		delete(s.nses, name)
+	}
+	return nse, ok
+}
+
+func (s *nseStore) Range(f func(name string, nse *registry.NetworkServiceEndpoint) bool) {
+	s.mu.RLock()
+	nses := make([]*registry.NetworkServiceEndpoint, 0, len(s.nses))
+	for _, nse := range s.nses {
+		nses = append(nses, nse)
+	}
+	s.mu.RUnlock()
+
+	for _, nse := range nses {
+		if !f(nse.GetName(), nse) {
+			return
+		}
+	}
+}
+
+// Find returns the NSEs matching the query. It uses the indexes to select the candidates if possible.
+func (s *nseStore) Find(query *registry.NetworkServiceEndpoint) (matches []*registry.NetworkServiceEndpoint) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if query.GetName() != "" {
+		if nse, ok := s.nses[query.GetName()]; ok && matchutils.MatchNetworkServiceEndpoints(query, nse) {
+			matches = append(matches, nse)
+		}
+		return matches
+	}
+
+	candidates, indexed := s.candidates(query)
+	if !indexed {
+		for _, nse := range s.nses {
+			if matchutils.MatchNetworkServiceEndpoints(query, nse) {
+				matches = append(matches, nse)
+			}
+		}
+		return matches
+	}
+	for name := range candidates {
+		if nse := s.nses[name]; matchutils.MatchNetworkServiceEndpoints(query, nse) {
+			matches = append(matches, nse)
+		}
+	}
+	return matches
+}
+
+// candidates returns the smallest indexed superset of the NSE names matching the query. It returns false if there
+// is no index for the query.
+func (s *nseStore) candidates(query *registry.NetworkServiceEndpoint) (candidates nameSet, indexed bool) {
+	for _, nsName := range query.GetNetworkServiceNames() {
+		set := s.byNetworkService[nsName]
+		if !indexed || len(set) < len(candidates) {
+			candidates, indexed = set, true
+		}
+	}
+	if query.GetUrl() == "" || (indexed && len(candidates) == 0) {
+		return candidates, indexed
+	}
+
+	// URL is matched by substring, so we need to check all the distinct URLs. There are usually much less of them
+	// than the NSEs, because the most of the NSEs are registered with the URL of their NSMgr.
+	var urlSets []nameSet
+	for url, set := range s.byURL {
+		if strings.Contains(url, query.GetUrl()) {
+			urlSets = append(urlSets, set)
+		}
+	}
+	var byURL nameSet
+	switch len(urlSets) {
+	case 0:
+		byURL = nameSet{}
+	case 1:
+		byURL = urlSets[0]
+	default:
+		byURL = make(nameSet)
+		for _, set := range urlSets {
+			for name := range set {
+				byURL[name] = struct{}{}
+			}
+		}
+	}
+	if !indexed || len(byURL) < len(candidates) {
+		candidates, indexed = byURL, true
+	}
+	return candidates, indexed
+}
+
+func (s *nseStore) store(name string, nse *registry.NetworkServiceEndpoint) {
+	if s.nses == nil {
+		s.nses = make(map[string]*registry.NetworkServiceEndpoint)
+		s.byNetworkService = make(map[string]nameSet)
+		s.byURL = make(map[string]nameSet)
+	}
+	if prev, ok := s.nses[name]; ok {
+		s.unindex(name, prev)
+	}
+	s.nses[name] = nse
+	for _, nsName := range nse.GetNetworkServiceNames() {
+		addToIndex(s.byNetworkService, nsName, name)
+	}
+	addToIndex(s.byURL, nse.GetUrl(), name)
+}
+
+func (s *nseStore) unindex(name string, nse *registry.NetworkServiceEndpoint) {
+	for _, nsName := range nse.GetNetworkServiceNames() {
+		deleteFromIndex(s.byNetworkService, nsName, name)
+	}
+	deleteFromIndex(s.byURL, nse.GetUrl(), name)
+}
+
+func addToIndex(index map[string]nameSet, key, name string) {
+	set, ok := index[key]
+	if !ok {
+		set = make(nameSet)
+		index[key] = set
+	}
+	set[name] = struct{}{}
+}
+
+func deleteFromIndex(index map[string]nameSet, key, name string) {
+	if set, ok := index[key]; ok {
+		delete(set, name)
+		if len(set) == 0 {
+			delete(index, key)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 // Copyright (c) 2020 Doc.ai and/or its affiliates.
 //
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
 // SPDX-License-Identifier: Apache-2.0
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -16,8 +18,14 @@
 
 package memory
 
+import "context"
+
 type configurable interface {
+	setContext(context.Context)
 	setEventChannelSize(int)
+	setRevisionHistorySize(int)
+	setOverflowPolicy(OverflowPolicy)
+	setMaxPageSize(int)
 }
 
 // Option is memory registry configuration option
@@ -31,9 +39,41 @@ func (f applierFunc) apply(c configurable) {
 	f(c)
 }
 
-// WithEventChannelSize sets specific size of event channels
+// WithEventChannelSize sets specific size of the watcher event queues
 func WithEventChannelSize(l int) Option {
 	return applierFunc(func(c configurable) {
 		c.setEventChannelSize(l)
 	})
 }
+
+// WithRevisionHistorySize sets the number of the last events kept to let the watchers resume from a revision. The
+// watch Find resuming from a revision out of the history fails with codes.OutOfRange, so the watcher should drop its
+// state and watch again from revision 0.
+func WithRevisionHistorySize(size int) Option {
+	return applierFunc(func(c configurable) {
+		c.setRevisionHistorySize(size)
+	})
+}
+
+// WithOverflowPolicy sets the policy applied to a watcher whose event queue is full. Default is OverflowCoalesce.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return applierFunc(func(c configurable) {
+		c.setOverflowPolicy(policy)
+	})
+}
+
+// WithMaxPageSize caps the page size requested by the non-watch Find, see pagination package. Find without the
+// requested page size is not limited.
+func WithMaxPageSize(size int) Option {
+	return applierFunc(func(c configurable) {
+		c.setMaxPageSize(size)
+	})
+}
+
+// WithContext sets the context bounding the registry server lifetime. It is used to get the clock and the logger, the
+// watch queue depth metrics are reported only until ctx is done. Without ctx the depth metrics are not reported.
+func WithContext(ctx context.Context) Option {
+	return applierFunc(func(c configurable) {
+		c.setContext(ctx)
+	})
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/streamchannel"
+)
+
+func startWatch(ctx context.Context, s registry.NetworkServiceEndpointRegistryServer, ch chan *registry.NetworkServiceEndpointResponse) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Find(&registry.NetworkServiceEndpointQuery{
+			NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+			Watch:                  true,
+		}, streamchannel.NewNetworkServiceEndpointFindServer(ctx, ch))
+	}()
+	return errCh
+}
+
+func registerNSEs(ctx context.Context, t *testing.T, s registry.NetworkServiceEndpointRegistryServer, count int, nameFunc func(i int) *registry.NetworkServiceEndpoint) {
+	for i := 0; i < count; i++ {
+		_, err := s.Register(ctx, nameFunc(i))
+		require.NoError(t, err)
+	}
+}
+
+func TestNetworkServiceEndpointRegistryServer_OverflowDisconnect(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	s := memory.NewNetworkServiceEndpointRegistryServer(
+		memory.WithEventChannelSize(2),
+		memory.WithOverflowPolicy(memory.OverflowDisconnect),
+	)
+
+	// Slow watcher never reads the events
+	slowCh := make(chan *registry.NetworkServiceEndpointResponse)
+	slowErrCh := startWatch(ctx, s, slowCh)
+
+	fastCh := make(chan *registry.NetworkServiceEndpointResponse, 20)
+	_ = startWatch(ctx, s, fastCh)
+
+	// Fast watcher is not blocked by the slow one
+	for i := 0; i < 10; i++ {
+		_, err := s.Register(ctx, &registry.NetworkServiceEndpoint{Name: fmt.Sprintf("nse-%d", i)})
+		require.NoError(t, err)
+
+		nseResp, err := receiveNSER(ctx, fastCh)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("nse-%d", i), nseResp.GetNetworkServiceEndpoint().GetName())
+	}
+
+	for {
+		select {
+		case <-slowCh:
+			continue
+		case err := <-slowErrCh:
+			require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		case <-ctx.Done():
+			require.FailNow(t, "slow watcher has not been disconnected")
+		}
+		break
+	}
+}
+
+func TestNetworkServiceEndpointRegistryServer_OverflowCoalesce(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	s := memory.NewNetworkServiceEndpointRegistryServer(
+		memory.WithEventChannelSize(1),
+		memory.WithOverflowPolicy(memory.OverflowCoalesce),
+	)
+
+	ch := make(chan *registry.NetworkServiceEndpointResponse)
+	_ = startWatch(ctx, s, ch)
+
+	registerNSEs(ctx, t, s, 10, func(i int) *registry.NetworkServiceEndpoint {
+		return &registry.NetworkServiceEndpoint{Name: "nse", Url: fmt.Sprintf("tcp://%d", i)}
+	})
+
+	var received int
+	for {
+		nseResp, err := receiveNSER(ctx, ch)
+		require.NoError(t, err)
+		received++
+		if nseResp.GetNetworkServiceEndpoint().GetUrl() == "tcp://9" {
+			break
+		}
+	}
+	require.Less(t, received, 10)
+}
+
+func TestNetworkServiceEndpointRegistryServer_OverflowDropAndResync(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	s := memory.NewNetworkServiceEndpointRegistryServer(
+		memory.WithEventChannelSize(1),
+		memory.WithOverflowPolicy(memory.OverflowDropAndResync),
+	)
+
+	ch := make(chan *registry.NetworkServiceEndpointResponse)
+	_ = startWatch(ctx, s, ch)
+
+	registerNSEs(ctx, t, s, 10, func(i int) *registry.NetworkServiceEndpoint {
+		return &registry.NetworkServiceEndpoint{Name: fmt.Sprintf("nse-%d", i)}
+	})
+
+	names := make(map[string]bool)
+	for len(names) < 10 {
+		nseResp, err := receiveNSER(ctx, ch)
+		require.NoError(t, err)
+		names[nseResp.GetNetworkServiceEndpoint().GetName()] = true
+	}
+}
+
+func TestNetworkServiceEndpointRegistryServer_OverflowDropAndResyncDeleted(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	s := memory.NewNetworkServiceEndpointRegistryServer(
+		memory.WithEventChannelSize(1),
+		memory.WithOverflowPolicy(memory.OverflowDropAndResync),
+	)
+
+	ch := make(chan *registry.NetworkServiceEndpointResponse)
+	_ = startWatch(ctx, s, ch)
+
+	registerNSEs(ctx, t, s, 1, func(i int) *registry.NetworkServiceEndpoint {
+		return &registry.NetworkServiceEndpoint{Name: fmt.Sprintf("nse-%d", i)}
+	})
+	nseResp, err := receiveNSER(ctx, ch)
+	require.NoError(t, err)
+	require.Equal(t, "nse-0", nseResp.GetNetworkServiceEndpoint().GetName())
+
+	registerNSEs(ctx, t, s, 10, func(i int) *registry.NetworkServiceEndpoint {
+		return &registry.NetworkServiceEndpoint{Name: fmt.Sprintf("nse-%d", i)}
+	})
+	// The queue is overflowed, so the deleted events are dropped with it
+	for i := 0; i < 5; i++ {
+		_, err := s.Unregister(ctx, &registry.NetworkServiceEndpoint{Name: fmt.Sprintf("nse-%d", i)})
+		require.NoError(t, err)
+	}
+
+	expected := make(map[string]bool)
+	for i := 5; i < 10; i++ {
+		expected[fmt.Sprintf("nse-%d", i)] = true
+	}
+
+	names := map[string]bool{"nse-0": true}
+	for {
+		nseResp, err := receiveNSER(ctx, ch)
+		require.NoError(t, err)
+
+		if nseResp.GetDeleted() {
+			delete(names, nseResp.GetNetworkServiceEndpoint().GetName())
+		} else {
+			names[nseResp.GetNetworkServiceEndpoint().GetName()] = true
+		}
+		if reflect.DeepEqual(expected, names) {
+			break
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"encoding/base64"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/pagination"
+)
+
+// paginate returns the page of the responses requested by the query. The responses are ordered by the entity name,
+// the continue token refers to the name of the last entity in the page. If the query has no limit, all the responses
+// are returned as is. maxPageSize caps the limit requested by the query, 0 means no cap.
+func paginate[T proto.Message](query proto.Message, maxPageSize int, resps []T, name func(T) string) ([]T, error) {
+	limit := int(pagination.Limit(query))
+	if limit == 0 {
+		return resps, nil
+	}
+	if maxPageSize > 0 && limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(pagination.ContinueToken(query))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid continue token: %s", err.Error())
+	}
+
+	sort.Slice(resps, func(i, j int) bool {
+		return name(resps[i]) < name(resps[j])
+	})
+	start := 0
+	if len(after) > 0 {
+		start = sort.Search(len(resps), func(i int) bool {
+			return name(resps[i]) > string(after)
+		})
+	}
+	resps = resps[start:]
+
+	if len(resps) <= limit {
+		return resps, nil
+	}
+	page := resps[:limit]
+	pagination.SetContinueToken(page[limit-1], base64.RawURLEncoding.EncodeToString([]byte(name(page[limit-1]))))
+	return page, nil
+}
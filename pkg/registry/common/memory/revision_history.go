@@ -0,0 +1,88 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/revision"
+)
+
+// revisionHistory assigns monotonically increasing revisions to the events and keeps the last events to let the
+// watchers resume from a known revision. It is not thread safe and should be used under the executor.
+type revisionHistory[T proto.Message] struct {
+	size int
+	// compacted is the latest revision not present in the history anymore
+	compacted uint64
+	current   uint64
+	events    []T
+	revisions map[string]uint64
+}
+
+func newRevisionHistory[T proto.Message](start uint64, size int) *revisionHistory[T] {
+	return &revisionHistory[T]{
+		size:      size,
+		compacted: start,
+		current:   start,
+		revisions: make(map[string]uint64),
+	}
+}
+
+// add assigns the next revision to the event and stores it in the history
+func (h *revisionHistory[T]) add(name string, deleted bool, event T) {
+	h.current++
+	revision.Set(event, h.current)
+
+	if deleted {
+		delete(h.revisions, name)
+	} else {
+		h.revisions[name] = h.current
+	}
+
+	if h.size <= 0 {
+		h.compacted = h.current
+		return
+	}
+	if len(h.events) == h.size {
+		h.compacted, _ = revision.Get(h.events[0])
+		var zero T
+		h.events[0] = zero
+		h.events = h.events[1:]
+	}
+	h.events = append(h.events, event)
+}
+
+// since returns the events happened after the rev. It returns false if the rev is out of the compaction window.
+func (h *revisionHistory[T]) since(rev uint64) (events []T, ok bool) {
+	if rev < h.compacted || rev > h.current {
+		return nil, false
+	}
+	for _, event := range h.events {
+		if eventRevision, _ := revision.Get(event); eventRevision > rev {
+			events = append(events, event)
+		}
+	}
+	return events, true
+}
+
+// revisionOf returns the revision of the latest event for the entity with the name
+func (h *revisionHistory[T]) revisionOf(name string) uint64 {
+	if rev, ok := h.revisions[name]; ok {
+		return rev
+	}
+	return h.current
+}
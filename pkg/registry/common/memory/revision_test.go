@@ -0,0 +1,228 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/streamchannel"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/revision"
+)
+
+func watchNSEs(ctx context.Context, s registry.NetworkServiceEndpointRegistryServer, resume uint64) <-chan *registry.NetworkServiceEndpointResponse {
+	query := &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+		Watch:                  true,
+	}
+	revision.Set(query, resume)
+
+	ch := make(chan *registry.NetworkServiceEndpointResponse, 10)
+	go func() {
+		_ = s.Find(query, streamchannel.NewNetworkServiceEndpointFindServer(ctx, ch))
+	}()
+	return ch
+}
+
+func receiveNSE(t *testing.T, ch <-chan *registry.NetworkServiceEndpointResponse) (name string, deleted bool, rev uint64) {
+	select {
+	case resp := <-ch:
+		rev, ok := revision.Get(resp)
+		require.True(t, ok)
+		return resp.GetNetworkServiceEndpoint().GetName(), resp.GetDeleted(), rev
+	case <-time.After(time.Second):
+		require.FailNow(t, "no event received")
+	}
+	return "", false, 0
+}
+
+func TestNetworkServiceEndpointRegistryServer_ResumeWatch(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := next.NewNetworkServiceEndpointRegistryServer(memory.NewNetworkServiceEndpointRegistryServer())
+
+	_, err := s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-1"})
+	require.NoError(t, err)
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	ch := watchNSEs(watchCtx, s, 0)
+
+	name, _, lastRevision := receiveNSE(t, ch)
+	require.Equal(t, "nse-1", name)
+
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.NoError(t, err)
+
+	name, _, rev := receiveNSE(t, ch)
+	require.Equal(t, "nse-2", name)
+	require.Greater(t, rev, lastRevision)
+	lastRevision = rev
+
+	// Watcher disconnects and misses some events
+	watchCancel()
+
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-3"})
+	require.NoError(t, err)
+	_, err = s.Unregister(ctx, &registry.NetworkServiceEndpoint{Name: "nse-1"})
+	require.NoError(t, err)
+
+	ch = watchNSEs(ctx, s, lastRevision)
+
+	name, deleted, rev := receiveNSE(t, ch)
+	require.Equal(t, "nse-3", name)
+	require.False(t, deleted)
+	require.Greater(t, rev, lastRevision)
+	lastRevision = rev
+
+	name, deleted, rev = receiveNSE(t, ch)
+	require.Equal(t, "nse-1", name)
+	require.True(t, deleted)
+	require.Greater(t, rev, lastRevision)
+
+	require.Never(t, func() bool { return len(ch) > 0 }, time.Millisecond*100, time.Millisecond*10)
+}
+
+func TestNetworkServiceEndpointRegistryServer_ResumeWatchCompacted(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := next.NewNetworkServiceEndpointRegistryServer(memory.NewNetworkServiceEndpointRegistryServer(
+		memory.WithRevisionHistorySize(1),
+	))
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	ch := watchNSEs(watchCtx, s, 0)
+
+	_, err := s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-1"})
+	require.NoError(t, err)
+
+	_, _, lastRevision := receiveNSE(t, ch)
+	watchCancel()
+
+	_, err = s.Unregister(ctx, &registry.NetworkServiceEndpoint{Name: "nse-1"})
+	require.NoError(t, err)
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.NoError(t, err)
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-3"})
+	require.NoError(t, err)
+
+	// The missed events are out of the history. A resync would send only nse-2 and nse-3, so the watcher would never
+	// learn that nse-1 is deleted. Instead the watcher is rejected and should drop its state and start from scratch.
+	query := &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+		Watch:                  true,
+	}
+	revision.Set(query, lastRevision)
+	err = s.Find(query, streamchannel.NewNetworkServiceEndpointFindServer(ctx, make(chan *registry.NetworkServiceEndpointResponse)))
+	require.Equal(t, codes.OutOfRange, status.Code(err))
+
+	ch = watchNSEs(ctx, s, 0)
+
+	var names []string
+	for i := 0; i < 2; i++ {
+		name, deleted, _ := receiveNSE(t, ch)
+		require.False(t, deleted)
+		names = append(names, name)
+	}
+	require.ElementsMatch(t, []string{"nse-2", "nse-3"}, names)
+}
+
+func TestNetworkServiceEndpointRegistryServer_WithoutRevisions(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := next.NewNetworkServiceEndpointRegistryServer(memory.NewNetworkServiceEndpointRegistryServer())
+
+	_, err := s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-1"})
+	require.NoError(t, err)
+
+	ch := make(chan *registry.NetworkServiceEndpointResponse, 1)
+	go func() {
+		_ = s.Find(&registry.NetworkServiceEndpointQuery{
+			NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+			Watch:                  true,
+		}, streamchannel.NewNetworkServiceEndpointFindServer(ctx, ch))
+	}()
+
+	select {
+	case resp := <-ch:
+		_, ok := revision.Get(resp)
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		require.FailNow(t, "no event received")
+	}
+}
+
+func TestNetworkServiceRegistryServer_ResumeWatch(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := next.NewNetworkServiceRegistryServer(memory.NewNetworkServiceRegistryServer())
+
+	_, err := s.Register(ctx, &registry.NetworkService{Name: "ns-1"})
+	require.NoError(t, err)
+
+	ch := make(chan *registry.NetworkServiceResponse, 10)
+	require.NoError(t, s.Find(&registry.NetworkServiceQuery{
+		NetworkService: new(registry.NetworkService),
+	}, streamchannel.NewNetworkServiceFindServer(metadata.NewIncomingContext(ctx, metadata.Pairs(revision.MetadataKey, "0")), ch)))
+	require.Len(t, ch, 1)
+	lastRevision, ok := revision.Get(<-ch)
+	require.True(t, ok)
+
+	_, err = s.Register(ctx, &registry.NetworkService{Name: "ns-2"})
+	require.NoError(t, err)
+
+	query := &registry.NetworkServiceQuery{
+		NetworkService: new(registry.NetworkService),
+		Watch:          true,
+	}
+	revision.Set(query, lastRevision)
+	go func() {
+		_ = s.Find(query, streamchannel.NewNetworkServiceFindServer(ctx, ch))
+	}()
+
+	select {
+	case resp := <-ch:
+		require.Equal(t, "ns-2", resp.GetNetworkService().GetName())
+		rev, ok := revision.Get(resp)
+		require.True(t, ok)
+		require.Greater(t, rev, lastRevision)
+	case <-time.After(time.Second):
+		require.FailNow(t, "no event received")
+	}
+	require.Never(t, func() bool { return len(ch) > 0 }, time.Millisecond*100, time.Millisecond*10)
+}
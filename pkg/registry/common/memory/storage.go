@@ -0,0 +1,56 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// Storage persists the registry entities, so the memory registry can restore them after restart
+type Storage[T any] interface {
+	// Load returns all the stored entities
+	Load() ([]T, error)
+	// Store stores or replaces the entity with the same name
+	Store(entity T) error
+	// Delete deletes the entity with the name
+	Delete(name string) error
+}
+
+// WithNetworkServiceEndpointStorage sets the storage for the NSEs. The memory registry restores the stored NSEs on
+// creation and unregisters each of them on its expiration time unless it is registered again.
+// ctx is set as with WithContext, the restored NSEs stop expiring when ctx is done.
+func WithNetworkServiceEndpointStorage(ctx context.Context, storage Storage[*registry.NetworkServiceEndpoint]) Option {
+	return applierFunc(func(c configurable) {
+		if s, ok := c.(*memoryNSEServer); ok {
+			s.ctx = ctx
+			s.storage = storage
+		}
+	})
+}
+
+// WithNetworkServiceStorage sets the storage for the NSs. The memory registry restores the stored NSs on creation.
+// ctx is set as with WithContext.
+func WithNetworkServiceStorage(ctx context.Context, storage Storage[*registry.NetworkService]) Option {
+	return applierFunc(func(c configurable) {
+		if s, ok := c.(*memoryNSServer); ok {
+			s.ctx = ctx
+			s.storage = storage
+		}
+	})
+}
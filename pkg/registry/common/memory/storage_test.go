@@ -0,0 +1,173 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
+	"github.com/networkservicemesh/sdk/pkg/tools/filestore"
+)
+
+func findNSENames(ctx context.Context, t *testing.T, s registry.NetworkServiceEndpointRegistryServer) []string {
+	stream, err := adapters.NetworkServiceEndpointServerToClient(s).Find(ctx, &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+	})
+	require.NoError(t, err)
+
+	var names []string
+	for _, nse := range registry.ReadNetworkServiceEndpointList(stream) {
+		names = append(names, nse.GetName())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestNetworkServiceEndpointRegistryServer_Storage(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	dir := t.TempDir()
+	storage, err := filestore.New(dir, func() *registry.NetworkServiceEndpoint { return new(registry.NetworkServiceEndpoint) })
+	require.NoError(t, err)
+
+	s := memory.NewNetworkServiceEndpointRegistryServer(memory.WithNetworkServiceEndpointStorage(ctx, storage))
+	for name, expiration := range map[string]time.Duration{"nse-1": time.Minute, "nse-2": time.Hour, "nse-3": time.Hour} {
+		_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{
+			Name:           name,
+			ExpirationTime: timestamppb.New(clockMock.Now().Add(expiration)),
+		})
+		require.NoError(t, err)
+	}
+	_, err = s.Unregister(ctx, &registry.NetworkServiceEndpoint{Name: "nse-3"})
+	require.NoError(t, err)
+	require.NoError(t, storage.Close())
+
+	// Restart
+	storage, err = filestore.New(dir, func() *registry.NetworkServiceEndpoint { return new(registry.NetworkServiceEndpoint) })
+	require.NoError(t, err)
+	defer func() { _ = storage.Close() }()
+
+	s = memory.NewNetworkServiceEndpointRegistryServer(memory.WithNetworkServiceEndpointStorage(ctx, storage))
+	require.Equal(t, []string{"nse-1", "nse-2"}, findNSENames(ctx, t, s))
+
+	// Restored NSE expires
+	clockMock.Add(time.Minute)
+	require.Eventually(t, func() bool {
+		return len(findNSENames(ctx, t, s)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	nses, err := storage.Load()
+	require.NoError(t, err)
+	require.Len(t, nses, 1)
+	require.Equal(t, "nse-2", nses[0].GetName())
+
+	// Registered again NSE doesn't expire with the restored expiration time
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{
+		Name:           "nse-2",
+		ExpirationTime: timestamppb.New(clockMock.Now().Add(2 * time.Hour)),
+	})
+	require.NoError(t, err)
+
+	clockMock.Add(time.Hour)
+	require.Never(t, func() bool {
+		return len(findNSENames(ctx, t, s)) == 0
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestNetworkServiceEndpointRegistryServer_StorageStopsExpirationOnDone(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	storage, err := filestore.New(t.TempDir(), func() *registry.NetworkServiceEndpoint { return new(registry.NetworkServiceEndpoint) })
+	require.NoError(t, err)
+	defer func() { _ = storage.Close() }()
+
+	require.NoError(t, storage.Store(&registry.NetworkServiceEndpoint{
+		Name:           "nse-1",
+		ExpirationTime: timestamppb.New(clockMock.Now().Add(time.Minute)),
+	}))
+
+	serverCtx, serverCancel := context.WithCancel(ctx)
+	s := memory.NewNetworkServiceEndpointRegistryServer(memory.WithNetworkServiceEndpointStorage(serverCtx, storage))
+	require.Equal(t, []string{"nse-1"}, findNSENames(ctx, t, s))
+
+	serverCancel()
+
+	clockMock.Add(time.Minute)
+	require.Never(t, func() bool {
+		return len(findNSENames(ctx, t, s)) == 0
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestNetworkServiceRegistryServer_Storage(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	newNS := func() *registry.NetworkService { return new(registry.NetworkService) }
+
+	storage, err := filestore.New(dir, newNS)
+	require.NoError(t, err)
+
+	s := memory.NewNetworkServiceRegistryServer(memory.WithNetworkServiceStorage(ctx, storage))
+	_, err = s.Register(ctx, &registry.NetworkService{Name: "ns-1", Payload: "IP"})
+	require.NoError(t, err)
+	_, err = s.Register(ctx, &registry.NetworkService{Name: "ns-2"})
+	require.NoError(t, err)
+	_, err = s.Unregister(ctx, &registry.NetworkService{Name: "ns-2"})
+	require.NoError(t, err)
+	require.NoError(t, storage.Close())
+
+	storage, err = filestore.New(dir, newNS)
+	require.NoError(t, err)
+	defer func() { _ = storage.Close() }()
+
+	s = memory.NewNetworkServiceRegistryServer(memory.WithNetworkServiceStorage(ctx, storage))
+	stream, err := adapters.NetworkServiceServerToClient(s).Find(ctx, &registry.NetworkServiceQuery{
+		NetworkService: new(registry.NetworkService),
+	})
+	require.NoError(t, err)
+
+	nss := registry.ReadNetworkServiceList(stream)
+	require.Len(t, nss, 1)
+	require.Equal(t, "ns-1", nss[0].GetName())
+	require.Equal(t, "IP", nss[0].GetPayload())
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/opentelemetry"
+)
+
+// watchMetrics reports the watcher queues depth and overflows. nil watchMetrics reports nothing.
+type watchMetrics struct {
+	kind      attribute.KeyValue
+	overflows metric.Int64Counter
+}
+
+// newWatchMetrics returns nil if opentelemetry is disabled. depths should call observe for each watcher queue, they are
+// reported until ctx is done. nil ctx disables the depth reporting.
+func newWatchMetrics(ctx context.Context, kind string, depths func(observe func(depth int))) *watchMetrics {
+	if !opentelemetry.IsEnabled() {
+		return nil
+	}
+
+	logCtx := ctx
+	if logCtx == nil {
+		logCtx = context.Background()
+	}
+	logger := log.FromContext(logCtx).WithField("memory", "watchMetrics")
+	meter := otel.Meter("")
+	m := &watchMetrics{
+		kind: attribute.String("kind", kind),
+	}
+
+	var err error
+	if m.overflows, err = meter.Int64Counter("registry_watch_queue_overflows",
+		metric.WithDescription("number of the registry watcher queue overflows")); err != nil {
+		logger.Errorf("failed to create overflows counter: %s", err.Error())
+		return nil
+	}
+	if ctx == nil {
+		return m
+	}
+
+	maxDepth, err := meter.Int64ObservableGauge("registry_watch_queue_max_depth",
+		metric.WithDescription("max number of the queued events per registry watcher"))
+	if err != nil {
+		logger.Errorf("failed to create max depth gauge: %s", err.Error())
+		return nil
+	}
+	totalDepth, err := meter.Int64ObservableGauge("registry_watch_queue_depth",
+		metric.WithDescription("total number of the queued events for all registry watchers"))
+	if err != nil {
+		logger.Errorf("failed to create depth gauge: %s", err.Error())
+		return nil
+	}
+
+	registration, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		var maxValue, totalValue int64
+		depths(func(depth int) {
+			if int64(depth) > maxValue {
+				maxValue = int64(depth)
+			}
+			totalValue += int64(depth)
+		})
+		o.ObserveInt64(maxDepth, maxValue, metric.WithAttributes(m.kind))
+		o.ObserveInt64(totalDepth, totalValue, metric.WithAttributes(m.kind))
+		return nil
+	}, maxDepth, totalDepth)
+	if err != nil {
+		logger.Errorf("failed to register depth callback: %s", err.Error())
+		return nil
+	}
+	go func() {
+		<-ctx.Done()
+		_ = registration.Unregister()
+	}()
+
+	return m
+}
+
+func (m *watchMetrics) overflow(policy OverflowPolicy) {
+	if m == nil {
+		return
+	}
+	m.overflows.Add(context.Background(), 1, metric.WithAttributes(m.kind, attribute.String("policy", policy.String())))
+}
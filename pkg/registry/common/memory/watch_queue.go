@@ -0,0 +1,213 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// OverflowPolicy defines how the memory registry handles a watcher that doesn't keep up with the events
+type OverflowPolicy int
+
+const (
+	// OverflowCoalesce keeps only the latest event for each entity in the overflowed watcher queue. The queue can grow
+	// over its size up to the number of the distinct entities.
+	OverflowCoalesce OverflowPolicy = iota
+	// OverflowDropAndResync drops the overflowed watcher queue and replaces it with the current set of the entities
+	// followed by the deleted events for the entities sent to the watcher before but missing in the current set
+	OverflowDropAndResync
+	// OverflowDisconnect closes the overflowed watcher Find with codes.ResourceExhausted
+	OverflowDisconnect
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowCoalesce:
+		return "coalesce"
+	case OverflowDropAndResync:
+		return "drop-and-resync"
+	case OverflowDisconnect:
+		return "disconnect"
+	default:
+		return "unknown"
+	}
+}
+
+// watchEvent is a registry watch event
+type watchEvent interface {
+	proto.Message
+	GetDeleted() bool
+}
+
+// watchQueue is a bounded per watcher queue of the events. Pushing to the queue never blocks, so a slow watcher
+// doesn't block the events delivery to the other watchers.
+type watchQueue[T watchEvent] struct {
+	size     int
+	policy   OverflowPolicy
+	snapshot func() []T
+	nameOf   func(T) string
+	deleted  func(T) T
+
+	mu sync.Mutex
+	// base is the number of the queued entities sent as a full set, they are not counted against the size
+	base       int
+	names      []string
+	events     []T
+	overflowed bool
+	notifyCh   chan struct{}
+	// delivered is the last event for each existing entity popped from the queue, it is tracked only for the
+	// OverflowDropAndResync policy
+	delivered map[string]T
+}
+
+// newWatchQueue creates a new watch queue. snapshot returns the current set of the entities, it is called in the same
+// goroutine as push. nameOf returns the entity name of the event, deleted returns the deleted event for the entity of
+// the event. They are used by the OverflowDropAndResync policy.
+func newWatchQueue[T watchEvent](size int, policy OverflowPolicy, snapshot func() []T, nameOf func(T) string, deleted func(T) T) *watchQueue[T] {
+	q := &watchQueue[T]{
+		size:     size,
+		policy:   policy,
+		snapshot: snapshot,
+		nameOf:   nameOf,
+		deleted:  deleted,
+		notifyCh: make(chan struct{}, 1),
+	}
+	if policy == OverflowDropAndResync {
+		q.delivered = make(map[string]T)
+	}
+	return q
+}
+
+// track remembers the last event delivered to the watcher for the entity, so the resync can tell the watcher about
+// the deleted entities
+func (q *watchQueue[T]) track(name string, event T) {
+	if q.delivered == nil {
+		return
+	}
+	if event.GetDeleted() {
+		delete(q.delivered, name)
+		return
+	}
+	q.delivered[name] = event
+}
+
+// pushAll appends the events to the queue ignoring the size. It is used to send the initial set of the entities.
+func (q *watchQueue[T]) pushAll(names []string, events []T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.base += len(events)
+	q.names = append(q.names, names...)
+	q.events = append(q.events, events...)
+	q.notify()
+}
+
+// push appends the event to the queue applying the overflow policy if the queue is full. It returns true if the queue
+// has been overflowed.
+func (q *watchQueue[T]) push(name string, event T) (overflowed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.overflowed {
+		return false
+	}
+	defer q.notify()
+
+	if len(q.events) < q.base+q.size {
+		q.names = append(q.names, name)
+		q.events = append(q.events, event)
+		return false
+	}
+
+	switch q.policy {
+	case OverflowDropAndResync:
+		q.resync()
+	case OverflowDisconnect:
+		q.overflowed = true
+		q.base, q.names, q.events = 0, nil, nil
+	default:
+		for i := range q.names {
+			if q.names[i] == name {
+				q.events[i] = event
+				return true
+			}
+		}
+		q.names = append(q.names, name)
+		q.events = append(q.events, event)
+	}
+	return true
+}
+
+// resync replaces the queued events with the current set of the entities. The entities delivered to the watcher but
+// missing in the current set have been deleted, so the deleted events are queued for them.
+func (q *watchQueue[T]) resync() {
+	events := q.snapshot()
+	names := make([]string, 0, len(events))
+	current := make(map[string]struct{}, len(events))
+	for _, event := range events {
+		name := q.nameOf(event)
+		names = append(names, name)
+		current[name] = struct{}{}
+	}
+	for name, event := range q.delivered {
+		if _, ok := current[name]; !ok {
+			events = append(events, q.deleted(event))
+			names = append(names, name)
+		}
+	}
+	q.base, q.names, q.events = len(events), names, events
+}
+
+func (q *watchQueue[T]) notify() {
+	select {
+	case q.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// wait returns a channel signaling that there are events in the queue
+func (q *watchQueue[T]) wait() <-chan struct{} {
+	return q.notifyCh
+}
+
+// popAll returns all the queued events or an error if the watcher has been disconnected on overflow
+func (q *watchQueue[T]) popAll() ([]T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.overflowed {
+		return nil, status.Errorf(codes.ResourceExhausted, "watcher has not kept up with %d queued events", q.size)
+	}
+	for i := range q.events {
+		q.track(q.names[i], q.events[i])
+	}
+	events := q.events
+	q.base, q.names, q.events = 0, nil, nil
+	return events, nil
+}
+
+// depth returns the current number of the queued events
+func (q *watchQueue[T]) depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.events)
+}
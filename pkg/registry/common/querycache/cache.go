@@ -1,6 +1,6 @@
 // Copyright (c) 2021 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -23,26 +23,65 @@ import (
 	"sync"
 	"time"
 
-	"github.com/edwarnicke/genericsync"
-	"github.com/networkservicemesh/api/pkg/api/registry"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/networkservicemesh/sdk/pkg/tools/clock"
 )
 
-type cache struct {
+// maxRecentEvents is the number of the last watch events kept to catch up the results found concurrently with them
+const maxRecentEvents = 1024
+
+// cache stores the Find results by query. The cached results are kept fresh by the watch events applied with Update.
+type cache[T proto.Message] struct {
+	ctx           context.Context
 	expireTimeout time.Duration
-	entries       genericsync.Map[string, *cacheEntry]
 	clockTime     clock.Clock
+	// match returns true if the item matches the query
+	match func(query, item T) bool
+	// nameOf returns the name of the item
+	nameOf func(item T) string
+	// nameQuery returns a query matching the item with the name
+	nameQuery func(name string) T
+
+	lock sync.Mutex
+	// byName stores the results for the queries by the name only, they are updated without matching
+	byName map[string]*cacheEntry[T]
+	// byQuery stores the results for the other queries
+	byQuery map[string]*cacheEntry[T]
+	// version is incremented on each Update and Invalidate
+	version uint64
+	// recent are the last applied events, they are replayed on the results found before them
+	recent []cacheEvent[T]
 }
 
-func newCache(ctx context.Context, opts ...Option) *cache {
-	c := &cache{
-		expireTimeout: time.Minute,
-		clockTime:     clock.FromContext(ctx),
-	}
+type cacheEntry[T proto.Message] struct {
+	query T
+	// key is the key of the entry in byName if byName is true or in byQuery otherwise
+	key            string
+	byName         bool
+	items          map[string]T
+	expirationTime time.Time
+	// watchCtx is the context of the watch keeping the result by the name fresh, it is nil for the results kept fresh
+	// by the shared watch stream
+	watchCtx  context.Context
+	stopWatch context.CancelFunc
+}
+
+type cacheEvent[T proto.Message] struct {
+	item    T
+	deleted bool
+}
 
-	for _, opt := range opts {
-		opt(c)
+func newCache[T proto.Message](ctx context.Context, o *options, match func(query, item T) bool, nameOf func(item T) string, nameQuery func(name string) T) *cache[T] {
+	c := &cache[T]{
+		ctx:           ctx,
+		expireTimeout: o.expireTimeout,
+		clockTime:     clock.FromContext(ctx),
+		match:         match,
+		nameOf:        nameOf,
+		nameQuery:     nameQuery,
+		byName:        make(map[string]*cacheEntry[T]),
+		byQuery:       make(map[string]*cacheEntry[T]),
 	}
 
 	ticker := c.clockTime.Ticker(c.expireTimeout)
@@ -53,16 +92,10 @@ func newCache(ctx context.Context, opts ...Option) *cache {
 				ticker.Stop()
 				return
 			case <-ticker.C():
-				c.entries.Range(func(_ string, e *cacheEntry) bool {
-					e.lock.Lock()
-					defer e.lock.Unlock()
-
-					if c.clockTime.Until(e.expirationTime) < 0 {
-						e.cleanup()
-					}
-
-					return true
-				})
+				c.lock.Lock()
+				c.deleteExpired(c.byName)
+				c.deleteExpired(c.byQuery)
+				c.lock.Unlock()
 			}
 		}
 	}()
@@ -70,56 +103,211 @@ func newCache(ctx context.Context, opts ...Option) *cache {
 	return c
 }
 
-func (c *cache) LoadOrStore(key string, nse *registry.NetworkServiceEndpoint, cancel context.CancelFunc) (*cacheEntry, bool) {
-	var once sync.Once
-	return c.entries.LoadOrStore(key, &cacheEntry{
-		nse:            nse,
-		expirationTime: c.clockTime.Now().Add(c.expireTimeout),
-		cleanup: func() {
-			once.Do(func() {
-				c.entries.Delete(key)
-				cancel()
-			})
-		},
-	})
+func (c *cache[T]) deleteExpired(entries map[string]*cacheEntry[T]) {
+	for _, e := range entries {
+		if c.clockTime.Until(e.expirationTime) < 0 {
+			c.remove(e)
+		}
+	}
 }
 
-func (c *cache) Load(key string) (*registry.NetworkServiceEndpoint, bool) {
-	e, ok := c.entries.Load(key)
-	if !ok {
-		return nil, false
+// remove deletes the entry from the cache and stops its watch
+func (c *cache[T]) remove(e *cacheEntry[T]) {
+	delete(c.entries(e.byName), e.key)
+	if e.stopWatch != nil {
+		e.stopWatch()
 	}
+}
 
-	e.lock.Lock()
-	defer e.lock.Unlock()
+// keyOf returns the key for the query and true if it is a query by the name only
+func (c *cache[T]) keyOf(query T) (key string, byName bool) {
+	if name := c.nameOf(query); name != "" && proto.Equal(query, c.nameQuery(name)) {
+		return name, true
+	}
+	b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(query)
+	return string(b), false
+}
+
+func (c *cache[T]) entries(byName bool) map[string]*cacheEntry[T] {
+	if byName {
+		return c.byName
+	}
+	return c.byQuery
+}
+
+// Load returns the cached result for the query and prolongs its expiration
+func (c *cache[T]) Load(query T) ([]T, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
+	key, byName := c.keyOf(query)
+	entries := c.entries(byName)
+	e, ok := entries[key]
+	if !ok {
+		return nil, false
+	}
 	if c.clockTime.Until(e.expirationTime) < 0 {
-		e.cleanup()
+		c.remove(e)
 		return nil, false
 	}
-
 	e.expirationTime = c.clockTime.Now().Add(c.expireTimeout)
 
-	return e.nse, true
+	items := make([]T, 0, len(e.items))
+	for _, item := range e.items {
+		items = append(items, proto.Clone(item).(T))
+	}
+	return items, true
 }
 
-type cacheEntry struct {
-	nse            *registry.NetworkServiceEndpoint
-	expirationTime time.Time
-	lock           sync.Mutex
-	cleanup        func()
+// Version returns the version of the cache to pass to Store for the result found after this call
+func (c *cache[T]) Version() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.version
 }
 
-func (e *cacheEntry) Update(nse *registry.NetworkServiceEndpoint) {
-	e.lock.Lock()
-	defer e.lock.Unlock()
+// Store stores the result for the query found after the since version. Each of the items is also stored for the query
+// by its name. The events applied after the since version are replayed on the stored results, so the updates and
+// deletes concurrent with the Find are not lost. If these events are not kept anymore or the cache has been
+// invalidated, the result is not stored. The result expires in expireTimeout after the last Store or Load.
+func (c *cache[T]) Store(query T, items []T, since uint64) {
+	c.storeSince(since, items, func() []*cacheEntry[T] {
+		stored := []*cacheEntry[T]{c.store(query, items, nil)}
+		for _, item := range items {
+			stored = append(stored, c.store(c.nameQuery(c.nameOf(item)), []T{item}, nil))
+		}
+		return stored
+	})
+}
 
-	e.nse = nse
+// StoreByName stores each of the items found after the since version for the query by its name. Each of the stored
+// results is kept fresh by its own watch: watch is started for the name not watched yet and should apply the events
+// with Update until ctx is done. When the watch returns, the result by the name is deleted.
+func (c *cache[T]) StoreByName(items []T, since uint64, watch func(ctx context.Context, name string)) {
+	c.storeSince(since, items, func() []*cacheEntry[T] {
+		var stored []*cacheEntry[T]
+		for _, item := range items {
+			if c.nameOf(item) != "" {
+				stored = append(stored, c.store(c.nameQuery(c.nameOf(item)), []T{item}, watch))
+			}
+		}
+		return stored
+	})
 }
 
-func (e *cacheEntry) Cleanup() {
-	e.lock.Lock()
-	defer e.lock.Unlock()
+func (c *cache[T]) storeSince(since uint64, items []T, store func() []*cacheEntry[T]) {
+	if len(items) == 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if since > c.version || c.version-since > uint64(len(c.recent)) {
+		return
+	}
+
+	stored := store()
+	for _, event := range c.recent[len(c.recent)-int(c.version-since):] {
+		for _, e := range stored {
+			c.apply(e, event)
+		}
+	}
+}
+
+func (c *cache[T]) store(query T, items []T, watch func(ctx context.Context, name string)) *cacheEntry[T] {
+	key, byName := c.keyOf(query)
+	e := &cacheEntry[T]{
+		query:          query,
+		key:            key,
+		byName:         byName,
+		items:          make(map[string]T, len(items)),
+		expirationTime: c.clockTime.Now().Add(c.expireTimeout),
+	}
+	for _, item := range items {
+		e.items[c.nameOf(item)] = proto.Clone(item).(T)
+	}
+
+	entries := c.entries(byName)
+	if prev, ok := entries[key]; ok && prev.watchCtx != nil {
+		e.watchCtx, e.stopWatch = prev.watchCtx, prev.stopWatch
+	} else if watch != nil {
+		e.watchCtx, e.stopWatch = context.WithCancel(c.ctx)
+		go func(ctx context.Context) {
+			watch(ctx, key)
+			c.forget(ctx, key)
+		}(e.watchCtx)
+	}
+	entries[key] = e
+	return e
+}
+
+// forget deletes the result by the name if it is still kept fresh by the watch with ctx
+func (c *cache[T]) forget(ctx context.Context, name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, ok := c.byName[name]; ok && e.watchCtx == ctx {
+		c.remove(e)
+	}
+}
+
+// Update applies the watch event to all the cached results. A result becoming empty is deleted from the cache.
+func (c *cache[T]) Update(item T, deleted bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	event := cacheEvent[T]{item: proto.Clone(item).(T), deleted: deleted}
+	c.version++
+	c.recent = append(c.recent, event)
+	if len(c.recent) > maxRecentEvents {
+		c.recent = append(c.recent[:0], c.recent[len(c.recent)-maxRecentEvents:]...)
+	}
+
+	if e, ok := c.byName[c.nameOf(item)]; ok {
+		c.apply(e, event)
+	}
+	for _, e := range c.byQuery {
+		c.apply(e, event)
+	}
+}
+
+// apply applies the event to the cached result if it is still stored. The results by the name are updated without
+// matching.
+func (c *cache[T]) apply(e *cacheEntry[T], event cacheEvent[T]) {
+	entries := c.entries(e.byName)
+	if entries[e.key] != e {
+		return
+	}
 
-	e.cleanup()
+	name := c.nameOf(event.item)
+	if e.byName && e.key != name {
+		return
+	}
+	if !event.deleted && (e.byName || c.match(e.query, event.item)) {
+		e.items[name] = proto.Clone(event.item).(T)
+		return
+	}
+	if _, ok := e.items[name]; !ok {
+		return
+	}
+	delete(e.items, name)
+	if len(e.items) == 0 {
+		c.remove(e)
+	}
+}
+
+// Invalidate deletes all the cached results
+func (c *cache[T]) Invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.version++
+	c.recent = nil
+	for _, e := range c.byName {
+		c.remove(e)
+	}
+	c.byName = make(map[string]*cacheEntry[T])
+	c.byQuery = make(map[string]*cacheEntry[T])
 }
@@ -0,0 +1,30 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querycache
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/pagination"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/revision"
+)
+
+// isCacheable returns false for the queries asking for the revisions or for a page of the results
+func isCacheable(query proto.Message) bool {
+	_, withRevisions := revision.Get(query)
+	return !withRevisions && pagination.Limit(query) == 0 && pagination.ContinueToken(query) == ""
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querycache
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/streamchannel"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/revision"
+	"github.com/networkservicemesh/sdk/pkg/tools/matchutils"
+)
+
+type queryCacheNSClient struct {
+	cache   *cache[*registry.NetworkService]
+	watcher *watcher[*registry.NetworkServiceResponse]
+}
+
+// NewNetworkServiceRegistryClient creates new querycache NS registry client that caches all resolved NSs. Each of the
+// found NSs is cached by its name and is kept fresh by its own watch Find sent to the next client. With the
+// WithNetworkServiceWatchClient client the non-watch Find results are cached by query instead and are kept fresh by a
+// single watch stream shared by all the queries. The watch is started from ctx, the results found while the watch
+// stream is not open are not cached. The cached result expires if it is not found for the expire timeout.
+func NewNetworkServiceRegistryClient(ctx context.Context, opts ...Option) registry.NetworkServiceRegistryClient {
+	o := newOptions(opts...)
+	c := newCache(ctx, o,
+		matchutils.MatchNetworkServices,
+		(*registry.NetworkService).GetName,
+		func(name string) *registry.NetworkService {
+			return &registry.NetworkService{Name: name}
+		},
+	)
+	q := &queryCacheNSClient{cache: c}
+	if o.nsWatchClient != nil {
+		q.watcher = startWatcher(ctx, o.retryInterval,
+			func(nsResp *registry.NetworkServiceResponse) {
+				c.Update(nsResp.GetNetworkService(), nsResp.GetDeleted())
+			},
+			c.Invalidate,
+			func(watchCtx context.Context, resume uint64) (recvStream[*registry.NetworkServiceResponse], error) {
+				watchQuery := &registry.NetworkServiceQuery{
+					NetworkService: new(registry.NetworkService),
+					Watch:          true,
+				}
+				revision.Set(watchQuery, resume)
+				return o.nsWatchClient.Find(watchCtx, watchQuery, o.watchCallOptions...)
+			},
+		)
+	}
+	return q
+}
+
+func (q *queryCacheNSClient) Register(ctx context.Context, ns *registry.NetworkService, opts ...grpc.CallOption) (*registry.NetworkService, error) {
+	return next.NetworkServiceRegistryClient(ctx).Register(ctx, ns, opts...)
+}
+
+func (q *queryCacheNSClient) Find(ctx context.Context, query *registry.NetworkServiceQuery, opts ...grpc.CallOption) (registry.NetworkServiceRegistry_FindClient, error) {
+	if query.Watch || query.GetNetworkService() == nil || !isCacheable(query) {
+		return next.NetworkServiceRegistryClient(ctx).Find(ctx, query, opts...)
+	}
+
+	if nss, ok := q.cache.Load(query.GetNetworkService()); ok {
+		return q.newFindClient(ctx, nss), nil
+	}
+
+	if q.watcher != nil && !q.watcher.isEstablished() {
+		// The result can't be kept fresh, so it is not cached
+		return next.NetworkServiceRegistryClient(ctx).Find(ctx, query, opts...)
+	}
+
+	since := q.cache.Version()
+	client, err := next.NetworkServiceRegistryClient(ctx).Find(ctx, query, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	nss := registry.ReadNetworkServiceList(client)
+	if q.watcher != nil {
+		q.cache.Store(query.GetNetworkService(), nss, since)
+	} else {
+		nextClient := next.NetworkServiceRegistryClient(ctx)
+		q.cache.StoreByName(nss, since, func(watchCtx context.Context, name string) {
+			q.watch(watchCtx, nextClient, name, opts...)
+		})
+	}
+
+	return q.newFindClient(ctx, nss), nil
+}
+
+// watch applies the events for the NS with the name to the cache until the watch stream breaks or ctx is done
+func (q *queryCacheNSClient) watch(ctx context.Context, client registry.NetworkServiceRegistryClient, name string, opts ...grpc.CallOption) {
+	stream, err := client.Find(ctx, &registry.NetworkServiceQuery{
+		NetworkService: &registry.NetworkService{Name: name},
+		Watch:          true,
+	}, opts...)
+	if err != nil {
+		return
+	}
+
+	for nsResp, err := stream.Recv(); err == nil; nsResp, err = stream.Recv() {
+		if nsResp.GetNetworkService().GetName() != name {
+			continue
+		}
+		q.cache.Update(nsResp.GetNetworkService(), nsResp.GetDeleted())
+	}
+}
+
+func (q *queryCacheNSClient) newFindClient(ctx context.Context, nss []*registry.NetworkService) registry.NetworkServiceRegistry_FindClient {
+	resultCh := make(chan *registry.NetworkServiceResponse, len(nss))
+	for _, ns := range nss {
+		resultCh <- &registry.NetworkServiceResponse{NetworkService: ns}
+	}
+	close(resultCh)
+
+	return streamchannel.NewNetworkServiceFindClient(ctx, resultCh)
+}
+
+func (q *queryCacheNSClient) Unregister(ctx context.Context, ns *registry.NetworkService, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.NetworkServiceRegistryClient(ctx).Unregister(ctx, ns, opts...)
+}
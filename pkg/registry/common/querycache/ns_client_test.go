@@ -0,0 +1,132 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querycache_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/querycache"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+)
+
+func testFindNS(ctx context.Context, c registry.NetworkServiceRegistryClient, nsName string) (*registry.NetworkService, error) {
+	stream, err := c.Find(ctx, &registry.NetworkServiceQuery{
+		NetworkService: &registry.NetworkService{Name: nsName},
+	})
+	if err != nil {
+		return nil, err
+	}
+	nsResp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return nsResp.GetNetworkService(), nil
+}
+
+func Test_QueryCacheNSClient_ShouldCacheNSs(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mem := memory.NewNetworkServiceRegistryServer()
+
+	testQueryCacheNSClient(ctx, t, mem, querycache.WithExpireTimeout(expireTimeout))
+}
+
+func Test_QueryCacheNSClient_WatchClient_ShouldCacheNSs(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mem := memory.NewNetworkServiceRegistryServer()
+
+	testQueryCacheNSClient(ctx, t, mem,
+		querycache.WithExpireTimeout(expireTimeout),
+		querycache.WithNetworkServiceWatchClient(adapters.NetworkServiceServerToClient(mem)))
+}
+
+func testQueryCacheNSClient(ctx context.Context, t *testing.T, mem registry.NetworkServiceRegistryServer, opts ...querycache.Option) {
+	failureClient := new(failureNSClient)
+	c := next.NewNetworkServiceRegistryClient(
+		querycache.NewNetworkServiceRegistryClient(ctx, opts...),
+		failureClient,
+		adapters.NetworkServiceServerToClient(mem),
+	)
+
+	_, err := mem.Register(ctx, &registry.NetworkService{Name: "ns", Payload: "IP"})
+	require.NoError(t, err)
+
+	// 1. Find from memory
+	ns, err := testFindNS(ctx, c, "ns")
+	require.NoError(t, err)
+	require.Equal(t, "IP", ns.GetPayload())
+
+	// 2. Find from cache
+	requireCached(t, &failureClient.shouldFail, func() error {
+		_, err := testFindNS(ctx, c, "ns")
+		return err
+	})
+
+	ns, err = testFindNS(ctx, c, "ns")
+	require.NoError(t, err)
+	require.Equal(t, "IP", ns.GetPayload())
+
+	// 3. Update NS in memory
+	_, err = mem.Register(ctx, &registry.NetworkService{Name: "ns", Payload: "ETHERNET"})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		ns, err = testFindNS(ctx, c, "ns")
+		return err == nil && ns.GetPayload() == "ETHERNET"
+	}, testWait, testTick)
+
+	// 4. Not cached NS is not found
+	_, err = testFindNS(ctx, c, "other")
+	require.Error(t, err)
+}
+
+type failureNSClient struct {
+	shouldFail int32
+}
+
+func (c *failureNSClient) Register(ctx context.Context, ns *registry.NetworkService, opts ...grpc.CallOption) (*registry.NetworkService, error) {
+	return next.NetworkServiceRegistryClient(ctx).Register(ctx, ns, opts...)
+}
+
+func (c *failureNSClient) Find(ctx context.Context, query *registry.NetworkServiceQuery, opts ...grpc.CallOption) (registry.NetworkServiceRegistry_FindClient, error) {
+	if atomic.LoadInt32(&c.shouldFail) == 1 && !query.Watch {
+		return nil, errors.New("find error")
+	}
+	return next.NetworkServiceRegistryClient(ctx).Find(ctx, query, opts...)
+}
+
+func (c *failureNSClient) Unregister(ctx context.Context, ns *registry.NetworkService, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.NetworkServiceRegistryClient(ctx).Unregister(ctx, ns, opts...)
+}
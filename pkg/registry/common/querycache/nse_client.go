@@ -1,5 +1,7 @@
 // Copyright (c) 2020-2021 Doc.ai and/or its affiliates.
 //
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
 // SPDX-License-Identifier: Apache-2.0
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -27,19 +29,47 @@ import (
 
 	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
 	"github.com/networkservicemesh/sdk/pkg/registry/core/streamchannel"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/revision"
+	"github.com/networkservicemesh/sdk/pkg/tools/matchutils"
 )
 
 type queryCacheNSEClient struct {
-	ctx   context.Context
-	cache *cache
+	cache   *cache[*registry.NetworkServiceEndpoint]
+	watcher *watcher[*registry.NetworkServiceEndpointResponse]
 }
 
-// NewClient creates new querycache NSE registry client that caches all resolved NSEs
+// NewClient creates new querycache NSE registry client that caches all resolved NSEs. Each of the found NSEs is cached
+// by its name and is kept fresh by its own watch Find sent to the next client. With the
+// WithNetworkServiceEndpointWatchClient client the non-watch Find results are cached by query instead and are kept
+// fresh by a single watch stream shared by all the queries. The watch is started from ctx, the results found while
+// the watch stream is not open are not cached. The cached result expires if it is not found for the expire timeout.
 func NewClient(ctx context.Context, opts ...Option) registry.NetworkServiceEndpointRegistryClient {
-	return &queryCacheNSEClient{
-		ctx:   ctx,
-		cache: newCache(ctx, opts...),
+	o := newOptions(opts...)
+	c := newCache(ctx, o,
+		matchutils.MatchNetworkServiceEndpoints,
+		(*registry.NetworkServiceEndpoint).GetName,
+		func(name string) *registry.NetworkServiceEndpoint {
+			return &registry.NetworkServiceEndpoint{Name: name}
+		},
+	)
+	q := &queryCacheNSEClient{cache: c}
+	if o.nseWatchClient != nil {
+		q.watcher = startWatcher(ctx, o.retryInterval,
+			func(nseResp *registry.NetworkServiceEndpointResponse) {
+				c.Update(nseResp.GetNetworkServiceEndpoint(), nseResp.GetDeleted())
+			},
+			c.Invalidate,
+			func(watchCtx context.Context, resume uint64) (recvStream[*registry.NetworkServiceEndpointResponse], error) {
+				watchQuery := &registry.NetworkServiceEndpointQuery{
+					NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+					Watch:                  true,
+				}
+				revision.Set(watchQuery, resume)
+				return o.nseWatchClient.Find(watchCtx, watchQuery, o.watchCallOptions...)
+			},
+		)
 	}
+	return q
 }
 
 func (q *queryCacheNSEClient) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*registry.NetworkServiceEndpoint, error) {
@@ -47,82 +77,64 @@ func (q *queryCacheNSEClient) Register(ctx context.Context, nse *registry.Networ
 }
 
 func (q *queryCacheNSEClient) Find(ctx context.Context, query *registry.NetworkServiceEndpointQuery, opts ...grpc.CallOption) (registry.NetworkServiceEndpointRegistry_FindClient, error) {
-	if query.Watch {
+	if query.Watch || query.GetNetworkServiceEndpoint() == nil || !isCacheable(query) {
 		return next.NetworkServiceEndpointRegistryClient(ctx).Find(ctx, query, opts...)
 	}
 
-	if client, ok := q.findInCache(ctx, query.String()); ok {
-		return client, nil
+	if nses, ok := q.cache.Load(query.GetNetworkServiceEndpoint()); ok {
+		return q.newFindClient(ctx, nses), nil
+	}
+
+	if q.watcher != nil && !q.watcher.isEstablished() {
+		// The result can't be kept fresh, so it is not cached
+		return next.NetworkServiceEndpointRegistryClient(ctx).Find(ctx, query, opts...)
 	}
 
+	since := q.cache.Version()
 	client, err := next.NetworkServiceEndpointRegistryClient(ctx).Find(ctx, query, opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	nses := registry.ReadNetworkServiceEndpointList(client)
-
-	resultCh := make(chan *registry.NetworkServiceEndpointResponse, len(nses))
-	for _, nse := range nses {
-		resultCh <- &registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: nse}
-		q.storeInCache(ctx, nse.Clone(), opts...)
-	}
-	close(resultCh)
-
-	return streamchannel.NewNetworkServiceEndpointFindClient(ctx, resultCh), nil
-}
-
-func (q *queryCacheNSEClient) findInCache(ctx context.Context, key string) (registry.NetworkServiceEndpointRegistry_FindClient, bool) {
-	nse, ok := q.cache.Load(key)
-	if !ok {
-		return nil, false
+	if q.watcher != nil {
+		q.cache.Store(query.GetNetworkServiceEndpoint(), nses, since)
+	} else {
+		nextClient := next.NetworkServiceEndpointRegistryClient(ctx)
+		q.cache.StoreByName(nses, since, func(watchCtx context.Context, name string) {
+			q.watch(watchCtx, nextClient, name, opts...)
+		})
 	}
 
-	resultCh := make(chan *registry.NetworkServiceEndpointResponse, 1)
-	resultCh <- &registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: nse.Clone()}
-	close(resultCh)
-
-	return streamchannel.NewNetworkServiceEndpointFindClient(ctx, resultCh), true
+	return q.newFindClient(ctx, nses), nil
 }
 
-func (q *queryCacheNSEClient) storeInCache(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) {
-	nseQuery := &registry.NetworkServiceEndpointQuery{
-		NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{
-			Name: nse.Name,
-		},
-	}
-
-	key := nseQuery.String()
-
-	findCtx, cancel := context.WithCancel(q.ctx)
-
-	entry, loaded := q.cache.LoadOrStore(key, nse, cancel)
-	if loaded {
-		cancel()
+// watch applies the events for the NSE with the name to the cache until the watch stream breaks or ctx is done
+func (q *queryCacheNSEClient) watch(ctx context.Context, client registry.NetworkServiceEndpointRegistryClient, name string, opts ...grpc.CallOption) {
+	stream, err := client.Find(ctx, &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{Name: name},
+		Watch:                  true,
+	}, opts...)
+	if err != nil {
 		return
 	}
 
-	go func() {
-		defer entry.Cleanup()
-
-		nseQuery.Watch = true
-
-		stream, err := next.NetworkServiceEndpointRegistryClient(ctx).Find(findCtx, nseQuery, opts...)
-		if err != nil {
-			return
+	for nseResp, err := stream.Recv(); err == nil; nseResp, err = stream.Recv() {
+		if nseResp.GetNetworkServiceEndpoint().GetName() != name {
+			continue
 		}
+		q.cache.Update(nseResp.GetNetworkServiceEndpoint(), nseResp.GetDeleted())
+	}
+}
 
-		for nseResp, err := stream.Recv(); err == nil; nseResp, err = stream.Recv() {
-			if nseResp.NetworkServiceEndpoint.Name != nseQuery.NetworkServiceEndpoint.Name {
-				continue
-			}
-			if nseResp.Deleted {
-				break
-			}
+func (q *queryCacheNSEClient) newFindClient(ctx context.Context, nses []*registry.NetworkServiceEndpoint) registry.NetworkServiceEndpointRegistry_FindClient {
+	resultCh := make(chan *registry.NetworkServiceEndpointResponse, len(nses))
+	for _, nse := range nses {
+		resultCh <- &registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: nse}
+	}
+	close(resultCh)
 
-			entry.Update(nseResp.NetworkServiceEndpoint)
-		}
-	}()
+	return streamchannel.NewNetworkServiceEndpointFindClient(ctx, resultCh)
 }
 
 func (q *queryCacheNSEClient) Unregister(ctx context.Context, in *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*empty.Empty, error) {
@@ -1,5 +1,7 @@
 // Copyright (c) 2020-2021 Doc.ai and/or its affiliates.
 //
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
 // SPDX-License-Identifier: Apache-2.0
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -18,6 +20,8 @@ package querycache_test
 
 import (
 	"context"
+	"sort"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -33,6 +37,9 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/registry/common/querycache"
 	"github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
 	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/streamchannel"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/count"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/revision"
 	"github.com/networkservicemesh/sdk/pkg/tools/clock"
 	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
 )
@@ -208,3 +215,427 @@ func (c *failureNSEClient) Find(ctx context.Context, query *registry.NetworkServ
 func (c *failureNSEClient) Unregister(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*empty.Empty, error) {
 	return next.NetworkServiceEndpointRegistryClient(ctx).Unregister(ctx, nse, opts...)
 }
+
+// requireCached waits for the result of find to be cached: find succeeds with the registry Find failing
+func requireCached(t *testing.T, shouldFail *int32, find func() error) {
+	require.Eventually(t, func() bool {
+		atomic.StoreInt32(shouldFail, 0)
+		if find() != nil {
+			return false
+		}
+		atomic.StoreInt32(shouldFail, 1)
+		return find() == nil
+	}, testWait, testTick)
+}
+
+func testFind(ctx context.Context, c registry.NetworkServiceEndpointRegistryClient, query *registry.NetworkServiceEndpoint) ([]string, error) {
+	stream, err := c.Find(ctx, &registry.NetworkServiceEndpointQuery{NetworkServiceEndpoint: query})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, nse := range registry.ReadNetworkServiceEndpointList(stream) {
+		names = append(names, nse.GetName())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func Test_QueryCacheClient_ShouldCacheMultiResultQueries(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mem := memory.NewNetworkServiceEndpointRegistryServer()
+
+	counter := new(count.CallCounter)
+	failureClient := new(failureNSEClient)
+	c := next.NewNetworkServiceEndpointRegistryClient(
+		querycache.NewClient(ctx,
+			querycache.WithExpireTimeout(expireTimeout),
+			querycache.WithNetworkServiceEndpointWatchClient(adapters.NetworkServiceEndpointServerToClient(mem))),
+		count.NewNetworkServiceEndpointRegistryClient(counter),
+		failureClient,
+		adapters.NetworkServiceEndpointServerToClient(mem),
+	)
+
+	for _, nse := range []*registry.NetworkServiceEndpoint{
+		{Name: "nse-1", NetworkServiceNames: []string{"ns-1"}},
+		{Name: "nse-2", NetworkServiceNames: []string{"ns-1", "ns-2"}},
+	} {
+		_, err := mem.Register(ctx, nse)
+		require.NoError(t, err)
+	}
+
+	query := &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns-1"}}
+
+	// 1. Find from memory and cache
+	requireCached(t, &failureClient.shouldFail, func() error {
+		_, err := testFind(ctx, c, query)
+		return err
+	})
+	finds := counter.Finds()
+
+	// 2. Find from cache
+	names, err := testFind(ctx, c, query)
+	require.NoError(t, err)
+	require.Equal(t, []string{"nse-1", "nse-2"}, names)
+
+	names, err = testFind(ctx, c, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"nse-2"}, names)
+
+	// 3. Cached result is updated with the new matching NSE
+	_, err = mem.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-3", NetworkServiceNames: []string{"ns-1"}})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		names, err = testFind(ctx, c, query)
+		return err == nil && len(names) == 3
+	}, testWait, testTick)
+
+	// 4. Cached result is updated on NSE delete
+	_, err = mem.Unregister(ctx, &registry.NetworkServiceEndpoint{Name: "nse-1"})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		names, err = testFind(ctx, c, query)
+		return err == nil && len(names) == 2 && names[0] == "nse-2"
+	}, testWait, testTick)
+
+	// Cached Finds have not reached the registry
+	require.Equal(t, finds, counter.Finds())
+}
+
+func Test_QueryCacheClient_WatchClient_ShouldCleanUpOnTimeout(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	mem := memory.NewNetworkServiceEndpointRegistryServer()
+
+	failureClient := new(failureNSEClient)
+	c := next.NewNetworkServiceEndpointRegistryClient(
+		querycache.NewClient(ctx,
+			querycache.WithExpireTimeout(expireTimeout),
+			querycache.WithNetworkServiceEndpointWatchClient(adapters.NetworkServiceEndpointServerToClient(mem))),
+		failureClient,
+		adapters.NetworkServiceEndpointServerToClient(mem),
+	)
+
+	_, err := mem.Register(ctx, &registry.NetworkServiceEndpoint{Name: name, NetworkServiceNames: []string{"ns"}})
+	require.NoError(t, err)
+
+	query := &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns"}}
+
+	// 1. Find from memory and cache
+	requireCached(t, &failureClient.shouldFail, func() error {
+		_, err := testFind(ctx, c, query)
+		return err
+	})
+
+	// 2. Keep finding from cache to prevent expiration
+	for start := clockMock.Now(); clockMock.Since(start) < 2*expireTimeout; clockMock.Add(expireTimeout / 3) {
+		names, err := testFind(ctx, c, query)
+		require.NoError(t, err)
+		require.Equal(t, []string{name}, names)
+	}
+
+	// 3. Wait for the expire to happen
+	clockMock.Add(expireTimeout)
+
+	_, err = testFind(ctx, c, query)
+	require.Error(t, err)
+}
+
+func Test_QueryCacheClient_ShouldNotCacheMultiResultQueriesWithoutWatchClient(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mem := memory.NewNetworkServiceEndpointRegistryServer()
+
+	failureClient := new(failureNSEClient)
+	c := next.NewNetworkServiceEndpointRegistryClient(
+		querycache.NewClient(ctx, querycache.WithExpireTimeout(expireTimeout)),
+		failureClient,
+		adapters.NetworkServiceEndpointServerToClient(mem),
+	)
+
+	_, err := mem.Register(ctx, &registry.NetworkServiceEndpoint{Name: name, NetworkServiceNames: []string{"ns"}})
+	require.NoError(t, err)
+
+	query := &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns"}}
+
+	// 1. Find from memory
+	names, err := testFind(ctx, c, query)
+	require.NoError(t, err)
+	require.Equal(t, []string{name}, names)
+
+	// 2. Found NSE is cached by its name
+	atomic.StoreInt32(&failureClient.shouldFail, 1)
+
+	names, err = testFind(ctx, c, &registry.NetworkServiceEndpoint{Name: name})
+	require.NoError(t, err)
+	require.Equal(t, []string{name}, names)
+
+	// 3. The query result itself can't be kept fresh with the new matching NSEs, so it is not cached
+	_, err = testFind(ctx, c, query)
+	require.Error(t, err)
+}
+
+func Test_QueryCacheClient_ShouldResumeBrokenWatch(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mem := memory.NewNetworkServiceEndpointRegistryServer()
+
+	failureClient := new(failureNSEClient)
+	breakingClient := new(breakingWatchNSEClient)
+	c := next.NewNetworkServiceEndpointRegistryClient(
+		querycache.NewClient(ctx,
+			querycache.WithWatchRetryInterval(testTick),
+			querycache.WithNetworkServiceEndpointWatchClient(next.NewNetworkServiceEndpointRegistryClient(
+				breakingClient,
+				adapters.NetworkServiceEndpointServerToClient(mem),
+			))),
+		failureClient,
+		adapters.NetworkServiceEndpointServerToClient(mem),
+	)
+
+	_, err := mem.Register(ctx, &registry.NetworkServiceEndpoint{Name: name, Url: url1})
+	require.NoError(t, err)
+
+	requireCached(t, &failureClient.shouldFail, func() error {
+		_, err := testFind(ctx, c, &registry.NetworkServiceEndpoint{Name: name})
+		return err
+	})
+	require.Eventually(t, func() bool {
+		return breakingClient.lastResume() != 0
+	}, testWait, testTick)
+
+	// 1. Break the watch stream and update NSE while the stream is broken
+	breakingClient.breakWatch()
+
+	_, err = mem.Register(ctx, &registry.NetworkServiceEndpoint{Name: name, Url: url2})
+	require.NoError(t, err)
+
+	// 2. Watch stream is resumed, the cache is not invalidated and gets the missed update
+	require.Eventually(t, func() bool {
+		stream, err := c.Find(ctx, testNSEQuery(name))
+		if err != nil {
+			return false
+		}
+		nseResp, err := stream.Recv()
+		return err == nil && nseResp.GetNetworkServiceEndpoint().GetUrl() == url2
+	}, testWait, testTick)
+	require.Equal(t, 2, breakingClient.watches())
+}
+
+type breakingWatchNSEClient struct {
+	lock       sync.Mutex
+	cancel     context.CancelFunc
+	resume     uint64
+	watchCount int
+}
+
+func (c *breakingWatchNSEClient) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*registry.NetworkServiceEndpoint, error) {
+	return next.NetworkServiceEndpointRegistryClient(ctx).Register(ctx, nse, opts...)
+}
+
+func (c *breakingWatchNSEClient) Find(ctx context.Context, query *registry.NetworkServiceEndpointQuery, opts ...grpc.CallOption) (registry.NetworkServiceEndpointRegistry_FindClient, error) {
+	if !query.Watch {
+		return next.NetworkServiceEndpointRegistryClient(ctx).Find(ctx, query, opts...)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.watchCount++
+
+	stream, err := next.NetworkServiceEndpointRegistryClient(ctx).Find(ctx, query, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &resumeTrackingStream{NetworkServiceEndpointRegistry_FindClient: stream, client: c}, nil
+}
+
+func (c *breakingWatchNSEClient) Unregister(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.NetworkServiceEndpointRegistryClient(ctx).Unregister(ctx, nse, opts...)
+}
+
+func (c *breakingWatchNSEClient) breakWatch() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.cancel()
+}
+
+func (c *breakingWatchNSEClient) lastResume() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.resume
+}
+
+func (c *breakingWatchNSEClient) watches() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.watchCount
+}
+
+type resumeTrackingStream struct {
+	registry.NetworkServiceEndpointRegistry_FindClient
+	client *breakingWatchNSEClient
+}
+
+func (s *resumeTrackingStream) Recv() (*registry.NetworkServiceEndpointResponse, error) {
+	nseResp, err := s.NetworkServiceEndpointRegistry_FindClient.Recv()
+	if err == nil {
+		if rev, ok := revision.Get(nseResp); ok {
+			s.client.lock.Lock()
+			s.client.resume = rev
+			s.client.lock.Unlock()
+		}
+	}
+	return nseResp, err
+}
+
+func Test_QueryCacheClient_ShouldNotCacheDeletedDuringFind(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mem := memory.NewNetworkServiceEndpointRegistryServer()
+
+	watchClient := &deleteAppliedNSEClient{deleteApplied: make(chan struct{})}
+	deletingClient := &deletingNSEClient{mem: mem, deleteApplied: watchClient.deleteApplied}
+	failureClient := new(failureNSEClient)
+	c := next.NewNetworkServiceEndpointRegistryClient(
+		querycache.NewClient(ctx,
+			querycache.WithExpireTimeout(expireTimeout),
+			querycache.WithNetworkServiceEndpointWatchClient(next.NewNetworkServiceEndpointRegistryClient(
+				watchClient,
+				adapters.NetworkServiceEndpointServerToClient(mem),
+			))),
+		failureClient,
+		deletingClient,
+		adapters.NetworkServiceEndpointServerToClient(mem),
+	)
+
+	for _, nseName := range []string{"nse-1", "nse-2"} {
+		_, err := mem.Register(ctx, &registry.NetworkServiceEndpoint{Name: nseName, NetworkServiceNames: []string{"ns"}})
+		require.NoError(t, err)
+	}
+
+	requireCached(t, &failureClient.shouldFail, func() error {
+		_, err := testFind(ctx, c, &registry.NetworkServiceEndpoint{Name: "nse-1"})
+		return err
+	})
+
+	// 1. nse-2 is deleted and the delete event is applied to the cache before the found result is stored
+	atomic.StoreInt32(&failureClient.shouldFail, 0)
+	atomic.StoreInt32(&deletingClient.deleteNext, 1)
+
+	names, err := testFind(ctx, c, &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"nse-1", "nse-2"}, names)
+
+	// 2. The cached result doesn't have nse-2
+	atomic.StoreInt32(&failureClient.shouldFail, 1)
+
+	names, err = testFind(ctx, c, &registry.NetworkServiceEndpoint{NetworkServiceNames: []string{"ns"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"nse-1"}, names)
+
+	_, err = testFind(ctx, c, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.Error(t, err)
+}
+
+// deletingNSEClient deletes nse-2 after reading the Find result if deleteNext is set
+type deletingNSEClient struct {
+	mem           registry.NetworkServiceEndpointRegistryServer
+	deleteNext    int32
+	deleteApplied <-chan struct{}
+}
+
+func (c *deletingNSEClient) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*registry.NetworkServiceEndpoint, error) {
+	return next.NetworkServiceEndpointRegistryClient(ctx).Register(ctx, nse, opts...)
+}
+
+func (c *deletingNSEClient) Find(ctx context.Context, query *registry.NetworkServiceEndpointQuery, opts ...grpc.CallOption) (registry.NetworkServiceEndpointRegistry_FindClient, error) {
+	stream, err := next.NetworkServiceEndpointRegistryClient(ctx).Find(ctx, query, opts...)
+	if err != nil || query.Watch || !atomic.CompareAndSwapInt32(&c.deleteNext, 1, 0) {
+		return stream, err
+	}
+
+	nses := registry.ReadNetworkServiceEndpointList(stream)
+	if _, err = c.mem.Unregister(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2"}); err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.deleteApplied:
+	}
+
+	resultCh := make(chan *registry.NetworkServiceEndpointResponse, len(nses))
+	for _, nse := range nses {
+		resultCh <- &registry.NetworkServiceEndpointResponse{NetworkServiceEndpoint: nse}
+	}
+	close(resultCh)
+	return streamchannel.NewNetworkServiceEndpointFindClient(ctx, resultCh), nil
+}
+
+func (c *deletingNSEClient) Unregister(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.NetworkServiceEndpointRegistryClient(ctx).Unregister(ctx, nse, opts...)
+}
+
+// deleteAppliedNSEClient closes deleteApplied once the first delete event is received and applied: the watcher calls
+// Recv again only after applying the previous event
+type deleteAppliedNSEClient struct {
+	deleteApplied chan struct{}
+}
+
+func (c *deleteAppliedNSEClient) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*registry.NetworkServiceEndpoint, error) {
+	return next.NetworkServiceEndpointRegistryClient(ctx).Register(ctx, nse, opts...)
+}
+
+func (c *deleteAppliedNSEClient) Find(ctx context.Context, query *registry.NetworkServiceEndpointQuery, opts ...grpc.CallOption) (registry.NetworkServiceEndpointRegistry_FindClient, error) {
+	stream, err := next.NetworkServiceEndpointRegistryClient(ctx).Find(ctx, query, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &deleteAppliedStream{NetworkServiceEndpointRegistry_FindClient: stream, client: c}, nil
+}
+
+func (c *deleteAppliedNSEClient) Unregister(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.NetworkServiceEndpointRegistryClient(ctx).Unregister(ctx, nse, opts...)
+}
+
+type deleteAppliedStream struct {
+	registry.NetworkServiceEndpointRegistry_FindClient
+	client  *deleteAppliedNSEClient
+	deleted bool
+}
+
+func (s *deleteAppliedStream) Recv() (*registry.NetworkServiceEndpointResponse, error) {
+	if s.deleted {
+		s.deleted = false
+		close(s.client.deleteApplied)
+	}
+	nseResp, err := s.NetworkServiceEndpointRegistry_FindClient.Recv()
+	s.deleted = err == nil && nseResp.GetDeleted()
+	return nseResp, err
+}
@@ -1,5 +1,7 @@
 // Copyright (c) 2021 Doc.ai and/or its affiliates.
 //
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
 // SPDX-License-Identifier: Apache-2.0
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -16,14 +18,73 @@
 
 package querycache
 
-import "time"
+import (
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+type options struct {
+	expireTimeout    time.Duration
+	retryInterval    time.Duration
+	nseWatchClient   registry.NetworkServiceEndpointRegistryClient
+	nsWatchClient    registry.NetworkServiceRegistryClient
+	watchCallOptions []grpc.CallOption
+}
 
 // Option is an option for cache
-type Option func(c *cache)
+type Option func(o *options)
 
 // WithExpireTimeout sets cache expire timeout
 func WithExpireTimeout(expireTimeout time.Duration) Option {
-	return func(c *cache) {
-		c.expireTimeout = expireTimeout
+	return func(o *options) {
+		o.expireTimeout = expireTimeout
+	}
+}
+
+// WithWatchRetryInterval sets the interval between the attempts to restore the broken watch stream keeping the cache
+// fresh
+func WithWatchRetryInterval(retryInterval time.Duration) Option {
+	return func(o *options) {
+		o.retryInterval = retryInterval
+	}
+}
+
+// WithNetworkServiceEndpointWatchClient sets the client used by NewClient to watch all the registry NSEs with a single
+// stream. The watch is started on NewClient and is kept until its ctx is done. Without the watch client each of the
+// cached NSEs is watched separately and the results of the queries not by the name only are not cached.
+func WithNetworkServiceEndpointWatchClient(c registry.NetworkServiceEndpointRegistryClient) Option {
+	return func(o *options) {
+		o.nseWatchClient = c
+	}
+}
+
+// WithNetworkServiceWatchClient sets the client used by NewNetworkServiceRegistryClient to watch all the registry NSs
+// with a single stream. The watch is started on NewNetworkServiceRegistryClient and is kept until its ctx is done.
+// Without the watch client each of the cached NSs is watched separately and the results of the queries not by the name
+// only are not cached.
+func WithNetworkServiceWatchClient(c registry.NetworkServiceRegistryClient) Option {
+	return func(o *options) {
+		o.nsWatchClient = c
+	}
+}
+
+// WithWatchCallOptions sets the call options for the watch Find
+func WithWatchCallOptions(opts ...grpc.CallOption) Option {
+	return func(o *options) {
+		o.watchCallOptions = opts
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		expireTimeout: time.Minute,
+		retryInterval: time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
 	}
+	return o
 }
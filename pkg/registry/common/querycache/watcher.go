@@ -0,0 +1,123 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querycache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/revision"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type recvStream[R proto.Message] interface {
+	Recv() (R, error)
+}
+
+// watcher runs a single watch stream shared by all the cached results. If the stream breaks, it is restored resuming
+// from the last received revision, so the registry sends only the missed events. If the registry can't resume the
+// stream, the cache is invalidated.
+type watcher[R proto.Message] struct {
+	ctx           context.Context
+	retryInterval time.Duration
+	// apply applies the event to the cache
+	apply func(resp R)
+	// invalidate invalidates the cache
+	invalidate func()
+	// established is true while the watch stream is open
+	established atomic.Bool
+}
+
+// startWatcher starts the watch stream until ctx is done. find should start the watch Find resuming from the revision.
+func startWatcher[R proto.Message](ctx context.Context, retryInterval time.Duration, apply func(resp R), invalidate func(),
+	find func(ctx context.Context, resume uint64) (recvStream[R], error)) *watcher[R] {
+	w := &watcher[R]{
+		ctx:           ctx,
+		retryInterval: retryInterval,
+		apply:         apply,
+		invalidate:    invalidate,
+	}
+	go w.run(find)
+	return w
+}
+
+// isEstablished returns true if the watch stream is open, so the results found now will be kept fresh. nil watcher is
+// never established.
+func (w *watcher[R]) isEstablished() bool {
+	return w != nil && w.established.Load()
+}
+
+func (w *watcher[R]) run(find func(ctx context.Context, resume uint64) (recvStream[R], error)) {
+	logger := log.FromContext(w.ctx).WithField("querycache", "watcher")
+	clockTime := clock.FromContext(w.ctx)
+
+	var lastRevision uint64
+	for {
+		stream, err := find(w.ctx, lastRevision)
+		if err == nil {
+			w.established.Store(true)
+			lastRevision, err = w.receive(stream, lastRevision)
+			w.established.Store(false)
+		}
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		if status.Code(errors.Cause(err)) == codes.OutOfRange {
+			// The missed events are lost, so start from scratch
+			w.invalidate()
+			lastRevision = 0
+			continue
+		}
+		logger.Warnf("watch stream is broken, last revision %d: %v", lastRevision, err)
+		if lastRevision == 0 {
+			// The events can't be resumed, so the cached results can become stale
+			w.invalidate()
+		}
+
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-clockTime.After(w.retryInterval):
+		}
+	}
+}
+
+// receive applies the events from the stream until it breaks and returns the last received revision
+func (w *watcher[R]) receive(stream recvStream[R], lastRevision uint64) (uint64, error) {
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return lastRevision, err
+		}
+
+		if rev, ok := revision.Get(resp); !ok {
+			lastRevision = 0
+		} else if rev > lastRevision {
+			lastRevision = rev
+		}
+
+		w.apply(resp)
+	}
+}
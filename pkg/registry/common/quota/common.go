@@ -0,0 +1,174 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package quota provides registry server chain elements limiting the number of live NSE and NS registrations per
+// spiffe ID of the registering client and the number of live NSEs per network service. Registrations beyond the
+// limits are rejected with ResourceExhausted. The counts are released on Unregister, including the one done on the
+// NSE expiration, so the elements should be placed after expire.
+package quota
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
+)
+
+type registration struct {
+	id              string
+	networkServices []string
+	restored        bool
+}
+
+type counters struct {
+	opts                  *options
+	mu                    sync.Mutex
+	registrations         map[string]*registration
+	countByID             map[string]int
+	countByNetworkService map[string]int
+}
+
+func newCounters(opts *options) *counters {
+	return &counters{
+		opts:                  opts,
+		registrations:         make(map[string]*registration),
+		countByID:             make(map[string]int),
+		countByNetworkService: make(map[string]int),
+	}
+}
+
+// acquire replaces the registration with the given name with the new one if the limits allow it. It returns the
+// replaced registration to restore it with the rollback if the registration fails.
+func (c *counters) acquire(name string, reg *registration) (prev *registration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev = c.registrations[name]
+	c.remove(prev)
+
+	if limit := c.opts.limitForID(reg.id); limit > 0 && c.countByID[reg.id] >= limit {
+		c.add(prev)
+		return nil, status.Errorf(codes.ResourceExhausted, "quota exceeded: %s has %d live registrations of %d allowed", idString(reg.id), c.countByID[reg.id], limit)
+	}
+	if limit := c.opts.limitPerNetworkService; limit > 0 {
+		for _, ns := range reg.networkServices {
+			if c.countByNetworkService[ns] >= limit {
+				c.add(prev)
+				return nil, status.Errorf(codes.ResourceExhausted, "quota exceeded: network service %s has %d live endpoints of %d allowed", ns, c.countByNetworkService[ns], limit)
+			}
+		}
+	}
+
+	c.add(reg)
+	c.registrations[name] = reg
+	return prev, nil
+}
+
+// rollback restores the registration replaced by acquire
+func (c *counters) rollback(name string, prev *registration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(c.registrations[name])
+	delete(c.registrations, name)
+	if prev != nil {
+		c.add(prev)
+		c.registrations[name] = prev
+	}
+}
+
+func (c *counters) release(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(c.registrations[name])
+	delete(c.registrations, name)
+}
+
+// restore counts the registration restored by the registry unless the name is already registered. Restored
+// registrations are counted even if they exceed the limits.
+func (c *counters) restore(name string, reg *registration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.registrations[name]; ok {
+		return
+	}
+	reg.restored = true
+	c.add(reg)
+	c.registrations[name] = reg
+}
+
+// releaseRestored releases the registration only if it is still the restored one
+func (c *counters) releaseRestored(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if reg := c.registrations[name]; reg != nil && reg.restored {
+		c.remove(reg)
+		delete(c.registrations, name)
+	}
+}
+
+func (c *counters) add(reg *registration) {
+	if reg == nil {
+		return
+	}
+	c.countByID[reg.id]++
+	for _, ns := range reg.networkServices {
+		c.countByNetworkService[ns]++
+	}
+}
+
+func (c *counters) remove(reg *registration) {
+	if reg == nil {
+		return
+	}
+	if c.countByID[reg.id]--; c.countByID[reg.id] == 0 {
+		delete(c.countByID, reg.id)
+	}
+	for _, ns := range reg.networkServices {
+		if c.countByNetworkService[ns]--; c.countByNetworkService[ns] == 0 {
+			delete(c.countByNetworkService, ns)
+		}
+	}
+}
+
+// spiffeIDFromContext returns spiffe ID of the client started the registration or "" if it is unknown
+func spiffeIDFromContext(ctx context.Context) string {
+	if id := grpcmetadata.SpiffeIDFromPath(ctx, grpcmetadata.PathFromContext(ctx)); !id.IsZero() {
+		return id.String()
+	}
+	return ""
+}
+
+// registrantID returns the spiffe ID of the registrant stored in the path IDs of the restored entity
+func registrantID(pathIDs []string) string {
+	if len(pathIDs) == 0 {
+		return ""
+	}
+	return pathIDs[0]
+}
+
+func idString(id string) string {
+	if id == "" {
+		return "unknown spiffe ID"
+	}
+	return "spiffe ID " + id
+}
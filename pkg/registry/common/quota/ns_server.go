@@ -0,0 +1,90 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type quotaNSServer struct {
+	counters *counters
+}
+
+// NewNetworkServiceRegistryServer creates a new NetworkServiceRegistryServer chain element limiting the number of
+// live NSs per spiffe ID
+func NewNetworkServiceRegistryServer(opts ...Option) registry.NetworkServiceRegistryServer {
+	o := newOptions(opts...)
+	s := &quotaNSServer{
+		counters: newCounters(o),
+	}
+	if o.localNSClient != nil {
+		s.restore(o.ctx, o.localNSClient)
+	}
+	return s
+}
+
+// restore counts the NSs restored by the registry
+func (s *quotaNSServer) restore(ctx context.Context, c registry.NetworkServiceRegistryClient) {
+	stream, err := c.Find(ctx, &registry.NetworkServiceQuery{
+		NetworkService: new(registry.NetworkService),
+	})
+	if err != nil {
+		log.FromContext(ctx).WithField("quotaNSServer", "restore").Errorf("failed to find the local NSs: %s", err.Error())
+		return
+	}
+	for _, ns := range registry.ReadNetworkServiceList(stream) {
+		s.counters.restore(ns.GetName(), &registration{
+			id: registrantID(ns.GetPathIds()),
+		})
+	}
+}
+
+func (s *quotaNSServer) Register(ctx context.Context, ns *registry.NetworkService) (*registry.NetworkService, error) {
+	prev, err := s.counters.acquire(ns.GetName(), &registration{
+		id: spiffeIDFromContext(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := next.NetworkServiceRegistryServer(ctx).Register(ctx, ns)
+	if err != nil {
+		s.counters.rollback(ns.GetName(), prev)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *quotaNSServer) Find(query *registry.NetworkServiceQuery, server registry.NetworkServiceRegistry_FindServer) error {
+	return next.NetworkServiceRegistryServer(server.Context()).Find(query, server)
+}
+
+func (s *quotaNSServer) Unregister(ctx context.Context, ns *registry.NetworkService) (*empty.Empty, error) {
+	resp, err := next.NetworkServiceRegistryServer(ctx).Unregister(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+	s.counters.release(ns.GetName())
+	return resp, nil
+}
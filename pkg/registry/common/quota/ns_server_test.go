@@ -0,0 +1,81 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/quota"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/inject/injecterror"
+)
+
+func TestQuotaNSServer_LimitPerID(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceRegistryServer(
+		quota.NewNetworkServiceRegistryServer(quota.WithLimitPerID(1)),
+		memory.NewNetworkServiceRegistryServer(),
+	)
+
+	teamCtx := withSpiffeID(context.Background(), t, "spiffe://test.com/team")
+	otherTeamCtx := withSpiffeID(context.Background(), t, "spiffe://test.com/other-team")
+
+	ns1, err := s.Register(teamCtx, &registry.NetworkService{Name: "ns-1"})
+	require.NoError(t, err)
+
+	_, err = s.Register(teamCtx, &registry.NetworkService{Name: "ns-2"})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = s.Register(otherTeamCtx, &registry.NetworkService{Name: "ns-2"})
+	require.NoError(t, err)
+
+	_, err = s.Unregister(teamCtx, ns1)
+	require.NoError(t, err)
+
+	_, err = s.Register(teamCtx, &registry.NetworkService{Name: "ns-3"})
+	require.NoError(t, err)
+}
+
+func TestQuotaNSServer_RollbackOnError(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceRegistryServer(
+		quota.NewNetworkServiceRegistryServer(quota.WithLimitPerID(1)),
+		injecterror.NewNetworkServiceRegistryServer(
+			injecterror.WithRegisterErrorTimes(0),
+			injecterror.WithError(errors.New("failed")),
+		),
+	)
+	ctx := withSpiffeID(context.Background(), t, "spiffe://test.com/team")
+
+	_, err := s.Register(ctx, &registry.NetworkService{Name: "ns-1"})
+	require.Error(t, err)
+
+	_, err = s.Register(ctx, &registry.NetworkService{Name: "ns-2"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type quotaNSEServer struct {
+	counters *counters
+}
+
+// NewNetworkServiceEndpointRegistryServer creates a new NetworkServiceEndpointRegistryServer chain element limiting
+// the number of live NSEs per spiffe ID and per network service
+func NewNetworkServiceEndpointRegistryServer(opts ...Option) registry.NetworkServiceEndpointRegistryServer {
+	o := newOptions(opts...)
+	s := &quotaNSEServer{
+		counters: newCounters(o),
+	}
+	if o.localNSEClient != nil {
+		s.restore(o.ctx, o.localNSEClient)
+	}
+	return s
+}
+
+// restore counts the NSEs restored by the registry. Restored NSE is released on its expiration time unless it is
+// registered again, as the registry expires it without calling the chain.
+func (s *quotaNSEServer) restore(ctx context.Context, c registry.NetworkServiceEndpointRegistryClient) {
+	stream, err := c.Find(ctx, &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+	})
+	if err != nil {
+		log.FromContext(ctx).WithField("quotaNSEServer", "restore").Errorf("failed to find the local NSEs: %s", err.Error())
+		return
+	}
+
+	timeClock := clock.FromContext(ctx)
+	for _, nse := range registry.ReadNetworkServiceEndpointList(stream) {
+		name := nse.GetName()
+		s.counters.restore(name, &registration{
+			id:              registrantID(nse.GetPathIds()),
+			networkServices: nse.GetNetworkServiceNames(),
+		})
+		if nse.GetExpirationTime() == nil {
+			continue
+		}
+		timeClock.AfterFunc(timeClock.Until(nse.GetExpirationTime().AsTime()), func() {
+			if ctx.Err() == nil {
+				s.counters.releaseRestored(name)
+			}
+		})
+	}
+}
+
+func (s *quotaNSEServer) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, error) {
+	prev, err := s.counters.acquire(nse.GetName(), &registration{
+		id:              spiffeIDFromContext(ctx),
+		networkServices: nse.GetNetworkServiceNames(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := next.NetworkServiceEndpointRegistryServer(ctx).Register(ctx, nse)
+	if err != nil {
+		s.counters.rollback(nse.GetName(), prev)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *quotaNSEServer) Find(query *registry.NetworkServiceEndpointQuery, server registry.NetworkServiceEndpointRegistry_FindServer) error {
+	return next.NetworkServiceEndpointRegistryServer(server.Context()).Find(query, server)
+}
+
+func (s *quotaNSEServer) Unregister(ctx context.Context, nse *registry.NetworkServiceEndpoint) (*empty.Empty, error) {
+	resp, err := next.NetworkServiceEndpointRegistryServer(ctx).Unregister(ctx, nse)
+	if err != nil {
+		return nil, err
+	}
+	s.counters.release(nse.GetName())
+	return resp, nil
+}
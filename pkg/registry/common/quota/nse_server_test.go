@@ -0,0 +1,227 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/begin"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/expire"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/quota"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/inject/injecterror"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
+	"github.com/networkservicemesh/sdk/pkg/tools/filestore"
+)
+
+func withSpiffeID(ctx context.Context, t *testing.T, spiffeID string) context.Context {
+	tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: spiffeID}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	return grpcmetadata.PathWithContext(ctx, &grpcmetadata.Path{
+		PathSegments: []*grpcmetadata.PathSegment{{Token: tok}},
+	})
+}
+
+func TestQuotaNSEServer_LimitPerID(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceEndpointRegistryServer(
+		quota.NewNetworkServiceEndpointRegistryServer(
+			quota.WithLimitPerID(2),
+			quota.WithLimitForID("spiffe://test.com/big-team", 3),
+		),
+		memory.NewNetworkServiceEndpointRegistryServer(),
+	)
+
+	teamCtx := withSpiffeID(context.Background(), t, "spiffe://test.com/team")
+	bigTeamCtx := withSpiffeID(context.Background(), t, "spiffe://test.com/big-team")
+
+	nse1, err := s.Register(teamCtx, &registry.NetworkServiceEndpoint{Name: "nse-1"})
+	require.NoError(t, err)
+	_, err = s.Register(teamCtx, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.NoError(t, err)
+
+	// Refresh doesn't take more quota
+	_, err = s.Register(teamCtx, nse1.Clone())
+	require.NoError(t, err)
+
+	_, err = s.Register(teamCtx, &registry.NetworkServiceEndpoint{Name: "nse-3"})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	for _, name := range []string{"nse-4", "nse-5", "nse-6"} {
+		_, err = s.Register(bigTeamCtx, &registry.NetworkServiceEndpoint{Name: name})
+		require.NoError(t, err)
+	}
+	_, err = s.Register(bigTeamCtx, &registry.NetworkServiceEndpoint{Name: "nse-7"})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = s.Unregister(teamCtx, nse1)
+	require.NoError(t, err)
+
+	_, err = s.Register(teamCtx, &registry.NetworkServiceEndpoint{Name: "nse-3"})
+	require.NoError(t, err)
+}
+
+func TestQuotaNSEServer_LimitPerNetworkService(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceEndpointRegistryServer(
+		quota.NewNetworkServiceEndpointRegistryServer(quota.WithLimitPerNetworkService(1)),
+		memory.NewNetworkServiceEndpointRegistryServer(),
+	)
+	ctx := withSpiffeID(context.Background(), t, "spiffe://test.com/team")
+
+	nse1, err := s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-1", NetworkServiceNames: []string{"ns-1"}})
+	require.NoError(t, err)
+
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2", NetworkServiceNames: []string{"ns-2", "ns-1"}})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Rejected registration doesn't take quota
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2", NetworkServiceNames: []string{"ns-2"}})
+	require.NoError(t, err)
+
+	// NSE can move to another network service
+	nse1.NetworkServiceNames = []string{"ns-3"}
+	_, err = s.Register(ctx, nse1)
+	require.NoError(t, err)
+
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-3", NetworkServiceNames: []string{"ns-1"}})
+	require.NoError(t, err)
+}
+
+func TestQuotaNSEServer_ReleaseOnExpire(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	const expireTimeout = time.Minute
+
+	mem := memory.NewNetworkServiceEndpointRegistryServer()
+	s := next.NewNetworkServiceEndpointRegistryServer(
+		begin.NewNetworkServiceEndpointRegistryServer(),
+		expire.NewNetworkServiceEndpointRegistryServer(ctx, expire.WithDefaultExpiration(expireTimeout)),
+		quota.NewNetworkServiceEndpointRegistryServer(quota.WithLimitPerID(1)),
+		mem,
+	)
+	ctx = withSpiffeID(ctx, t, "spiffe://test.com/team")
+
+	_, err := s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-1"})
+	require.NoError(t, err)
+
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	clockMock.Add(expireTimeout)
+	require.Eventually(t, func() bool {
+		_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+		return err == nil
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestQuotaNSEServer_KeepOnUnregisterError(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	s := next.NewNetworkServiceEndpointRegistryServer(
+		quota.NewNetworkServiceEndpointRegistryServer(quota.WithLimitPerID(1)),
+		injecterror.NewNetworkServiceEndpointRegistryServer(
+			injecterror.WithRegisterErrorTimes(),
+			injecterror.WithUnregisterErrorTimes(0),
+			injecterror.WithError(errors.New("failed")),
+		),
+	)
+	ctx := withSpiffeID(context.Background(), t, "spiffe://test.com/team")
+
+	nse1, err := s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-1"})
+	require.NoError(t, err)
+
+	_, err = s.Unregister(ctx, nse1)
+	require.Error(t, err)
+
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = s.Unregister(ctx, nse1)
+	require.NoError(t, err)
+
+	_, err = s.Register(ctx, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.NoError(t, err)
+}
+
+func TestQuotaNSEServer_RestoredRegistrations(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	storage, err := filestore.New(t.TempDir(), func() *registry.NetworkServiceEndpoint { return new(registry.NetworkServiceEndpoint) })
+	require.NoError(t, err)
+	defer func() { _ = storage.Close() }()
+
+	require.NoError(t, storage.Store(&registry.NetworkServiceEndpoint{
+		Name:           "nse-1",
+		PathIds:        []string{"spiffe://test.com/team"},
+		ExpirationTime: timestamppb.New(clockMock.Now().Add(time.Minute)),
+	}))
+
+	mem := memory.NewNetworkServiceEndpointRegistryServer(memory.WithNetworkServiceEndpointStorage(ctx, storage))
+	s := next.NewNetworkServiceEndpointRegistryServer(
+		quota.NewNetworkServiceEndpointRegistryServer(
+			quota.WithLimitPerID(1),
+			quota.WithLocalNetworkServiceEndpointRegistryClient(ctx, adapters.NetworkServiceEndpointServerToClient(mem)),
+		),
+		mem,
+	)
+	teamCtx := withSpiffeID(ctx, t, "spiffe://test.com/team")
+
+	_, err = s.Register(teamCtx, &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = s.Register(withSpiffeID(ctx, t, "spiffe://test.com/other-team"), &registry.NetworkServiceEndpoint{Name: "nse-2"})
+	require.NoError(t, err)
+
+	// Restored NSE expires
+	clockMock.Add(time.Minute)
+	require.Eventually(t, func() bool {
+		_, err = s.Register(teamCtx, &registry.NetworkServiceEndpoint{Name: "nse-3"})
+		return err == nil
+	}, time.Second, time.Millisecond*10)
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+type options struct {
+	limitPerID             int
+	limitsByID             map[string]int
+	limitPerNetworkService int
+	ctx                    context.Context
+	localNSEClient         registry.NetworkServiceEndpointRegistryClient
+	localNSClient          registry.NetworkServiceRegistryClient
+}
+
+// Option is an option pattern for quota chain elements
+type Option func(*options)
+
+// WithLimitPerID sets the max number of live registrations per spiffe ID. 0 means no limit. Default: 0.
+func WithLimitPerID(limit int) Option {
+	return func(o *options) {
+		o.limitPerID = limit
+	}
+}
+
+// WithLimitForID overrides the max number of live registrations for the given spiffe ID. 0 means no limit.
+func WithLimitForID(spiffeID string, limit int) Option {
+	return func(o *options) {
+		o.limitsByID[spiffeID] = limit
+	}
+}
+
+// WithLimitPerNetworkService sets the max number of live NSEs per network service. 0 means no limit. Default: 0.
+func WithLimitPerNetworkService(limit int) Option {
+	return func(o *options) {
+		o.limitPerNetworkService = limit
+	}
+}
+
+// WithLocalNetworkServiceEndpointRegistryClient sets the client finding the NSEs stored by the registry. The NSEs found
+// on creation are counted, so the NSEs restored by the registry after restart keep taking quota until they are
+// unregistered or expire. The restored NSEs stop expiring when ctx is done. Default: none.
+func WithLocalNetworkServiceEndpointRegistryClient(ctx context.Context, c registry.NetworkServiceEndpointRegistryClient) Option {
+	return func(o *options) {
+		o.ctx = ctx
+		o.localNSEClient = c
+	}
+}
+
+// WithLocalNetworkServiceRegistryClient sets the client finding the NSs stored by the registry. The NSs found on
+// creation are counted, so the NSs restored by the registry after restart keep taking quota until they are
+// unregistered. Default: none.
+func WithLocalNetworkServiceRegistryClient(ctx context.Context, c registry.NetworkServiceRegistryClient) Option {
+	return func(o *options) {
+		o.ctx = ctx
+		o.localNSClient = c
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		limitsByID: make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) limitForID(id string) int {
+	if limit, ok := o.limitsByID[id]; ok {
+		return limit
+	}
+	return o.limitPerID
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2022-2023 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -18,6 +18,7 @@ package refresh
 
 import (
 	"context"
+	"time"
 
 	"github.com/edwarnicke/genericsync"
 	"github.com/golang/protobuf/ptypes/empty"
@@ -28,19 +29,26 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/registry/common/begin"
 	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
 	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
 )
 
 type refreshNSEClient struct {
-	ctx context.Context
+	ctx  context.Context
+	opts *refreshOptions
 	genericsync.Map[string, context.CancelFunc]
 }
 
 // NewNetworkServiceEndpointRegistryClient creates new NetworkServiceEndpointRegistryClient that will refresh expiration
 // time for registered NSEs
-func NewNetworkServiceEndpointRegistryClient(ctx context.Context) registry.NetworkServiceEndpointRegistryClient {
-	return &refreshNSEClient{
-		ctx: ctx,
+func NewNetworkServiceEndpointRegistryClient(ctx context.Context, options ...Option) registry.NetworkServiceEndpointRegistryClient {
+	c := &refreshNSEClient{
+		ctx:  ctx,
+		opts: new(refreshOptions),
 	}
+	for _, opt := range options {
+		opt(c.opts)
+	}
+	return c
 }
 
 func (c *refreshNSEClient) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*registry.NetworkServiceEndpoint, error) {
@@ -63,14 +71,31 @@ func (c *refreshNSEClient) Register(ctx context.Context, nse *registry.NetworkSe
 	var clockTime = clock.FromContext(ctx)
 
 	if resp.GetExpirationTime() != nil {
-		var refreshCh = clockTime.After(2 * clockTime.Until(resp.GetExpirationTime().AsTime().Local()) / 3)
+		var name = nse.GetName()
+		var refreshCh = clockTime.After(c.opts.refreshAfter(clockTime.Until(resp.GetExpirationTime().AsTime().Local())))
 
 		go func() {
 			select {
 			case <-refreshCtx.Done():
 				return
 			case <-refreshCh:
-				<-factory.Register(begin.CancelContext(refreshCtx))
+			}
+			// On success the refreshed registration schedules the next refresh and cancels refreshCtx, so the retries
+			// only continue while the refresh keeps failing.
+			var backoff time.Duration
+			for {
+				err := <-factory.Register(begin.CancelContext(refreshCtx))
+				if err == nil || c.opts.initialBackoff <= 0 {
+					return
+				}
+				backoff = c.opts.retryAfter(backoff)
+				log.FromContext(refreshCtx).WithField("refreshNSEClient", "Register").
+					Warnf("failed to refresh NSE %s, retry in %s: %s", name, backoff, err.Error())
+				select {
+				case <-refreshCtx.Done():
+					return
+				case <-clockTime.After(c.opts.withJitter(backoff)):
+				}
 			}
 		}()
 	}
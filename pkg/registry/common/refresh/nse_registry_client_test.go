@@ -1,4 +1,4 @@
-// Copyright (c) 2022 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 	"google.golang.org/grpc"
@@ -300,6 +301,165 @@ func Test_RefreshNSEClient_CorrectInitialRegTime(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_RefreshNSEClient_RetriesWithBackoff(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	const backoff = time.Second
+
+	var registerCount int32
+	client := next.NewNetworkServiceEndpointRegistryClient(
+		begin.NewNetworkServiceEndpointRegistryClient(),
+		refresh.NewNetworkServiceEndpointRegistryClient(ctx, refresh.WithBackoff(backoff, 3*backoff)),
+		&injectNSERegisterClient{
+			NetworkServiceEndpointRegistryClient: null.NewNetworkServiceEndpointRegistryClient(),
+			register: func(ctx context.Context, nse *registry.NetworkServiceEndpoint, opts ...grpc.CallOption) (*registry.NetworkServiceEndpoint, error) {
+				// The first refresh and 3 retries fail
+				if count := atomic.AddInt32(&registerCount, 1); count > 1 && count <= 5 {
+					return nil, errors.New("registry is not available")
+				}
+				nse.ExpirationTime = timestamppb.New(clockMock.Now().Add(expireTimeout))
+				return next.NetworkServiceEndpointRegistryClient(ctx).Register(ctx, nse, opts...)
+			},
+		},
+	)
+
+	reg, err := client.Register(ctx, testNSE(clockMock))
+	require.NoError(t, err)
+
+	requireCount := func(expected int32) {
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&registerCount) == expected
+		}, testWait, testTick)
+		// Wait for the retry timer to be set
+		time.Sleep(testWait)
+	}
+
+	clockMock.Add(expireTimeout / 3 * 2)
+	requireCount(2)
+
+	// 1s, 2s, 3s, 3s retries
+	for i, interval := range []time.Duration{backoff, 2 * backoff, 3 * backoff, 3 * backoff} {
+		count := int32(i + 2)
+
+		clockMock.Add(interval - time.Millisecond)
+		require.Never(t, func() bool {
+			return atomic.LoadInt32(&registerCount) > count
+		}, testWait, testTick)
+
+		clockMock.Add(time.Millisecond)
+		requireCount(count + 1)
+	}
+
+	// Successful refresh stops the retries
+	clockMock.Add(3 * backoff)
+	require.Never(t, func() bool {
+		return atomic.LoadInt32(&registerCount) > 6
+	}, testWait, testTick)
+
+	_, err = client.Unregister(ctx, reg)
+	require.NoError(t, err)
+}
+
+func Test_RefreshNSEClient_Jitter(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	countClient := new(requestCountClient)
+	client := next.NewNetworkServiceEndpointRegistryClient(
+		begin.NewNetworkServiceEndpointRegistryClient(),
+		refresh.NewNetworkServiceEndpointRegistryClient(ctx, refresh.WithJitter(0.5)),
+		countClient,
+	)
+
+	const nsesCount = 20
+
+	var regs []*registry.NetworkServiceEndpoint
+	for i := 0; i < nsesCount; i++ {
+		nse := testNSE(clockMock)
+		nse.Name = fmt.Sprintf("nse-%d", i)
+		reg, err := client.Register(ctx, nse)
+		require.NoError(t, err)
+		regs = append(regs, reg)
+	}
+
+	// Refreshes are spread in [1/3, 2/3] of the expiration timeout
+	clockMock.Add(expireTimeout/3 - time.Millisecond)
+	require.Never(t, func() bool {
+		return atomic.LoadInt32(&countClient.requestCount) > nsesCount
+	}, testWait, testTick)
+
+	clockMock.Add(expireTimeout / 6)
+	require.Eventually(t, func() bool {
+		count := atomic.LoadInt32(&countClient.requestCount)
+		return count > nsesCount && count < 2*nsesCount
+	}, testWait, testTick)
+
+	clockMock.Add(expireTimeout / 6)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&countClient.requestCount) == 2*nsesCount
+	}, testWait, testTick)
+
+	for _, reg := range regs {
+		_, err := client.Unregister(ctx, reg)
+		require.NoError(t, err)
+	}
+}
+
+func Test_RefreshNSEClient_NearExpiry(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	var expireAfter atomic.Value
+	expireAfter.Store(expireTimeout)
+
+	countClient := new(requestCountClient)
+	client := next.NewNetworkServiceEndpointRegistryClient(
+		begin.NewNetworkServiceEndpointRegistryClient(),
+		refresh.NewNetworkServiceEndpointRegistryClient(ctx, refresh.WithNearExpiryThreshold(expireTimeout/2)),
+		countClient,
+		checknse.NewClient(t, func(t *testing.T, nse *registry.NetworkServiceEndpoint) {
+			nse.ExpirationTime = timestamppb.New(clockMock.Now().Add(expireAfter.Load().(time.Duration)))
+		}),
+	)
+
+	reg, err := client.Register(ctx, testNSE(clockMock))
+	require.NoError(t, err)
+
+	// The registry reports near expiry
+	expireAfter.Store(expireTimeout / 3)
+
+	clockMock.Add(expireTimeout / 3 * 2)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&countClient.requestCount) == 2
+	}, testWait, testTick)
+	time.Sleep(testWait)
+
+	// Refresh happens at 1/3 of the time left
+	clockMock.Add(expireTimeout / 9)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&countClient.requestCount) == 3
+	}, testWait, testTick)
+
+	_, err = client.Unregister(ctx, reg)
+	require.NoError(t, err)
+}
+
 type requestCountClient struct {
 	requestCount int32
 
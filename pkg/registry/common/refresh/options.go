@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refresh
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultRefreshFraction     = 2. / 3.
+	nearExpiryRefreshFraction  = 1. / 3.
+	defaultBackoffMultiplier   = 2
+	defaultMaxBackoffIntervals = 16
+)
+
+type refreshOptions struct {
+	jitter              float64
+	initialBackoff      time.Duration
+	maxBackoff          time.Duration
+	nearExpiryThreshold time.Duration
+}
+
+// Option is an option pattern for NewNetworkServiceEndpointRegistryClient
+type Option func(o *refreshOptions)
+
+// WithJitter sets the jitter fraction in [0, 1]: each refresh (and retry) is moved earlier by a random part of its
+// interval up to the jitter fraction of it, so the NSEs registered together don't refresh in lockstep.
+// By default there is no jitter.
+func WithJitter(jitter float64) Option {
+	return func(o *refreshOptions) {
+		switch {
+		case jitter < 0:
+			o.jitter = 0
+		case jitter > 1:
+			o.jitter = 1
+		default:
+			o.jitter = jitter
+		}
+	}
+}
+
+// WithBackoff enables retrying of the failed refreshes with exponential backoff: the first retry happens after
+// initial interval, each next interval is doubled but doesn't exceed max. If max is not positive, it is set to 16
+// initial intervals.
+// By default the failed refresh is not retried.
+func WithBackoff(initial, max time.Duration) Option {
+	return func(o *refreshOptions) {
+		o.initialBackoff = initial
+		o.maxBackoff = max
+		if o.maxBackoff <= 0 {
+			o.maxBackoff = initial * defaultMaxBackoffIntervals
+		}
+	}
+}
+
+// WithNearExpiryThreshold enables adaptive refresh: if the registry returns the registration expiring in less than
+// threshold, the next refresh happens at 1/3 of the time left instead of 2/3, leaving more time for the retries.
+// By default the refresh always happens at 2/3 of the time left.
+func WithNearExpiryThreshold(threshold time.Duration) Option {
+	return func(o *refreshOptions) {
+		o.nearExpiryThreshold = threshold
+	}
+}
+
+// refreshAfter returns the interval to the next refresh of the registration expiring after expireAfter
+func (o *refreshOptions) refreshAfter(expireAfter time.Duration) time.Duration {
+	fraction := defaultRefreshFraction
+	if expireAfter < o.nearExpiryThreshold {
+		fraction = nearExpiryRefreshFraction
+	}
+	return o.withJitter(time.Duration(float64(expireAfter) * fraction))
+}
+
+// retryAfter returns the interval to the next retry after the failed one with the previous interval
+func (o *refreshOptions) retryAfter(prev time.Duration) time.Duration {
+	next := o.initialBackoff
+	if prev > 0 {
+		next = prev * defaultBackoffMultiplier
+	}
+	if next > o.maxBackoff {
+		next = o.maxBackoff
+	}
+	return next
+}
+
+func (o *refreshOptions) withJitter(interval time.Duration) time.Duration {
+	if o.jitter == 0 {
+		return interval
+	}
+	// nolint:gosec
+	return interval - time.Duration(rand.Float64()*o.jitter*float64(interval))
+}
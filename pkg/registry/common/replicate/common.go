@@ -0,0 +1,256 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replicate provides registry server chain elements replicating Register/Unregister requests between
+// the peer registries, so multiple registry instances share the same view of the registered NSEs and NSs.
+//
+// Each registry replicates the requests it has processed itself to all of its peers, so the registries are expected
+// to form a full mesh. A replicated request is marked in its path: the segment of the replicating registry carries
+// the registry name. Requests with such a mark are applied locally but never replicated again, which prevents loops.
+// The mark is trusted only if the request comes directly from a peer registry authenticated by its spiffe ID, see
+// WithPeerSpiffeIDs. Marked requests from other peers are rejected.
+//
+// Conflicts between NSE registrations with the same name are resolved by the initial registration time set by
+// setregistrationtime: a replicated NSE older than the registered one is ignored. The failed replicated requests are
+// retried with a backoff. Each time the connection to a peer is established, the peer is resynced: all the NSEs and NSs
+// stored by the registry are registered to it, so a started or restarted peer gets the ones registered before. The
+// dropped Unregister requests are not resynced, the peer keeps such NSEs until they expire.
+package replicate
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/edwarnicke/serialize"
+	"github.com/pkg/errors"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type registrationTimeKey struct{}
+
+// replicaAuthenticator checks that the replicated requests come from the peer registries
+type replicaAuthenticator struct {
+	peerIDs    map[spiffeid.ID]struct{}
+	peerIDFunc func(ctx context.Context) (spiffeid.ID, error)
+}
+
+func newReplicaAuthenticator(o *options) *replicaAuthenticator {
+	a := &replicaAuthenticator{
+		peerIDs:    make(map[spiffeid.ID]struct{}, len(o.peerIDs)),
+		peerIDFunc: o.peerIDFunc,
+	}
+	for _, id := range o.peerIDs {
+		a.peerIDs[id] = struct{}{}
+	}
+	return a
+}
+
+// isReplica returns true if the request has been replicated by a peer registry. It fails with codes.PermissionDenied
+// if the request is marked as replicated, but the peer is not authenticated as a peer registry.
+func (a *replicaAuthenticator) isReplica(ctx context.Context) (bool, error) {
+	if !isMarked(ctx) {
+		return false, nil
+	}
+	id, err := a.peerIDFunc(ctx)
+	if err != nil {
+		return false, status.Errorf(codes.PermissionDenied, "replicated request from not authenticated peer: %s", err.Error())
+	}
+	if _, ok := a.peerIDs[id]; !ok {
+		return false, status.Errorf(codes.PermissionDenied, "replicated request from not a peer registry: %s", id.String())
+	}
+	return true, nil
+}
+
+// isMarked returns true if the request is marked as already replicated by some registry
+func isMarked(ctx context.Context) bool {
+	path := grpcmetadata.PathFromContext(ctx)
+	for i, segment := range path.PathSegments {
+		if i >= int(path.Index) {
+			break
+		}
+		if segment.ReplicatedBy != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// replicaPath returns a copy of the request path with the current segment marked as replicated by the registry
+func replicaPath(ctx context.Context, name string) *grpcmetadata.Path {
+	path := grpcmetadata.PathFromContext(ctx).Clone()
+	if path.GetCurrentPathSegment() == nil {
+		path.PathSegments = append(path.PathSegments, &grpcmetadata.PathSegment{})
+		path.Index = uint32(len(path.PathSegments) - 1)
+	}
+	path.GetCurrentPathSegment().ReplicatedBy = name
+	return path
+}
+
+func storeRegistrationTime(ctx context.Context, registrationTime *timestamppb.Timestamp) {
+	if registrationTime != nil {
+		metadata.Map(ctx, false).Store(registrationTimeKey{}, registrationTime.AsTime())
+	}
+}
+
+func loadRegistrationTime(ctx context.Context) (time.Time, bool) {
+	if v, ok := metadata.Map(ctx, false).Load(registrationTimeKey{}); ok {
+		return v.(time.Time), true
+	}
+	return time.Time{}, false
+}
+
+// isStale returns true if there is a newer registration than the given one
+func isStale(ctx context.Context, registrationTime *timestamppb.Timestamp) bool {
+	stored, ok := loadRegistrationTime(ctx)
+	return ok && registrationTime != nil && registrationTime.AsTime().Before(stored)
+}
+
+type peer struct {
+	ctx           context.Context
+	url           *url.URL
+	name          string
+	dialOptions   []grpc.DialOption
+	callTimeout   time.Duration
+	retryInterval time.Duration
+	maxRetries    int
+	executor      serialize.Executor
+	cc            *grpc.ClientConn
+}
+
+// newPeers creates the peers from the options. If resync is not nil, each of the peers is resynced with it every time
+// the connection to the peer is established.
+func newPeers(ctx context.Context, o *options, resync func(ctx context.Context, cc grpc.ClientConnInterface) error) []*peer {
+	var peers []*peer
+	for _, u := range o.peerURLs {
+		p := &peer{
+			ctx:           ctx,
+			url:           u,
+			name:          o.name,
+			dialOptions:   o.dialOptions,
+			callTimeout:   o.callTimeout,
+			retryInterval: o.retryInterval,
+			maxRetries:    o.maxRetries,
+		}
+		if resync != nil {
+			p.executor.AsyncExec(func() {
+				cc, err := p.conn()
+				if err != nil {
+					log.FromContext(p.ctx).WithField("replicate", p.url.String()).Warnf("failed to resync: %s", err.Error())
+					return
+				}
+				go p.monitor(cc, resync)
+			})
+		}
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+// replicate asynchronously sends the request to the peer. Requests to the same peer are sent in order. The failed
+// request is retried with a backoff.
+func (p *peer) replicate(ctx context.Context, method, name string, send func(ctx context.Context, cc grpc.ClientConnInterface) error) {
+	path := replicaPath(ctx, p.name)
+	p.executor.AsyncExec(func() {
+		logger := log.FromContext(p.ctx).WithField("replicate", p.url.String())
+
+		retryInterval := p.retryInterval
+		for retry := 0; p.ctx.Err() == nil; retry++ {
+			err := p.send(path, send)
+			if err == nil {
+				return
+			}
+			if retry == p.maxRetries {
+				logger.Warnf("failed to replicate %s %s: %s", method, name, err.Error())
+				return
+			}
+			logger.Debugf("failed to replicate %s %s, retrying in %s: %s", method, name, retryInterval, err.Error())
+
+			select {
+			case <-p.ctx.Done():
+			case <-clock.FromContext(p.ctx).After(retryInterval):
+			}
+			retryInterval *= 2
+		}
+	})
+}
+
+// send should be called under the executor
+func (p *peer) send(path *grpcmetadata.Path, send func(ctx context.Context, cc grpc.ClientConnInterface) error) error {
+	cc, err := p.conn()
+	if err != nil {
+		return err
+	}
+
+	callCtx, cancel := context.WithTimeout(grpcmetadata.PathWithContext(p.ctx, path), p.callTimeout)
+	defer cancel()
+
+	return send(callCtx, cc)
+}
+
+// monitor resyncs the peer each time the connection to it becomes ready until the peer ctx is done. The idle
+// connection is reconnected, so a restarted peer is resynced without waiting for the next replicated request.
+func (p *peer) monitor(cc *grpc.ClientConn, resync func(ctx context.Context, cc grpc.ClientConnInterface) error) {
+	for {
+		state := cc.GetState()
+		switch state {
+		case connectivity.Idle:
+			cc.Connect()
+		case connectivity.Ready:
+			path := replicaPath(p.ctx, p.name)
+			p.executor.AsyncExec(func() {
+				if p.ctx.Err() != nil {
+					return
+				}
+				if err := p.send(path, resync); err != nil {
+					log.FromContext(p.ctx).WithField("replicate", p.url.String()).Warnf("failed to resync: %s", err.Error())
+				}
+			})
+		default:
+		}
+		if !cc.WaitForStateChange(p.ctx, state) {
+			return
+		}
+	}
+}
+
+// conn should be called under the executor
+func (p *peer) conn() (*grpc.ClientConn, error) {
+	if p.cc != nil {
+		return p.cc, nil
+	}
+	cc, err := grpc.DialContext(p.ctx, grpcutils.URLToTarget(p.url), p.dialOptions...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial %s", p.url.String())
+	}
+	p.cc = cc
+	go func() {
+		<-p.ctx.Done()
+		_ = cc.Close()
+	}()
+	return cc, nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicate
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+)
+
+type replicateNSServer struct {
+	peers []*peer
+	auth  *replicaAuthenticator
+}
+
+// NewNetworkServiceRegistryServer creates a new NetworkServiceRegistryServer chain element replicating NS
+// Register/Unregister requests to the peer registries.
+func NewNetworkServiceRegistryServer(ctx context.Context, opts ...Option) registry.NetworkServiceRegistryServer {
+	o := newOptions(opts...)
+	var resync func(ctx context.Context, cc grpc.ClientConnInterface) error
+	if o.localNSClient != nil {
+		resync = func(ctx context.Context, cc grpc.ClientConnInterface) error {
+			return resyncNetworkServices(ctx, o.localNSClient, cc)
+		}
+	}
+	return &replicateNSServer{
+		peers: newPeers(ctx, o, resync),
+		auth:  newReplicaAuthenticator(o),
+	}
+}
+
+func (s *replicateNSServer) Register(ctx context.Context, ns *registry.NetworkService) (*registry.NetworkService, error) {
+	replica, err := s.auth.isReplica(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := next.NetworkServiceRegistryServer(ctx).Register(ctx, ns)
+	if err != nil || replica {
+		return resp, err
+	}
+
+	replicated := resp.Clone()
+	for _, p := range s.peers {
+		p.replicate(ctx, "Register", replicated.GetName(), func(ctx context.Context, cc grpc.ClientConnInterface) error {
+			_, err := nsClient(cc).Register(ctx, replicated.Clone(), grpc.WaitForReady(true))
+			return err
+		})
+	}
+	return resp, nil
+}
+
+func (s *replicateNSServer) Find(query *registry.NetworkServiceQuery, server registry.NetworkServiceRegistry_FindServer) error {
+	return next.NetworkServiceRegistryServer(server.Context()).Find(query, server)
+}
+
+func (s *replicateNSServer) Unregister(ctx context.Context, ns *registry.NetworkService) (*empty.Empty, error) {
+	replica, err := s.auth.isReplica(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := next.NetworkServiceRegistryServer(ctx).Unregister(ctx, ns)
+	if err != nil || replica {
+		return resp, err
+	}
+
+	replicated := ns.Clone()
+	for _, p := range s.peers {
+		p.replicate(ctx, "Unregister", replicated.GetName(), func(ctx context.Context, cc grpc.ClientConnInterface) error {
+			_, err := nsClient(cc).Unregister(ctx, replicated.Clone(), grpc.WaitForReady(true))
+			return err
+		})
+	}
+	return resp, nil
+}
+
+func nsClient(cc grpc.ClientConnInterface) registry.NetworkServiceRegistryClient {
+	return chain.NewNetworkServiceRegistryClient(
+		grpcmetadata.NewNetworkServiceRegistryClient(),
+		registry.NewNetworkServiceRegistryClient(cc),
+	)
+}
+
+// resyncNetworkServices registers all the NSs found with the local client to the peer
+func resyncNetworkServices(ctx context.Context, local registry.NetworkServiceRegistryClient, cc grpc.ClientConnInterface) error {
+	stream, err := local.Find(ctx, &registry.NetworkServiceQuery{
+		NetworkService: new(registry.NetworkService),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to find the local NSs")
+	}
+	for _, ns := range registry.ReadNetworkServiceList(stream) {
+		if _, err := nsClient(cc).Register(ctx, ns, grpc.WaitForReady(true)); err != nil {
+			return errors.Wrapf(err, "failed to register %s", ns.GetName())
+		}
+	}
+	return nil
+}
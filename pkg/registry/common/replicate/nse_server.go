@@ -0,0 +1,148 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicate
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/setregistrationtime"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type replicateNSEServer struct {
+	peers []*peer
+	auth  *replicaAuthenticator
+}
+
+// NewNetworkServiceEndpointRegistryServer creates a new NetworkServiceEndpointRegistryServer chain element replicating
+// NSE Register/Unregister requests to the peer registries. It should be placed after the metadata chain element and
+// before setregistrationtime.
+func NewNetworkServiceEndpointRegistryServer(ctx context.Context, opts ...Option) registry.NetworkServiceEndpointRegistryServer {
+	o := newOptions(opts...)
+	var resync func(ctx context.Context, cc grpc.ClientConnInterface) error
+	if o.localNSEClient != nil {
+		resync = func(ctx context.Context, cc grpc.ClientConnInterface) error {
+			return resyncNetworkServiceEndpoints(ctx, o.localNSEClient, cc)
+		}
+	}
+	return &replicateNSEServer{
+		peers: newPeers(ctx, o, resync),
+		auth:  newReplicaAuthenticator(o),
+	}
+}
+
+func (s *replicateNSEServer) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, error) {
+	replica, err := s.auth.isReplica(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if replica {
+		if isStale(ctx, nse.GetInitialRegistrationTime()) {
+			log.FromContext(ctx).WithField("replicateNSEServer", "Register").
+				Infof("ignoring replicated NSE %s: a newer registration exists", nse.GetName())
+			return nse, nil
+		}
+		ctx = setregistrationtime.WithTrustedRegistrationTime(ctx)
+	}
+
+	resp, err := next.NetworkServiceEndpointRegistryServer(ctx).Register(ctx, nse)
+	if err != nil {
+		return nil, err
+	}
+	storeRegistrationTime(ctx, resp.GetInitialRegistrationTime())
+
+	if !replica {
+		replicated := resp.Clone()
+		for _, p := range s.peers {
+			p.replicate(ctx, "Register", replicated.GetName(), func(ctx context.Context, cc grpc.ClientConnInterface) error {
+				_, err := nseClient(cc).Register(ctx, replicated.Clone(), grpc.WaitForReady(true))
+				return err
+			})
+		}
+	}
+	return resp, nil
+}
+
+func (s *replicateNSEServer) Find(query *registry.NetworkServiceEndpointQuery, server registry.NetworkServiceEndpointRegistry_FindServer) error {
+	return next.NetworkServiceEndpointRegistryServer(server.Context()).Find(query, server)
+}
+
+func (s *replicateNSEServer) Unregister(ctx context.Context, nse *registry.NetworkServiceEndpoint) (*empty.Empty, error) {
+	replica, err := s.auth.isReplica(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if replica {
+		if isStale(ctx, nse.GetInitialRegistrationTime()) {
+			log.FromContext(ctx).WithField("replicateNSEServer", "Unregister").
+				Infof("ignoring replicated NSE %s: a newer registration exists", nse.GetName())
+			return new(empty.Empty), nil
+		}
+		return next.NetworkServiceEndpointRegistryServer(ctx).Unregister(ctx, nse)
+	}
+
+	replicated := nse.Clone()
+	if registrationTime, ok := loadRegistrationTime(ctx); ok && replicated.GetInitialRegistrationTime() == nil {
+		replicated.InitialRegistrationTime = timestamppb.New(registrationTime)
+	}
+
+	resp, err := next.NetworkServiceEndpointRegistryServer(ctx).Unregister(ctx, nse)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range s.peers {
+		p.replicate(ctx, "Unregister", replicated.GetName(), func(ctx context.Context, cc grpc.ClientConnInterface) error {
+			_, err := nseClient(cc).Unregister(ctx, replicated.Clone(), grpc.WaitForReady(true))
+			return err
+		})
+	}
+	return resp, nil
+}
+
+func nseClient(cc grpc.ClientConnInterface) registry.NetworkServiceEndpointRegistryClient {
+	return chain.NewNetworkServiceEndpointRegistryClient(
+		grpcmetadata.NewNetworkServiceEndpointRegistryClient(),
+		registry.NewNetworkServiceEndpointRegistryClient(cc),
+	)
+}
+
+// resyncNetworkServiceEndpoints registers all the NSEs found with the local client to the peer
+func resyncNetworkServiceEndpoints(ctx context.Context, local registry.NetworkServiceEndpointRegistryClient, cc grpc.ClientConnInterface) error {
+	stream, err := local.Find(ctx, &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to find the local NSEs")
+	}
+	for _, nse := range registry.ReadNetworkServiceEndpointList(stream) {
+		if _, err := nseClient(cc).Register(ctx, nse, grpc.WaitForReady(true)); err != nil {
+			return errors.Wrapf(err, "failed to register %s", nse.GetName())
+		}
+	}
+	return nil
+}
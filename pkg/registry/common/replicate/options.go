@@ -0,0 +1,146 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicate
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/spire"
+)
+
+const (
+	defaultCallTimeout   = time.Second * 5
+	defaultRetryInterval = time.Millisecond * 100
+	defaultMaxRetries    = 5
+)
+
+type options struct {
+	name           string
+	peerURLs       []*url.URL
+	dialOptions    []grpc.DialOption
+	callTimeout    time.Duration
+	retryInterval  time.Duration
+	maxRetries     int
+	peerIDs        []spiffeid.ID
+	peerIDFunc     func(ctx context.Context) (spiffeid.ID, error)
+	localNSEClient registry.NetworkServiceEndpointRegistryClient
+	localNSClient  registry.NetworkServiceRegistryClient
+}
+
+// Option is an option pattern for replicate chain elements
+type Option func(*options)
+
+// WithName sets the name of the registry stamped to the replicated requests. Default: random UUID.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithPeerURLs sets URLs of the peer registries to replicate the requests to
+func WithPeerURLs(peerURLs ...*url.URL) Option {
+	return func(o *options) {
+		o.peerURLs = peerURLs
+	}
+}
+
+// WithDialOptions sets grpc.DialOptions to dial the peer registries
+func WithDialOptions(dialOptions ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = dialOptions
+	}
+}
+
+// WithCallTimeout sets the timeout for a single replicated request or for a peer resync. Default: 5s.
+func WithCallTimeout(callTimeout time.Duration) Option {
+	return func(o *options) {
+		o.callTimeout = callTimeout
+	}
+}
+
+// WithRetryInterval sets the interval before the first retry of the failed replicated request, it is doubled on each
+// next retry. Default: 100ms.
+func WithRetryInterval(retryInterval time.Duration) Option {
+	return func(o *options) {
+		o.retryInterval = retryInterval
+	}
+}
+
+// WithMaxRetries sets the number of the retries of the failed replicated request. The request is dropped after that,
+// the peer catches up on the resync after the reconnect. Default: 5.
+func WithMaxRetries(maxRetries int) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+	}
+}
+
+// WithLocalNetworkServiceEndpointRegistryClient sets the client finding the NSEs stored by the registry. All of them
+// are registered to the peer registry each time the connection to it is established, so a started or restarted peer
+// gets the NSEs registered before. Default: none, the peers are not resynced.
+func WithLocalNetworkServiceEndpointRegistryClient(c registry.NetworkServiceEndpointRegistryClient) Option {
+	return func(o *options) {
+		o.localNSEClient = c
+	}
+}
+
+// WithLocalNetworkServiceRegistryClient sets the client finding the NSs stored by the registry. All of them are
+// registered to the peer registry each time the connection to it is established, so a started or restarted peer gets
+// the NSs registered before. Default: none, the peers are not resynced.
+func WithLocalNetworkServiceRegistryClient(c registry.NetworkServiceRegistryClient) Option {
+	return func(o *options) {
+		o.localNSClient = c
+	}
+}
+
+// WithPeerSpiffeIDs sets spiffe IDs of the peer registries. A request marked as replicated is accepted only from a peer
+// authenticated with one of these IDs, otherwise it fails with codes.PermissionDenied. Default: none, so all the
+// replicated requests are rejected.
+func WithPeerSpiffeIDs(peerIDs ...spiffeid.ID) Option {
+	return func(o *options) {
+		o.peerIDs = peerIDs
+	}
+}
+
+// WithPeerSpiffeIDFunc sets the function returning spiffe ID of the request peer. Default: spire.PeerSpiffeIDFromContext,
+// which gets the ID from the peer TLS certificate.
+func WithPeerSpiffeIDFunc(peerIDFunc func(ctx context.Context) (spiffeid.ID, error)) Option {
+	return func(o *options) {
+		o.peerIDFunc = peerIDFunc
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		name:          uuid.New().String(),
+		callTimeout:   defaultCallTimeout,
+		retryInterval: defaultRetryInterval,
+		maxRetries:    defaultMaxRetries,
+		peerIDFunc:    spire.PeerSpiffeIDFromContext,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
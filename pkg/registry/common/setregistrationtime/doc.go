@@ -1,5 +1,7 @@
 // Copyright (c) 2021 Doc.ai and/or its affiliates.
 //
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
 // SPDX-License-Identifier: Apache-2.0
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -14,5 +16,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package setregistrationtime provides registry server chain elements for initial registration time setting
+// Package setregistrationtime provides registry server chain elements for initial registration time setting.
+// The initial registration time is kept on the NSE refreshes, unless the NSE comes with a newer one from a trusted
+// peer, see WithTrustedRegistrationTime.
 package setregistrationtime
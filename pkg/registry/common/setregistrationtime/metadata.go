@@ -29,6 +29,20 @@ import (
 
 type key struct{}
 
+type trustedKey struct{}
+
+// WithTrustedRegistrationTime returns ctx with the NSE initial registration time trusted: if it is newer than the
+// stored one, the NSE is considered registered again somewhere else, so the newer time wins. It should be set only for
+// the requests from the trusted peers, e.g. the authenticated peer registries.
+func WithTrustedRegistrationTime(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedKey{}, true)
+}
+
+func isTrusted(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedKey{}).(bool)
+	return trusted
+}
+
 // store sets the initialRegistrationTime stored in per NSE metadata.
 func store(ctx context.Context, initialRegistrationTime protoreflect.ProtoMessage) {
 	metadata.Map(ctx, false).Store(key{}, proto.Clone(initialRegistrationTime))
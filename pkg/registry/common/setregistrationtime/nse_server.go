@@ -1,6 +1,6 @@
 // Copyright (c) 2021 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -39,7 +39,9 @@ func NewNetworkServiceEndpointRegistryServer() registry.NetworkServiceEndpointRe
 }
 
 func (r *setregtimeNSEServer) Register(ctx context.Context, nse *registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, error) {
-	if v, ok := load(ctx); ok {
+	// A newer trusted registration time means that the NSE has been registered again somewhere else (e.g. on a peer
+	// registry replica), so the newer registration wins.
+	if v, ok := load(ctx); ok && !(isTrusted(ctx) && isAfter(nse.GetInitialRegistrationTime(), v)) {
 		nse.InitialRegistrationTime = v
 	} else {
 		if nse.InitialRegistrationTime == nil {
@@ -59,3 +61,7 @@ func (r *setregtimeNSEServer) Unregister(ctx context.Context, nse *registry.Netw
 	deleteTime(ctx)
 	return next.NetworkServiceEndpointRegistryServer(ctx).Unregister(ctx, nse)
 }
+
+func isAfter(left, right *timestamppb.Timestamp) bool {
+	return left != nil && left.AsTime().After(right.AsTime())
+}
@@ -1,6 +1,6 @@
 // Copyright (c) 2021 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -26,6 +26,7 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/registry"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/networkservicemesh/sdk/pkg/tools/clock"
 	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
@@ -84,6 +85,27 @@ func TestRegTimeServer_Register(t *testing.T) {
 	require.NotNil(t, reg.InitialRegistrationTime)
 	require.True(t, proto.Equal(reg.InitialRegistrationTime, registeredNse.InitialRegistrationTime))
 
+	// 3.3 Refresh with older time
+	regClone = reg.Clone()
+	regClone.InitialRegistrationTime = timestamppb.New(clockMock.Now().Add(-time.Hour))
+	reg, err = s.Register(ctx, regClone)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(reg.InitialRegistrationTime, registeredNse.InitialRegistrationTime))
+
+	// 3.4 Refresh with newer time
+	regClone = reg.Clone()
+	regClone.InitialRegistrationTime = timestamppb.New(clockMock.Now())
+	reg, err = s.Register(ctx, regClone)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(reg.InitialRegistrationTime, registeredNse.InitialRegistrationTime))
+
+	// 3.5 Refresh with newer trusted time
+	regClone = reg.Clone()
+	regClone.InitialRegistrationTime = timestamppb.New(clockMock.Now())
+	reg, err = s.Register(setregistrationtime.WithTrustedRegistrationTime(ctx), regClone)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(reg.InitialRegistrationTime, regClone.InitialRegistrationTime))
+
 	// 4. Unregister
 	_, err = s.Unregister(ctx, reg.Clone())
 	require.NoError(t, err)
@@ -0,0 +1,58 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validatematches_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/validatematches"
+)
+
+func testNetworkService(selector string) *registry.NetworkService {
+	return &registry.NetworkService{
+		Name: "ns",
+		Matches: []*registry.Match{
+			{
+				SourceSelector: map[string]string{"region": selector},
+			},
+		},
+	}
+}
+
+func TestValidateMatches_Valid(t *testing.T) {
+	server := validatematches.NewNetworkServiceRegistryServer()
+
+	reg, err := server.Register(context.Background(), testNetworkService("op:in(us-east-1,us-east-2)"))
+	require.NoError(t, err)
+
+	_, err = server.Unregister(context.Background(), reg)
+	require.NoError(t, err)
+}
+
+func TestValidateMatches_Invalid(t *testing.T) {
+	server := validatematches.NewNetworkServiceRegistryServer()
+
+	_, err := server.Register(context.Background(), testNetworkService("op:in()"))
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drain provides helpers to mark NSEs as draining.
+//
+// The draining NSE stays registered and keeps serving the existing connections, but it is not selected for the new
+// ones. To start draining, the NSE registers itself again marked with Set. The mark is stored in the NSE labels under
+// the reserved NetworkServiceLabelsKey, so it reaches the registry watchers with the NSE update and is kept by any
+// registry API client. If the drain deadline is set, the registry expires the NSE not later than the deadline.
+package drain
+
+import (
+	"time"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+const (
+	// NetworkServiceLabelsKey is the reserved key of the drain labels in the NSE NetworkServiceLabels. It should not
+	// be used as a network service name.
+	NetworkServiceLabelsKey = "drain.networkservicemesh.io"
+	// DeadlineLabel is the drain label with the deadline in RFC 3339 format. It is empty for the NSE draining without
+	// a deadline.
+	DeadlineLabel = "deadline"
+)
+
+// Set marks the NSE as draining until the deadline. Zero deadline means draining without a deadline.
+func Set(nse *registry.NetworkServiceEndpoint, deadline time.Time) {
+	var value string
+	if !deadline.IsZero() {
+		value = deadline.UTC().Format(time.RFC3339Nano)
+	}
+	if nse.NetworkServiceLabels == nil {
+		nse.NetworkServiceLabels = make(map[string]*registry.NetworkServiceLabels)
+	}
+	nse.NetworkServiceLabels[NetworkServiceLabelsKey] = &registry.NetworkServiceLabels{
+		Labels: map[string]string{
+			DeadlineLabel: value,
+		},
+	}
+}
+
+// Clear removes the draining mark from the NSE
+func Clear(nse *registry.NetworkServiceEndpoint) {
+	delete(nse.NetworkServiceLabels, NetworkServiceLabelsKey)
+	if len(nse.NetworkServiceLabels) == 0 {
+		nse.NetworkServiceLabels = nil
+	}
+}
+
+// IsDraining returns true if the NSE is marked as draining
+func IsDraining(nse *registry.NetworkServiceEndpoint) bool {
+	_, ok := nse.GetNetworkServiceLabels()[NetworkServiceLabelsKey]
+	return ok
+}
+
+// Deadline returns the drain deadline of the NSE. false means that the NSE is not draining or has no deadline.
+func Deadline(nse *registry.NetworkServiceEndpoint) (time.Time, bool) {
+	value := nse.GetNetworkServiceLabels()[NetworkServiceLabelsKey].GetLabels()[DeadlineLabel]
+	if value == "" {
+		return time.Time{}, false
+	}
+	deadline, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return deadline, true
+}
+
+// ExcludeDraining returns the NSEs not marked as draining
+func ExcludeDraining(nses []*registry.NetworkServiceEndpoint) []*registry.NetworkServiceEndpoint {
+	var result []*registry.NetworkServiceEndpoint
+	for _, nse := range nses {
+		if !IsDraining(nse) {
+			result = append(result, nse)
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/drain"
+)
+
+func TestDrain(t *testing.T) {
+	nse := &registry.NetworkServiceEndpoint{Name: "nse-1"}
+	require.False(t, drain.IsDraining(nse))
+
+	deadline := time.Now().Add(time.Minute)
+	drain.Set(nse, deadline)
+
+	// The mark should survive marshaling
+	data, err := proto.Marshal(nse)
+	require.NoError(t, err)
+	nse = new(registry.NetworkServiceEndpoint)
+	require.NoError(t, proto.Unmarshal(data, nse))
+	require.Contains(t, nse.GetNetworkServiceLabels(), drain.NetworkServiceLabelsKey)
+
+	require.True(t, drain.IsDraining(nse))
+	actual, ok := drain.Deadline(nse)
+	require.True(t, ok)
+	require.True(t, deadline.Equal(actual))
+
+	drain.Set(nse, time.Time{})
+	require.True(t, drain.IsDraining(nse))
+	_, ok = drain.Deadline(nse)
+	require.False(t, ok)
+
+	require.Empty(t, drain.ExcludeDraining([]*registry.NetworkServiceEndpoint{nse}))
+
+	drain.Clear(nse)
+	require.False(t, drain.IsDraining(nse))
+	require.Nil(t, nse.GetNetworkServiceLabels())
+	require.Len(t, drain.ExcludeDraining([]*registry.NetworkServiceEndpoint{nse}), 1)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pagination provides helpers to request the registry non-watch Find results page by page.
+//
+// The client sets the page limit with SetLimit and, to get the next page, the continue token received in the last
+// response of the previous page with SetContinueToken. The values are stored in the query and response unknown fields,
+// see unknownfields.
+package pagination
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/unknownfields"
+)
+
+// SetLimit sets the max number of the responses in the page to the query
+func SetLimit(query proto.Message, limit uint32) {
+	unknownfields.SetVarint(query, unknownfields.PageLimitFieldNumber, uint64(limit))
+}
+
+// Limit returns the max number of the responses in the page requested by the query. 0 means no limit.
+func Limit(query proto.Message) uint32 {
+	limit, _ := unknownfields.GetVarint(query, unknownfields.PageLimitFieldNumber)
+	return uint32(limit)
+}
+
+// SetContinueToken sets the continue token to the query or to the last response of the page
+func SetContinueToken(msg proto.Message, token string) {
+	unknownfields.SetBytes(msg, unknownfields.ContinueTokenFieldNumber, []byte(token))
+}
+
+// ContinueToken returns the continue token from the query or from the response. Empty token in the last response
+// of the page means there are no more pages.
+func ContinueToken(msg proto.Message) string {
+	token, _ := unknownfields.GetBytes(msg, unknownfields.ContinueTokenFieldNumber)
+	return string(token)
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package revision provides helpers to carry registry revisions in the Find queries and responses.
+//
+// The revision is stored in the query and response unknown fields, see unknownfields.
+//
+// The watcher opts in to the revisions by passing a resume revision either with Set to the query or with WithResume to
+// the context. Revision 0 requests the full set of the matching entities, so the watcher can get the revisions on the
+// first Find and resume from the last received revision on the next Find. If the registry can't resume from the
+// revision, the watch Find fails with codes.OutOfRange.
+package revision
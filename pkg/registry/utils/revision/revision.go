@@ -0,0 +1,67 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/unknownfields"
+)
+
+// MetadataKey is a gRPC metadata key used to pass the resume revision for the watch Find
+const MetadataKey = "nsm-resume-revision"
+
+// Set sets revision to the msg
+func Set(msg proto.Message, revision uint64) {
+	unknownfields.SetVarint(msg, unknownfields.RevisionFieldNumber, revision)
+}
+
+// Clear removes the revision from the msg
+func Clear(msg proto.Message) {
+	unknownfields.Clear(msg, unknownfields.RevisionFieldNumber)
+}
+
+// Get returns the revision stored in the msg
+func Get(msg proto.Message) (revision uint64, ok bool) {
+	return unknownfields.GetVarint(msg, unknownfields.RevisionFieldNumber)
+}
+
+// WithResume returns a new context carrying the resume revision in the outgoing gRPC metadata
+func WithResume(ctx context.Context, revision uint64) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, strconv.FormatUint(revision, 10))
+}
+
+// ResumeFrom returns the revision the watcher wants to resume from. The revision set to the query takes precedence
+// over the one passed in the incoming gRPC metadata. false means that the watcher doesn't support the revisions.
+func ResumeFrom(ctx context.Context, query proto.Message) (uint64, bool) {
+	if revision, ok := Get(query); ok {
+		return revision, true
+	}
+	values := metadata.ValueFromIncomingContext(ctx, MetadataKey)
+	if len(values) == 0 {
+		return 0, false
+	}
+	revision, err := strconv.ParseUint(values[len(values)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return revision, true
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/revision"
+)
+
+func TestRevision_SetGetClear(t *testing.T) {
+	resp := &registry.NetworkServiceEndpointResponse{
+		NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{Name: "nse"},
+	}
+
+	_, ok := revision.Get(resp)
+	require.False(t, ok)
+
+	revision.Set(resp, 5)
+	revision.Set(resp, 10)
+
+	b, err := proto.Marshal(resp)
+	require.NoError(t, err)
+
+	received := new(registry.NetworkServiceEndpointResponse)
+	require.NoError(t, proto.Unmarshal(b, received))
+
+	rev, ok := revision.Get(received)
+	require.True(t, ok)
+	require.Equal(t, uint64(10), rev)
+	require.Equal(t, "nse", received.GetNetworkServiceEndpoint().GetName())
+
+	revision.Clear(received)
+	_, ok = revision.Get(received)
+	require.False(t, ok)
+	require.True(t, proto.Equal(received, &registry.NetworkServiceEndpointResponse{
+		NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{Name: "nse"},
+	}))
+}
+
+func TestRevision_ResumeFrom(t *testing.T) {
+	query := new(registry.NetworkServiceEndpointQuery)
+
+	_, ok := revision.ResumeFrom(context.Background(), query)
+	require.False(t, ok)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(revision.MetadataKey, "7"))
+	rev, ok := revision.ResumeFrom(ctx, query)
+	require.True(t, ok)
+	require.Equal(t, uint64(7), rev)
+
+	revision.Set(query, 3)
+	rev, ok = revision.ResumeFrom(ctx, query)
+	require.True(t, ok)
+	require.Equal(t, uint64(3), rev)
+}
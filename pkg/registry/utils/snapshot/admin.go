@@ -0,0 +1,236 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// ServiceName is the full name of the snapshot admin gRPC service
+const ServiceName = "networkservicemesh.registry.snapshot.Admin"
+
+const (
+	exportMethod = "/" + ServiceName + "/Export"
+	importMethod = "/" + ServiceName + "/Import"
+
+	formatField         = "format"
+	documentField       = "document"
+	conflictPolicyField = "conflictPolicy"
+	importedField       = "imported"
+	skippedField        = "skipped"
+)
+
+// AdminServer is the snapshot admin gRPC service. The service uses protobuf well-known types, so it doesn't need any
+// generated code on the client side:
+//
+//	Export(google.protobuf.Struct{format}) returns (google.protobuf.BytesValue)
+//	Import(google.protobuf.Struct{document, conflictPolicy}) returns (google.protobuf.Struct{imported, skipped})
+type AdminServer interface {
+	Export(ctx context.Context, request *structpb.Struct) (*wrapperspb.BytesValue, error)
+	Import(ctx context.Context, request *structpb.Struct) (*structpb.Struct, error)
+}
+
+// AuthorizeFunc authorizes the admin service method call. It should return an error with the gRPC status code for the
+// rejected calls.
+type AuthorizeFunc func(ctx context.Context, method string) error
+
+// AdminOption is an option for the snapshot AdminServer
+type AdminOption func(s *adminServer)
+
+// WithAuthorizeFunc sets the function authorizing the admin service calls. Default: all the calls are rejected with
+// codes.PermissionDenied, so the service can't be exposed without an explicit authorization.
+func WithAuthorizeFunc(authorize AuthorizeFunc) AdminOption {
+	return func(s *adminServer) {
+		s.authorize = authorize
+	}
+}
+
+// LocalOnly is AuthorizeFunc allowing only the calls from the local peers: loopback TCP addresses and unix sockets
+func LocalOnly(ctx context.Context, method string) error {
+	if p, ok := peer.FromContext(ctx); ok {
+		switch addr := p.Addr.(type) {
+		case *net.TCPAddr:
+			if addr.IP.IsLoopback() {
+				return nil
+			}
+		case *net.UnixAddr:
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "%s is allowed only from the local peers", method)
+}
+
+func denyAll(_ context.Context, method string) error {
+	return status.Errorf(codes.PermissionDenied, "%s is not authorized: snapshot admin authorization is not configured", method)
+}
+
+type adminServer struct {
+	nsClient  registry.NetworkServiceRegistryClient
+	nseClient registry.NetworkServiceEndpointRegistryClient
+	authorize AuthorizeFunc
+}
+
+// NewAdminServer creates new snapshot AdminServer exporting from and importing to the registry reachable with the
+// given clients. The service exposes and replaces the whole registry contents, so the calls are rejected unless they
+// are authorized with WithAuthorizeFunc.
+func NewAdminServer(nsClient registry.NetworkServiceRegistryClient, nseClient registry.NetworkServiceEndpointRegistryClient, opts ...AdminOption) AdminServer {
+	s := &adminServer{
+		nsClient:  nsClient,
+		nseClient: nseClient,
+		authorize: denyAll,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *adminServer) Export(ctx context.Context, request *structpb.Struct) (*wrapperspb.BytesValue, error) {
+	if err := s.authorize(ctx, exportMethod); err != nil {
+		return nil, err
+	}
+	snapshot, err := Export(ctx, s.nsClient, s.nseClient)
+	if err != nil {
+		return nil, err
+	}
+	data, err := Marshal(snapshot, Format(request.GetFields()[formatField].GetStringValue()))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return wrapperspb.Bytes(data), nil
+}
+
+func (s *adminServer) Import(ctx context.Context, request *structpb.Struct) (*structpb.Struct, error) {
+	if err := s.authorize(ctx, importMethod); err != nil {
+		return nil, err
+	}
+	snapshot, err := Unmarshal([]byte(request.GetFields()[documentField].GetStringValue()))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	policy, err := ParseConflictPolicy(request.GetFields()[conflictPolicyField].GetStringValue())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	result, err := Import(ctx, snapshot, s.nsClient, s.nseClient, policy)
+	if err != nil {
+		return nil, err
+	}
+	return &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			importedField: structpb.NewNumberValue(float64(result.Imported)),
+			skippedField:  structpb.NewNumberValue(float64(result.Skipped)),
+		},
+	}, nil
+}
+
+// RegisterAdminServer registers the snapshot AdminServer on the gRPC server
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&adminServiceDesc, srv)
+}
+
+var adminServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Export",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(structpb.Struct)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(AdminServer).Export(ctx, req.(*structpb.Struct))
+				}
+				if interceptor == nil {
+					return handler(ctx, in)
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: exportMethod}, handler)
+			},
+		},
+		{
+			MethodName: "Import",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(structpb.Struct)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(AdminServer).Import(ctx, req.(*structpb.Struct))
+				}
+				if interceptor == nil {
+					return handler(ctx, in)
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: importMethod}, handler)
+			},
+		},
+	},
+}
+
+// AdminClient is the snapshot admin gRPC service client
+type AdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewAdminClient creates new snapshot AdminClient
+func NewAdminClient(cc grpc.ClientConnInterface) *AdminClient {
+	return &AdminClient{cc: cc}
+}
+
+// Export returns the registry snapshot document of the given format
+func (c *AdminClient) Export(ctx context.Context, format Format, opts ...grpc.CallOption) ([]byte, error) {
+	in := &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			formatField: structpb.NewStringValue(string(format)),
+		},
+	}
+	out := new(wrapperspb.BytesValue)
+	if err := c.cc.Invoke(ctx, exportMethod, in, out, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to export registry snapshot")
+	}
+	return out.GetValue(), nil
+}
+
+// Import imports the registry snapshot document with the given conflict policy
+func (c *AdminClient) Import(ctx context.Context, document []byte, policy ConflictPolicy, opts ...grpc.CallOption) (*ImportResult, error) {
+	in := &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			documentField:       structpb.NewStringValue(string(document)),
+			conflictPolicyField: structpb.NewStringValue(policy.String()),
+		},
+	}
+	out := new(structpb.Struct)
+	if err := c.cc.Invoke(ctx, importMethod, in, out, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to import registry snapshot")
+	}
+	return &ImportResult{
+		Imported: int(out.GetFields()[importedField].GetNumberValue()),
+		Skipped:  int(out.GetFields()[skippedField].GetNumberValue()),
+	}, nil
+}
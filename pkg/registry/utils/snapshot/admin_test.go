@@ -0,0 +1,175 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/snapshot"
+	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
+	"github.com/networkservicemesh/sdk/pkg/tools/sandbox"
+)
+
+func registryClients(ctx context.Context, t *testing.T, entry *sandbox.RegistryEntry) (registry.NetworkServiceRegistryClient, registry.NetworkServiceEndpointRegistryClient) {
+	cc, err := grpc.DialContext(ctx, grpcutils.URLToTarget(entry.URL), sandbox.DialOptions()...)
+	require.NoError(t, err)
+	go func() {
+		<-ctx.Done()
+		_ = cc.Close()
+	}()
+
+	return chain.NewNetworkServiceRegistryClient(
+			grpcmetadata.NewNetworkServiceRegistryClient(),
+			registry.NewNetworkServiceRegistryClient(cc),
+		), chain.NewNetworkServiceEndpointRegistryClient(
+			grpcmetadata.NewNetworkServiceEndpointRegistryClient(),
+			registry.NewNetworkServiceEndpointRegistryClient(cc),
+		)
+}
+
+func adminClient(ctx context.Context, t *testing.T, entry *sandbox.RegistryEntry, opts ...snapshot.AdminOption) *snapshot.AdminClient {
+	nsClient, nseClient := registryClients(ctx, t, entry)
+
+	server := grpc.NewServer()
+	snapshot.RegisterAdminServer(server, snapshot.NewAdminServer(nsClient, nseClient, opts...))
+
+	u := &url.URL{Scheme: "tcp", Host: "127.0.0.1:0"}
+	require.Empty(t, grpcutils.ListenAndServe(ctx, u, server))
+
+	cc, err := grpc.DialContext(ctx, grpcutils.URLToTarget(u),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	require.NoError(t, err)
+	go func() {
+		<-ctx.Done()
+		_ = cc.Close()
+	}()
+
+	return snapshot.NewAdminClient(cc)
+}
+
+func Test_AdminServer_ShouldMoveRegistryStateBetweenDomains(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	domain1 := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(0).
+		SetRegistryProxySupplier(nil).
+		SetNSMgrProxySupplier(nil).
+		Build()
+
+	nsClient, nseClient := registryClients(ctx, t, domain1.Registry)
+	_, err := nsClient.Register(ctx, &registry.NetworkService{Name: "ns-1"})
+	require.NoError(t, err)
+	_, err = nseClient.Register(ctx, &registry.NetworkServiceEndpoint{
+		Name:                "nse-1",
+		NetworkServiceNames: []string{"ns-1"},
+		Url:                 "tcp://1.1.1.1",
+	})
+	require.NoError(t, err)
+
+	document, err := adminClient(ctx, t, domain1.Registry, snapshot.WithAuthorizeFunc(snapshot.LocalOnly)).Export(ctx, snapshot.FormatYAML)
+	require.NoError(t, err)
+
+	domain2 := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(0).
+		SetRegistryProxySupplier(nil).
+		SetNSMgrProxySupplier(nil).
+		Build()
+	client2 := adminClient(ctx, t, domain2.Registry, snapshot.WithAuthorizeFunc(snapshot.LocalOnly))
+
+	result, err := client2.Import(ctx, document, snapshot.ConflictSkip)
+	require.NoError(t, err)
+	require.Equal(t, &snapshot.ImportResult{Imported: 2}, result)
+
+	result, err = client2.Import(ctx, document, snapshot.ConflictSkip)
+	require.NoError(t, err)
+	require.Equal(t, &snapshot.ImportResult{Skipped: 2}, result)
+
+	result, err = client2.Import(ctx, document, snapshot.ConflictOverwrite)
+	require.NoError(t, err)
+	require.Equal(t, &snapshot.ImportResult{Imported: 2}, result)
+
+	data, err := client2.Export(ctx, snapshot.FormatJSON)
+	require.NoError(t, err)
+	s, err := snapshot.Unmarshal(data)
+	require.NoError(t, err)
+	require.Len(t, s.NetworkServices, 1)
+	require.Len(t, s.NetworkServiceEndpoints, 1)
+	require.Equal(t, "tcp://1.1.1.1", s.NetworkServiceEndpoints[0].GetUrl())
+	require.NotNil(t, s.NetworkServiceEndpoints[0].GetExpirationTime())
+}
+
+func Test_AdminServer_ShouldRejectNotAuthorizedCalls(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	domain := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(0).
+		SetRegistryProxySupplier(nil).
+		SetNSMgrProxySupplier(nil).
+		Build()
+
+	client := adminClient(ctx, t, domain.Registry)
+
+	_, err := client.Export(ctx, snapshot.FormatJSON)
+	require.Equal(t, codes.PermissionDenied, status.Code(errors.Cause(err)))
+
+	_, err = client.Import(ctx, []byte(`{"version": 1}`), snapshot.ConflictSkip)
+	require.Equal(t, codes.PermissionDenied, status.Code(errors.Cause(err)))
+}
+
+func Test_Builder_ShouldImportRegistrySnapshot(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	domain := sandbox.NewBuilder(ctx, t).
+		SetNodesCount(0).
+		SetRegistryProxySupplier(nil).
+		SetNSMgrProxySupplier(nil).
+		SetRegistrySnapshot(testSnapshot()).
+		Build()
+
+	nsClient, nseClient := registryClients(ctx, t, domain.Registry)
+	s, err := snapshot.Export(ctx, nsClient, nseClient)
+	require.NoError(t, err)
+	require.Len(t, s.NetworkServices, 1)
+	require.Len(t, s.NetworkServiceEndpoints, 1)
+	require.Equal(t, "nse-1", s.NetworkServiceEndpoints[0].GetName())
+}
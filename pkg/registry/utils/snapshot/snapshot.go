@@ -0,0 +1,158 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot provides export and import of the registry contents: NSs and NSEs including their expiration
+// times and path ids.
+//
+// The snapshot is serialized to a versioned JSON or YAML document, the entities are encoded with protojson. protojson
+// drops the protobuf unknown fields, so they are kept in the document separately by the entity name. Export and
+// Import work with any registry through the registry API, so they can be used both in-process with adapters and over
+// gRPC. The same functionality is available remotely with the snapshot admin gRPC service, see NewAdminServer.
+package snapshot
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// CurrentVersion is the version of the snapshot documents created by Marshal
+const CurrentVersion = 1
+
+// Format is a snapshot document format
+type Format string
+
+const (
+	// FormatJSON is JSON document format
+	FormatJSON Format = "json"
+	// FormatYAML is YAML document format
+	FormatYAML Format = "yaml"
+)
+
+// Snapshot is the registry contents snapshot
+type Snapshot struct {
+	Version                 int
+	CreatedAt               time.Time
+	NetworkServices         []*registry.NetworkService
+	NetworkServiceEndpoints []*registry.NetworkServiceEndpoint
+}
+
+type document struct {
+	Version                 int               `json:"version"`
+	CreatedAt               time.Time         `json:"createdAt"`
+	NetworkServices         []json.RawMessage `json:"networkServices,omitempty"`
+	NetworkServiceEndpoints []json.RawMessage `json:"networkServiceEndpoints,omitempty"`
+	// NetworkServicesUnknown are the NS unknown fields by the NS name
+	NetworkServicesUnknown map[string][]byte `json:"networkServicesUnknown,omitempty"`
+	// NetworkServiceEndpointsUnknown are the NSE unknown fields by the NSE name
+	NetworkServiceEndpointsUnknown map[string][]byte `json:"networkServiceEndpointsUnknown,omitempty"`
+}
+
+// Marshal encodes the snapshot to the document of the given format
+func Marshal(s *Snapshot, format Format) ([]byte, error) {
+	doc := &document{
+		Version:   s.Version,
+		CreatedAt: s.CreatedAt,
+	}
+	for _, ns := range s.NetworkServices {
+		data, err := protojson.Marshal(ns)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal network service %s", ns.GetName())
+		}
+		doc.NetworkServices = append(doc.NetworkServices, data)
+		doc.NetworkServicesUnknown = storeUnknown(doc.NetworkServicesUnknown, ns.GetName(), ns)
+	}
+	for _, nse := range s.NetworkServiceEndpoints {
+		data, err := protojson.Marshal(nse)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal network service endpoint %s", nse.GetName())
+		}
+		doc.NetworkServiceEndpoints = append(doc.NetworkServiceEndpoints, data)
+		doc.NetworkServiceEndpointsUnknown = storeUnknown(doc.NetworkServiceEndpointsUnknown, nse.GetName(), nse)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal snapshot")
+	}
+
+	switch format {
+	case FormatJSON, "":
+		return data, nil
+	case FormatYAML:
+		data, err = yaml.JSONToYAML(data)
+		return data, errors.Wrap(err, "failed to convert snapshot to YAML")
+	default:
+		return nil, errors.Errorf("unknown snapshot format: %s", format)
+	}
+}
+
+// Unmarshal decodes the snapshot from the JSON or YAML document
+func Unmarshal(data []byte) (*Snapshot, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse snapshot")
+	}
+
+	doc := new(document)
+	if err = json.Unmarshal(data, doc); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal snapshot")
+	}
+	if doc.Version < 1 || doc.Version > CurrentVersion {
+		return nil, errors.Errorf("unsupported snapshot version: %d", doc.Version)
+	}
+
+	s := &Snapshot{
+		Version:   doc.Version,
+		CreatedAt: doc.CreatedAt,
+	}
+	for i, data := range doc.NetworkServices {
+		ns := new(registry.NetworkService)
+		if err := protojson.Unmarshal(data, ns); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal network service #%d", i)
+		}
+		ns.ProtoReflect().SetUnknown(doc.NetworkServicesUnknown[ns.GetName()])
+		s.NetworkServices = append(s.NetworkServices, ns)
+	}
+	for i, data := range doc.NetworkServiceEndpoints {
+		nse := new(registry.NetworkServiceEndpoint)
+		if err := protojson.Unmarshal(data, nse); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal network service endpoint #%d", i)
+		}
+		nse.ProtoReflect().SetUnknown(doc.NetworkServiceEndpointsUnknown[nse.GetName()])
+		s.NetworkServiceEndpoints = append(s.NetworkServiceEndpoints, nse)
+	}
+	return s, nil
+}
+
+// storeUnknown stores the msg unknown fields by the name, if there are any
+func storeUnknown(unknown map[string][]byte, name string, msg proto.Message) map[string][]byte {
+	value := msg.ProtoReflect().GetUnknown()
+	if len(value) == 0 {
+		return unknown
+	}
+	if unknown == nil {
+		unknown = make(map[string][]byte)
+	}
+	unknown[name] = value
+	return unknown
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/registry/common/memory"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/snapshot"
+	"github.com/networkservicemesh/sdk/pkg/tools/unknownfields"
+)
+
+func testSnapshot() *snapshot.Snapshot {
+	return &snapshot.Snapshot{
+		Version:   snapshot.CurrentVersion,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		NetworkServices: []*registry.NetworkService{
+			{Name: "ns-1", Payload: "IP"},
+		},
+		NetworkServiceEndpoints: []*registry.NetworkServiceEndpoint{
+			{
+				Name:                "nse-1",
+				NetworkServiceNames: []string{"ns-1"},
+				Url:                 "tcp://1.1.1.1",
+				ExpirationTime:      timestamppb.New(time.Now().Add(time.Hour)),
+				PathIds:             []string{"id-1", "id-2"},
+			},
+		},
+	}
+}
+
+func Test_Snapshot_MarshalUnmarshal(t *testing.T) {
+	for _, format := range []snapshot.Format{snapshot.FormatJSON, snapshot.FormatYAML} {
+		s := testSnapshot()
+
+		data, err := snapshot.Marshal(s, format)
+		require.NoError(t, err)
+
+		actual, err := snapshot.Unmarshal(data)
+		require.NoError(t, err)
+
+		require.Equal(t, s.Version, actual.Version)
+		require.True(t, s.CreatedAt.Equal(actual.CreatedAt))
+		require.Len(t, actual.NetworkServices, 1)
+		require.Equal(t, s.NetworkServices[0].String(), actual.NetworkServices[0].String())
+		require.Len(t, actual.NetworkServiceEndpoints, 1)
+		require.Equal(t, s.NetworkServiceEndpoints[0].String(), actual.NetworkServiceEndpoints[0].String())
+	}
+}
+
+func Test_Snapshot_MarshalUnmarshalUnknownFields(t *testing.T) {
+	for _, format := range []snapshot.Format{snapshot.FormatJSON, snapshot.FormatYAML} {
+		s := testSnapshot()
+		unknownfields.SetVarint(s.NetworkServiceEndpoints[0], unknownfields.RevisionFieldNumber, 42)
+
+		data, err := snapshot.Marshal(s, format)
+		require.NoError(t, err)
+
+		actual, err := snapshot.Unmarshal(data)
+		require.NoError(t, err)
+
+		require.Len(t, actual.NetworkServiceEndpoints, 1)
+		require.True(t, proto.Equal(s.NetworkServiceEndpoints[0], actual.NetworkServiceEndpoints[0]))
+		value, ok := unknownfields.GetVarint(actual.NetworkServiceEndpoints[0], unknownfields.RevisionFieldNumber)
+		require.True(t, ok)
+		require.Equal(t, uint64(42), value)
+	}
+}
+
+func Test_Snapshot_UnmarshalUnsupportedVersion(t *testing.T) {
+	_, err := snapshot.Unmarshal([]byte(`{"version": 2}`))
+	require.Error(t, err)
+
+	_, err = snapshot.Unmarshal([]byte(`networkServices: []`))
+	require.Error(t, err)
+
+	_, err = snapshot.Marshal(testSnapshot(), "xml")
+	require.Error(t, err)
+}
+
+func Test_Snapshot_ExportImport(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	nsClient := adapters.NetworkServiceServerToClient(memory.NewNetworkServiceRegistryServer())
+	nseClient := adapters.NetworkServiceEndpointServerToClient(memory.NewNetworkServiceEndpointRegistryServer())
+
+	s := testSnapshot()
+	s.NetworkServiceEndpoints = append(s.NetworkServiceEndpoints, &registry.NetworkServiceEndpoint{
+		Name:           "nse-expired",
+		ExpirationTime: timestamppb.New(time.Now().Add(-time.Hour)),
+	})
+
+	result, err := snapshot.Import(ctx, s, nsClient, nseClient, snapshot.ConflictSkip)
+	require.NoError(t, err)
+	require.Equal(t, &snapshot.ImportResult{Imported: 2, Skipped: 1}, result)
+
+	exported, err := snapshot.Export(ctx, nsClient, nseClient)
+	require.NoError(t, err)
+	require.Equal(t, snapshot.CurrentVersion, exported.Version)
+	require.Len(t, exported.NetworkServices, 1)
+	require.Len(t, exported.NetworkServiceEndpoints, 1)
+	require.Equal(t, []string{"id-1", "id-2"}, exported.NetworkServiceEndpoints[0].GetPathIds())
+
+	// Conflicts
+	s = testSnapshot()
+	s.NetworkServiceEndpoints[0].Url = "tcp://2.2.2.2"
+
+	result, err = snapshot.Import(ctx, s, nsClient, nseClient, snapshot.ConflictSkip)
+	require.NoError(t, err)
+	require.Equal(t, &snapshot.ImportResult{Skipped: 2}, result)
+
+	exported, err = snapshot.Export(ctx, nsClient, nseClient)
+	require.NoError(t, err)
+	require.Equal(t, "tcp://1.1.1.1", exported.NetworkServiceEndpoints[0].GetUrl())
+
+	result, err = snapshot.Import(ctx, s, nsClient, nseClient, snapshot.ConflictOverwrite)
+	require.NoError(t, err)
+	require.Equal(t, &snapshot.ImportResult{Imported: 2}, result)
+
+	exported, err = snapshot.Export(ctx, nsClient, nseClient)
+	require.NoError(t, err)
+	require.Equal(t, "tcp://2.2.2.2", exported.NetworkServiceEndpoints[0].GetUrl())
+}
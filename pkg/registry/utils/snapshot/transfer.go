@@ -0,0 +1,161 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+)
+
+// ConflictPolicy defines what Import does with the entities already registered in the registry
+type ConflictPolicy int
+
+const (
+	// ConflictSkip keeps the registered entities. This is the default policy.
+	ConflictSkip ConflictPolicy = iota
+	// ConflictOverwrite registers the entities from the snapshot over the registered ones
+	ConflictOverwrite
+)
+
+func (p ConflictPolicy) String() string {
+	switch p {
+	case ConflictSkip:
+		return "skip"
+	case ConflictOverwrite:
+		return "overwrite"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseConflictPolicy returns the conflict policy by its name. Empty name means ConflictSkip.
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	switch name {
+	case "", ConflictSkip.String():
+		return ConflictSkip, nil
+	case ConflictOverwrite.String():
+		return ConflictOverwrite, nil
+	default:
+		return 0, errors.Errorf("unknown conflict policy: %s", name)
+	}
+}
+
+// ImportResult is the result of Import
+type ImportResult struct {
+	// Imported is the number of the registered entities
+	Imported int
+	// Skipped is the number of the entities skipped because of conflicts or expiration
+	Skipped int
+}
+
+// Export returns the snapshot of all NSs and NSEs found in the registry
+func Export(ctx context.Context, nsClient registry.NetworkServiceRegistryClient, nseClient registry.NetworkServiceEndpointRegistryClient) (*Snapshot, error) {
+	s := &Snapshot{
+		Version:   CurrentVersion,
+		CreatedAt: clock.FromContext(ctx).Now().UTC(),
+	}
+
+	nsStream, err := nsClient.Find(ctx, &registry.NetworkServiceQuery{
+		NetworkService: new(registry.NetworkService),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find network services")
+	}
+	s.NetworkServices = registry.ReadNetworkServiceList(nsStream)
+
+	nseStream, err := nseClient.Find(ctx, &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: new(registry.NetworkServiceEndpoint),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find network service endpoints")
+	}
+	s.NetworkServiceEndpoints = registry.ReadNetworkServiceEndpointList(nseStream)
+
+	return s, nil
+}
+
+// Import registers NSs and NSEs from the snapshot in the registry. Expired NSEs are skipped. On error, the result
+// contains the entities processed before the failed one.
+func Import(ctx context.Context, s *Snapshot, nsClient registry.NetworkServiceRegistryClient, nseClient registry.NetworkServiceEndpointRegistryClient, policy ConflictPolicy) (*ImportResult, error) {
+	result := new(ImportResult)
+
+	for _, ns := range s.NetworkServices {
+		if policy == ConflictSkip {
+			exists, err := nsExists(ctx, nsClient, ns.GetName())
+			if err != nil {
+				return result, err
+			}
+			if exists {
+				result.Skipped++
+				continue
+			}
+		}
+		if _, err := nsClient.Register(ctx, ns.Clone()); err != nil {
+			return result, errors.Wrapf(err, "failed to register network service %s", ns.GetName())
+		}
+		result.Imported++
+	}
+
+	now := clock.FromContext(ctx).Now()
+	for _, nse := range s.NetworkServiceEndpoints {
+		if nse.GetExpirationTime() != nil && !now.Before(nse.GetExpirationTime().AsTime()) {
+			result.Skipped++
+			continue
+		}
+		if policy == ConflictSkip {
+			exists, err := nseExists(ctx, nseClient, nse.GetName())
+			if err != nil {
+				return result, err
+			}
+			if exists {
+				result.Skipped++
+				continue
+			}
+		}
+		if _, err := nseClient.Register(ctx, nse.Clone()); err != nil {
+			return result, errors.Wrapf(err, "failed to register network service endpoint %s", nse.GetName())
+		}
+		result.Imported++
+	}
+
+	return result, nil
+}
+
+func nsExists(ctx context.Context, nsClient registry.NetworkServiceRegistryClient, name string) (bool, error) {
+	stream, err := nsClient.Find(ctx, &registry.NetworkServiceQuery{
+		NetworkService: &registry.NetworkService{Name: name},
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to find network service %s", name)
+	}
+	return len(registry.ReadNetworkServiceList(stream)) != 0, nil
+}
+
+func nseExists(ctx context.Context, nseClient registry.NetworkServiceEndpointRegistryClient, name string) (bool, error) {
+	stream, err := nseClient.Find(ctx, &registry.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{Name: name},
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to find network service endpoint %s", name)
+	}
+	return len(registry.ReadNetworkServiceEndpointList(stream)) != 0, nil
+}
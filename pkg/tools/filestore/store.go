@@ -0,0 +1,260 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filestore provides an on-disk storage of named proto messages based on an append-only log and snapshots
+package filestore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/fs"
+)
+
+const (
+	logFileName          = "log.jsonl"
+	snapshotFileName     = "snapshot.jsonl"
+	defaultCompactAfter  = 1000
+	opStore              = "store"
+	opDelete             = "delete"
+	filePerm             = 0o600
+	dirPerm              = 0o700
+	maxRecordSize        = 16 * 1024 * 1024
+	initialRecordBufSize = 64 * 1024
+)
+
+// Message is a proto message having a unique name
+type Message interface {
+	proto.Message
+	GetName() string
+}
+
+type record struct {
+	Op   string          `json:"op"`
+	Name string          `json:"name"`
+	Data json.RawMessage `json:"data,omitempty"`
+	// Unknown keeps the protobuf unknown fields of the message dropped by protojson, see unknownfields
+	Unknown []byte `json:"unknown,omitempty"`
+}
+
+// Store persists named messages in the directory. Every change is appended to the log file, the log is compacted into
+// the snapshot file when it grows long enough. The snapshot is fsynced before it replaces the previous one, the log
+// appends are not fsynced, so the changes since the last compaction may be partially lost on the host crash.
+type Store[T Message] struct {
+	dir          string
+	newMessage   func() T
+	compactAfter int
+
+	mu         sync.Mutex
+	state      map[string]*record
+	logFile    *os.File
+	logRecords int
+}
+
+// New opens the Store in the dir creating it if needed. newMessage should return a new empty message of type T.
+func New[T Message](dir string, newMessage func() T, opts ...Option) (*Store[T], error) {
+	o := &options{
+		compactAfter: defaultCompactAfter,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, errors.Wrapf(err, "failed to create directory %s", dir)
+	}
+
+	s := &Store[T]{
+		dir:          dir,
+		newMessage:   newMessage,
+		compactAfter: o.compactAfter,
+		state:        make(map[string]*record),
+	}
+
+	if err := s.readFile(snapshotFileName); err != nil {
+		return nil, err
+	}
+	if err := s.readFile(logFileName); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Load returns all the stored messages
+func (s *Store[T]) Load() ([]T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []T
+	for name, r := range s.state {
+		msg := s.newMessage()
+		if err := protojson.Unmarshal(r.Data, msg); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal %s", name)
+		}
+		msg.ProtoReflect().SetUnknown(r.Unknown)
+		result = append(result, msg)
+	}
+	return result, nil
+}
+
+// Store stores or replaces the message with the same name
+func (s *Store[T]) Store(msg T) error {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s", msg.GetName())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &record{Op: opStore, Name: msg.GetName(), Data: data, Unknown: msg.ProtoReflect().GetUnknown()}
+	if err := s.append(r); err != nil {
+		return err
+	}
+	s.state[msg.GetName()] = r
+	return s.compactIfNeeded()
+}
+
+// Delete deletes the message with the name
+func (s *Store[T]) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.state[name]; !ok {
+		return nil
+	}
+	if err := s.append(&record{Op: opDelete, Name: name}); err != nil {
+		return err
+	}
+	delete(s.state, name)
+	return s.compactIfNeeded()
+}
+
+// Close syncs and closes the log file
+func (s *Store[T]) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.logFile == nil {
+		return nil
+	}
+	syncErr := s.logFile.Sync()
+	err := s.logFile.Close()
+	s.logFile = nil
+	if syncErr != nil {
+		return errors.Wrap(syncErr, "failed to sync log file")
+	}
+	return errors.Wrap(err, "failed to close log file")
+}
+
+func (s *Store[T]) append(r *record) error {
+	if s.logFile == nil {
+		return errors.New("store is closed")
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal record for %s", r.Name)
+	}
+	if _, err := s.logFile.Write(append(line, '\n')); err != nil {
+		return errors.Wrapf(err, "failed to write record for %s", r.Name)
+	}
+	s.logRecords++
+	return nil
+}
+
+func (s *Store[T]) compactIfNeeded() error {
+	if s.logRecords < s.compactAfter {
+		return nil
+	}
+	return s.compact()
+}
+
+// compact writes the current state into the snapshot and starts a new empty log
+func (s *Store[T]) compact() error {
+	var buf bytes.Buffer
+	for name, r := range s.state {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal record for %s", name)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := fs.WriteFileAtomic(filepath.Join(s.dir, snapshotFileName), buf.Bytes(), filePerm); err != nil {
+		return errors.Wrap(err, "failed to write snapshot")
+	}
+
+	if s.logFile != nil {
+		_ = s.logFile.Close()
+	}
+	logFile, err := os.OpenFile(filepath.Join(s.dir, logFileName), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, filePerm)
+	if err != nil {
+		return errors.Wrap(err, "failed to open log file")
+	}
+	s.logFile = logFile
+	s.logRecords = 0
+	return nil
+}
+
+// readFile applies the records from the file to the state. Malformed last record left by the crash is ignored, any
+// other malformed record fails the read.
+func (s *Store[T]) readFile(name string) error {
+	f, err := os.Open(filepath.Clean(filepath.Join(s.dir, name)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", name)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, initialRecordBufSize), maxRecordSize)
+	for line := 1; scanner.Scan(); line++ {
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			if !scanner.Scan() && scanner.Err() == nil {
+				return nil
+			}
+			return errors.Wrapf(err, "malformed record at line %d of %s", line, name)
+		}
+		switch r.Op {
+		case opStore:
+			r := r
+			s.state[r.Name] = &r
+		case opDelete:
+			delete(s.state, r.Name)
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return errors.Wrapf(err, "failed to read %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestore_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/filestore"
+	"github.com/networkservicemesh/sdk/pkg/tools/unknownfields"
+)
+
+func newNSE() *registry.NetworkServiceEndpoint {
+	return new(registry.NetworkServiceEndpoint)
+}
+
+func loadNames(t *testing.T, s *filestore.Store[*registry.NetworkServiceEndpoint]) []string {
+	nses, err := s.Load()
+	require.NoError(t, err)
+
+	var names []string
+	for _, nse := range nses {
+		names = append(names, nse.GetName())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestStore_Restore(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := filestore.New(dir, newNSE)
+	require.NoError(t, err)
+
+	expected := &registry.NetworkServiceEndpoint{
+		Name:                "nse-1",
+		NetworkServiceNames: []string{"ns"},
+		Url:                 "tcp://1.1.1.1:5000",
+		ExpirationTime:      timestamppb.New(time.Now().Add(time.Minute)),
+		PathIds:             []string{"id-1", "id-2"},
+	}
+	require.NoError(t, s.Store(expected))
+	require.NoError(t, s.Store(&registry.NetworkServiceEndpoint{Name: "nse-2"}))
+	require.NoError(t, s.Store(&registry.NetworkServiceEndpoint{Name: "nse-3"}))
+	require.NoError(t, s.Delete("nse-2"))
+	require.NoError(t, s.Delete("nse-4"))
+	require.NoError(t, s.Close())
+
+	s, err = filestore.New(dir, newNSE)
+	require.NoError(t, err)
+	defer func() { _ = s.Close() }()
+
+	require.Equal(t, []string{"nse-1", "nse-3"}, loadNames(t, s))
+
+	nses, err := s.Load()
+	require.NoError(t, err)
+	for _, nse := range nses {
+		if nse.GetName() == expected.GetName() {
+			require.True(t, proto.Equal(expected, nse))
+		}
+	}
+}
+
+func TestStore_Compaction(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := filestore.New(dir, newNSE, filestore.WithCompactAfter(3))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, s.Store(&registry.NetworkServiceEndpoint{Name: "nse-1", Url: time.Duration(i).String()}))
+	}
+	require.NoError(t, s.Store(&registry.NetworkServiceEndpoint{Name: "nse-2"}))
+	require.NoError(t, s.Close())
+
+	info, err := os.Stat(filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	require.Less(t, info.Size(), int64(200))
+
+	s, err = filestore.New(dir, newNSE)
+	require.NoError(t, err)
+	defer func() { _ = s.Close() }()
+
+	require.Equal(t, []string{"nse-1", "nse-2"}, loadNames(t, s))
+}
+
+func TestStore_TruncatedRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := filestore.New(dir, newNSE)
+	require.NoError(t, err)
+	require.NoError(t, s.Store(&registry.NetworkServiceEndpoint{Name: "nse-1"}))
+	require.NoError(t, s.Close())
+
+	f, err := os.OpenFile(filepath.Join(dir, "log.jsonl"), os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"store","name":"nse-2","da`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s, err = filestore.New(dir, newNSE)
+	require.NoError(t, err)
+	defer func() { _ = s.Close() }()
+
+	require.Equal(t, []string{"nse-1"}, loadNames(t, s))
+}
+
+func TestStore_CorruptedRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := filestore.New(dir, newNSE)
+	require.NoError(t, err)
+	require.NoError(t, s.Store(&registry.NetworkServiceEndpoint{Name: "nse-1"}))
+	require.NoError(t, s.Close())
+
+	f, err := os.OpenFile(filepath.Join(dir, "log.jsonl"), os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"store","name":"nse-2","da` + "\n" + `{"op":"store","name":"nse-3","data":{"name":"nse-3"}}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, err = filestore.New(dir, newNSE)
+	require.Error(t, err)
+}
+
+func TestStore_UnknownFields(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := filestore.New(dir, newNSE)
+	require.NoError(t, err)
+
+	nse := &registry.NetworkServiceEndpoint{Name: "nse-1"}
+	unknownfields.SetVarint(nse, unknownfields.RevisionFieldNumber, 42)
+	require.NoError(t, s.Store(nse))
+	require.NoError(t, s.Close())
+
+	// The first restart reads the log, the second one reads the snapshot
+	for i := 0; i < 2; i++ {
+		s, err = filestore.New(dir, newNSE)
+		require.NoError(t, err)
+
+		nses, err := s.Load()
+		require.NoError(t, err)
+		require.Len(t, nses, 1)
+
+		value, ok := unknownfields.GetVarint(nses[0], unknownfields.RevisionFieldNumber)
+		require.True(t, ok)
+		require.Equal(t, uint64(42), value)
+		require.NoError(t, s.Close())
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipaminfo
+
+import (
+	"context"
+	"math/big"
+	"net"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServiceName is the full name of the IPAM introspection admin gRPC service
+const ServiceName = "networkservicemesh.ipam.info.Admin"
+
+const (
+	queryMethod = "/" + ServiceName + "/Query"
+
+	allocationsField  = "allocations"
+	usageField        = "usage"
+	connectionIDField = "connectionId"
+	addressesField    = "addresses"
+	prefixField       = "prefix"
+	totalField        = "total"
+	freeField         = "free"
+)
+
+// AdminServer is the IPAM introspection admin gRPC service. The service uses protobuf well-known types, so it doesn't
+// need any generated code on the client side:
+//
+//	Query(google.protobuf.Empty) returns (google.protobuf.Struct{<name>: {allocations, usage}})
+//
+// The response maps the IPAM names to their reports. The address counts are decimal strings, so the IPv6 prefix
+// counts don't lose precision.
+type AdminServer interface {
+	Query(ctx context.Context, request *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// AuthorizeFunc authorizes the admin service method call. It should return an error with the gRPC status code for the
+// rejected calls.
+type AuthorizeFunc func(ctx context.Context, method string) error
+
+// AdminOption is an option for the IPAM introspection AdminServer
+type AdminOption func(s *adminServer)
+
+// WithAuthorizeFunc sets the function authorizing the admin service calls. Default: all the calls are rejected with
+// codes.PermissionDenied, so the service can't be exposed without an explicit authorization.
+func WithAuthorizeFunc(authorize AuthorizeFunc) AdminOption {
+	return func(s *adminServer) {
+		s.authorize = authorize
+	}
+}
+
+// LocalOnly is AuthorizeFunc allowing only the calls from the local peers: loopback TCP addresses and unix sockets
+func LocalOnly(ctx context.Context, method string) error {
+	if p, ok := peer.FromContext(ctx); ok {
+		switch addr := p.Addr.(type) {
+		case *net.TCPAddr:
+			if addr.IP.IsLoopback() {
+				return nil
+			}
+		case *net.UnixAddr:
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "%s is allowed only from the local peers", method)
+}
+
+func denyAll(_ context.Context, method string) error {
+	return status.Errorf(codes.PermissionDenied, "%s is not authorized: IPAM admin authorization is not configured", method)
+}
+
+type adminServer struct {
+	introspectors map[string]Introspector
+	authorize     AuthorizeFunc
+}
+
+// NewAdminServer creates new IPAM introspection AdminServer reporting the given named IPAMs. The reports expose the
+// connection IDs and their addresses, so the calls are rejected unless they are authorized with WithAuthorizeFunc.
+func NewAdminServer(introspectors map[string]Introspector, opts ...AdminOption) AdminServer {
+	s := &adminServer{
+		introspectors: introspectors,
+		authorize:     denyAll,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *adminServer) Query(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	if err := s.authorize(ctx, queryMethod); err != nil {
+		return nil, err
+	}
+	reports := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(s.introspectors))}
+	for name, introspector := range s.introspectors {
+		reports.Fields[name] = structpb.NewStructValue(reportToStruct(NewReport(introspector)))
+	}
+	return reports, nil
+}
+
+// RegisterAdminServer registers the IPAM introspection AdminServer on the gRPC server
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&adminServiceDesc, srv)
+}
+
+var adminServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Query",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(AdminServer).Query(ctx, req.(*emptypb.Empty))
+				}
+				if interceptor == nil {
+					return handler(ctx, in)
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: queryMethod}, handler)
+			},
+		},
+	},
+}
+
+// AdminClient is the IPAM introspection admin gRPC service client
+type AdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewAdminClient creates new IPAM introspection AdminClient
+func NewAdminClient(cc grpc.ClientConnInterface) *AdminClient {
+	return &AdminClient{cc: cc}
+}
+
+// Query returns the reports of the IPAMs by their names
+func (c *AdminClient) Query(ctx context.Context, opts ...grpc.CallOption) (map[string]*Report, error) {
+	out := new(structpb.Struct)
+	if err := c.cc.Invoke(ctx, queryMethod, new(emptypb.Empty), out, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to query IPAM reports")
+	}
+	reports := make(map[string]*Report, len(out.GetFields()))
+	for name, value := range out.GetFields() {
+		report, err := reportFromStruct(value.GetStructValue())
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid report of %s", name)
+		}
+		reports[name] = report
+	}
+	return reports, nil
+}
+
+func reportToStruct(report *Report) *structpb.Struct {
+	allocations := make([]*structpb.Value, 0, len(report.Allocations))
+	for _, allocation := range report.Allocations {
+		addresses := make([]*structpb.Value, 0, len(allocation.Addresses))
+		for _, addr := range allocation.Addresses {
+			addresses = append(addresses, structpb.NewStringValue(addr))
+		}
+		allocations = append(allocations, structpb.NewStructValue(&structpb.Struct{
+			Fields: map[string]*structpb.Value{
+				connectionIDField: structpb.NewStringValue(allocation.ConnectionID),
+				addressesField:    structpb.NewListValue(&structpb.ListValue{Values: addresses}),
+			},
+		}))
+	}
+	usage := make([]*structpb.Value, 0, len(report.Usage))
+	for _, prefixUsage := range report.Usage {
+		usage = append(usage, structpb.NewStructValue(&structpb.Struct{
+			Fields: map[string]*structpb.Value{
+				prefixField: structpb.NewStringValue(prefixUsage.Prefix),
+				totalField:  structpb.NewStringValue(prefixUsage.Total.String()),
+				freeField:   structpb.NewStringValue(prefixUsage.Free.String()),
+			},
+		}))
+	}
+	return &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			allocationsField: structpb.NewListValue(&structpb.ListValue{Values: allocations}),
+			usageField:       structpb.NewListValue(&structpb.ListValue{Values: usage}),
+		},
+	}
+}
+
+func reportFromStruct(s *structpb.Struct) (*Report, error) {
+	report := new(Report)
+	for _, value := range s.GetFields()[allocationsField].GetListValue().GetValues() {
+		fields := value.GetStructValue().GetFields()
+		allocation := &Allocation{
+			ConnectionID: fields[connectionIDField].GetStringValue(),
+		}
+		for _, addr := range fields[addressesField].GetListValue().GetValues() {
+			allocation.Addresses = append(allocation.Addresses, addr.GetStringValue())
+		}
+		report.Allocations = append(report.Allocations, allocation)
+	}
+	for _, value := range s.GetFields()[usageField].GetListValue().GetValues() {
+		fields := value.GetStructValue().GetFields()
+		total, ok := new(big.Int).SetString(fields[totalField].GetStringValue(), 10)
+		if !ok {
+			return nil, errors.Errorf("invalid %s: %q", totalField, fields[totalField].GetStringValue())
+		}
+		free, ok := new(big.Int).SetString(fields[freeField].GetStringValue(), 10)
+		if !ok {
+			return nil, errors.Errorf("invalid %s: %q", freeField, fields[freeField].GetStringValue())
+		}
+		report.Usage = append(report.Usage, &PrefixUsage{
+			Prefix: fields[prefixField].GetStringValue(),
+			Total:  total,
+			Free:   free,
+		})
+	}
+	return report, nil
+}
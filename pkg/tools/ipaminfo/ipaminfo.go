@@ -0,0 +1,88 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipaminfo provides read-only introspection of the IPAM allocations and utilization: the Introspector
+// interface implemented by the IPAM servers, the optional admin gRPC service and OpenTelemetry gauges.
+package ipaminfo
+
+import (
+	"math/big"
+	"net"
+	"sort"
+)
+
+// Allocation is the set of addresses allocated to the connection
+type Allocation struct {
+	ConnectionID string   `json:"connectionId"`
+	Addresses    []string `json:"addresses"`
+}
+
+// PrefixUsage is the capacity of the prefix managed by IPAM
+type PrefixUsage struct {
+	Prefix string   `json:"prefix"`
+	Total  *big.Int `json:"total"`
+	Free   *big.Int `json:"free"`
+}
+
+// Utilization returns the percentage of the allocated addresses
+func (u *PrefixUsage) Utilization() float64 {
+	if u.Total == nil || u.Total.Sign() == 0 {
+		return 0
+	}
+	allocated := new(big.Float).SetInt(new(big.Int).Sub(u.Total, u.Free))
+	percent, _ := allocated.Quo(allocated.Mul(allocated, big.NewFloat(100)), new(big.Float).SetInt(u.Total)).Float64()
+	return percent
+}
+
+// NewPrefixUsage returns the usage of the prefix having free addresses
+func NewPrefixUsage(prefix *net.IPNet, free *big.Int) *PrefixUsage {
+	ones, bits := prefix.Mask.Size()
+	return &PrefixUsage{
+		Prefix: prefix.String(),
+		Total:  new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)),
+		Free:   free,
+	}
+}
+
+// Introspector provides the IPAM allocations and utilization
+type Introspector interface {
+	// Allocations returns the allocations sorted by the connection ID
+	Allocations() []*Allocation
+	// Usage returns the usage of each managed prefix
+	Usage() []*PrefixUsage
+}
+
+// Report is the IPAM introspection report
+type Report struct {
+	Allocations []*Allocation  `json:"allocations"`
+	Usage       []*PrefixUsage `json:"usage"`
+}
+
+// NewReport returns the report of the IPAM
+func NewReport(introspector Introspector) *Report {
+	return &Report{
+		Allocations: introspector.Allocations(),
+		Usage:       introspector.Usage(),
+	}
+}
+
+// SortAllocations sorts the allocations by the connection ID
+func SortAllocations(allocations []*Allocation) []*Allocation {
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].ConnectionID < allocations[j].ConnectionID
+	})
+	return allocations
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipaminfo_test
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
+	"github.com/networkservicemesh/sdk/pkg/tools/ipaminfo"
+)
+
+type testIntrospector struct{}
+
+func (testIntrospector) Allocations() []*ipaminfo.Allocation {
+	return []*ipaminfo.Allocation{
+		{ConnectionID: "conn-1", Addresses: []string{"10.0.0.1/32", "10.0.0.0/32"}},
+	}
+}
+
+func (testIntrospector) Usage() []*ipaminfo.PrefixUsage {
+	_, ipNet, _ := net.ParseCIDR("10.0.0.0/24")
+	return []*ipaminfo.PrefixUsage{ipaminfo.NewPrefixUsage(ipNet, big.NewInt(192))}
+}
+
+type testIPv6Introspector struct{}
+
+func (testIPv6Introspector) Allocations() []*ipaminfo.Allocation {
+	return nil
+}
+
+func (testIPv6Introspector) Usage() []*ipaminfo.PrefixUsage {
+	_, ipNet, _ := net.ParseCIDR("fe80::/64")
+	return []*ipaminfo.PrefixUsage{ipaminfo.NewPrefixUsage(ipNet, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1)))}
+}
+
+func Test_PrefixUsage(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+
+	usage := ipaminfo.NewPrefixUsage(ipNet, big.NewInt(192))
+	require.Equal(t, "10.0.0.0/24", usage.Prefix)
+	require.Equal(t, int64(256), usage.Total.Int64())
+	require.Equal(t, 25., usage.Utilization())
+
+	_, ipNet, err = net.ParseCIDR("fe80::/64")
+	require.NoError(t, err)
+
+	usage = ipaminfo.NewPrefixUsage(ipNet, new(big.Int).Lsh(big.NewInt(1), 63))
+	require.Equal(t, new(big.Int).Lsh(big.NewInt(1), 64).String(), usage.Total.String())
+	require.Equal(t, 50., usage.Utilization())
+}
+
+func adminClient(ctx context.Context, t *testing.T, opts ...ipaminfo.AdminOption) *ipaminfo.AdminClient {
+	server := grpc.NewServer()
+	ipaminfo.RegisterAdminServer(server, ipaminfo.NewAdminServer(map[string]ipaminfo.Introspector{
+		"ipam-1": testIntrospector{},
+		"ipam-2": testIPv6Introspector{},
+	}, opts...))
+
+	u := &url.URL{Scheme: "tcp", Host: "127.0.0.1:0"}
+	require.Empty(t, grpcutils.ListenAndServe(ctx, u, server))
+
+	cc, err := grpc.DialContext(ctx, grpcutils.URLToTarget(u), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	go func() {
+		<-ctx.Done()
+		_ = cc.Close()
+	}()
+
+	return ipaminfo.NewAdminClient(cc)
+}
+
+func Test_AdminServer(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	reports, err := adminClient(ctx, t, ipaminfo.WithAuthorizeFunc(ipaminfo.LocalOnly)).Query(ctx)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+
+	report := reports["ipam-1"]
+	require.NotNil(t, report)
+	require.Equal(t, testIntrospector{}.Allocations(), report.Allocations)
+	require.Len(t, report.Usage, 1)
+	require.Equal(t, "10.0.0.0/24", report.Usage[0].Prefix)
+	require.Equal(t, int64(256), report.Usage[0].Total.Int64())
+	require.Equal(t, int64(192), report.Usage[0].Free.Int64())
+
+	// The IPv6 address counts don't lose precision
+	report = reports["ipam-2"]
+	require.NotNil(t, report)
+	require.Empty(t, report.Allocations)
+	require.Equal(t, testIPv6Introspector{}.Usage(), report.Usage)
+}
+
+func Test_AdminServer_ShouldRejectNotAuthorizedCalls(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := adminClient(ctx, t).Query(ctx)
+	require.Equal(t, codes.PermissionDenied, status.Code(errors.Cause(err)))
+
+	_, err = adminClient(ctx, t, ipaminfo.WithAuthorizeFunc(func(context.Context, string) error {
+		return status.Error(codes.Unauthenticated, "unauthenticated")
+	})).Query(ctx)
+	require.Equal(t, codes.Unauthenticated, status.Code(errors.Cause(err)))
+}
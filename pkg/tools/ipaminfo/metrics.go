@@ -0,0 +1,89 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipaminfo
+
+import (
+	"context"
+	"math/big"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/opentelemetry"
+)
+
+// RegisterMetrics registers OpenTelemetry gauges for the IPAM prefixes usage: ipam_total_addresses,
+// ipam_free_addresses and ipam_utilization, attributed with the IPAM name and the prefix, and ipam_allocations
+// attributed with the IPAM name. The gauges are unregistered when ctx is done.
+func RegisterMetrics(ctx context.Context, name string, introspector Introspector) {
+	if !opentelemetry.IsEnabled() {
+		return
+	}
+
+	logger := log.FromContext(ctx).WithField("ipaminfo", "RegisterMetrics")
+	meter := otel.Meter("")
+
+	total, err := meter.Float64ObservableGauge("ipam_total_addresses",
+		metric.WithDescription("number of the addresses in the IPAM prefix"))
+	if err != nil {
+		logger.Errorf("failed to create total addresses gauge: %s", err.Error())
+		return
+	}
+	free, err := meter.Float64ObservableGauge("ipam_free_addresses",
+		metric.WithDescription("number of the free addresses in the IPAM prefix"))
+	if err != nil {
+		logger.Errorf("failed to create free addresses gauge: %s", err.Error())
+		return
+	}
+	utilization, err := meter.Float64ObservableGauge("ipam_utilization",
+		metric.WithDescription("percentage of the allocated addresses in the IPAM prefix"))
+	if err != nil {
+		logger.Errorf("failed to create utilization gauge: %s", err.Error())
+		return
+	}
+	allocations, err := meter.Int64ObservableGauge("ipam_allocations",
+		metric.WithDescription("number of the IPAM allocations"))
+	if err != nil {
+		logger.Errorf("failed to create allocations gauge: %s", err.Error())
+		return
+	}
+
+	ipamAttr := attribute.String("ipam", name)
+	registration, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, usage := range introspector.Usage() {
+			attrs := metric.WithAttributes(ipamAttr, attribute.String("prefix", usage.Prefix))
+			totalValue, _ := new(big.Float).SetInt(usage.Total).Float64()
+			freeValue, _ := new(big.Float).SetInt(usage.Free).Float64()
+			o.ObserveFloat64(total, totalValue, attrs)
+			o.ObserveFloat64(free, freeValue, attrs)
+			o.ObserveFloat64(utilization, usage.Utilization(), attrs)
+		}
+		o.ObserveInt64(allocations, int64(len(introspector.Allocations())), metric.WithAttributes(ipamAttr))
+		return nil
+	}, total, free, utilization, allocations)
+	if err != nil {
+		logger.Errorf("failed to register gauges callback: %s", err.Error())
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = registration.Unregister()
+	}()
+}
@@ -1,6 +1,6 @@
 // Copyright (c) 2021-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2022-2023 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -21,6 +21,7 @@ package ippool
 
 import (
 	"math"
+	"math/big"
 	"net"
 	"sync"
 
@@ -352,6 +353,26 @@ func (tree *IPPool) excludeNode(exclude *treeNode) {
 	tree.excludeNode(exclude.Right)
 }
 
+// AddressCount returns the number of the IP addresses in the pool
+func (tree *IPPool) AddressCount() *big.Int {
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+
+	count := new(big.Int)
+	tree.countNode(tree.root, count)
+	return count
+}
+
+func (tree *IPPool) countNode(node *treeNode, count *big.Int) {
+	if node == nil {
+		return
+	}
+
+	tree.countNode(node.Left, count)
+	count.Add(count, node.Value.size())
+	tree.countNode(node.Right, count)
+}
+
 // Empty returns true if pool does not contain any nodes
 func (tree *IPPool) Empty() bool {
 	return tree.root == nil
@@ -1,6 +1,6 @@
 // Copyright (c) 2021-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2022 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -20,6 +20,7 @@ package ippool
 
 import (
 	"fmt"
+	"math/big"
 	"math/rand"
 	"net"
 	"runtime"
@@ -243,6 +244,23 @@ func TestIPPoolTool_GetPrefixes(t *testing.T) {
 	require.Equal(t, prefixes[2], "192.15.0.0/32")
 }
 
+func TestIPPoolTool_AddressCount(t *testing.T) {
+	ipPool := NewWithNetString("192.0.0.0/24")
+	require.Equal(t, int64(256), ipPool.AddressCount().Int64())
+
+	ipPool.ExcludeString("192.0.0.0/25")
+	require.Equal(t, int64(128), ipPool.AddressCount().Int64())
+
+	ipPool.AddString("192.1.0.0")
+	require.Equal(t, int64(129), ipPool.AddressCount().Int64())
+
+	require.Equal(t, int64(0), New(net.IPv4len).AddressCount().Int64())
+
+	ipv6Pool := NewWithNetString("fe80::/0")
+	expected := new(big.Int).Lsh(big.NewInt(1), 128)
+	require.Equal(t, expected.String(), ipv6Pool.AddressCount().String())
+}
+
 //nolint:dupl
 func TestIPPoolTool_PullP2PAddrs(t *testing.T) {
 	ipPool := NewWithNetString("192.0.0.0/8")
@@ -1,6 +1,6 @@
 // Copyright (c) 2021-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2022 Cisco and/or its affiliates.
+// Copyright (c) 2022-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -18,7 +18,10 @@
 
 package ippool
 
-import "math"
+import (
+	"math"
+	"math/big"
+)
 
 type ipAddress struct {
 	high, low uint64
@@ -83,6 +86,19 @@ func (b *ipAddress) Next() *ipAddress {
 	return r
 }
 
+func (b *ipAddress) bigInt() *big.Int {
+	r := new(big.Int).SetUint64(b.high)
+	r.Lsh(r, prefixBitsSize)
+	return r.Or(r, new(big.Int).SetUint64(b.low))
+}
+
+// size returns the number of the IP addresses in the range
+func (b *ipRange) size() *big.Int {
+	r := b.end.bigInt()
+	r.Sub(r, b.start.bigInt())
+	return r.Add(r, big.NewInt(1))
+}
+
 func (b *ipRange) Clone() *ipRange {
 	return &ipRange{
 		start: b.start.Clone(),
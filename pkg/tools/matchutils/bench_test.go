@@ -0,0 +1,103 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matchutils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+func benchmarkMatchEndpoint(b *testing.B, nsesCount int, cacheSize int) {
+	defer func(cache *valueCache) { compiledValues = cache }(compiledValues)
+	compiledValues = newValueCache(cacheSize)
+
+	ns := &registry.NetworkService{
+		Name: "ns",
+		Matches: []*registry.Match{
+			{
+				SourceSelector: map[string]string{
+					"app": "{{.app}}",
+				},
+				Routes: []*registry.Destination{
+					{
+						DestinationSelector: map[string]string{
+							"app":    "{{.app}}",
+							"region": "op:in({{.region}},us-east-2)",
+							"zone":   "op:!exists()",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var nses []*registry.NetworkServiceEndpoint
+	for i := 0; i < nsesCount; i++ {
+		nses = append(nses, &registry.NetworkServiceEndpoint{
+			Name: fmt.Sprint("nse-", i),
+			NetworkServiceLabels: map[string]*registry.NetworkServiceLabels{
+				"ns": {
+					Labels: map[string]string{
+						"app":    fmt.Sprint("app-", i%10),
+						"region": "us-east-1",
+					},
+				},
+			},
+		})
+	}
+
+	nsLabels := map[string]string{
+		"app":    "app-1",
+		"region": "us-east-1",
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if len(MatchEndpoint(nsLabels, ns, nses...)) != nsesCount/10 {
+			b.Fatal("unexpected match result")
+		}
+	}
+}
+
+func BenchmarkMatchEndpoint_100(b *testing.B) {
+	benchmarkMatchEndpoint(b, 100, defaultCacheSize)
+}
+
+func BenchmarkMatchEndpoint_1000(b *testing.B) {
+	benchmarkMatchEndpoint(b, 1000, defaultCacheSize)
+}
+
+func BenchmarkMatchEndpoint_10000(b *testing.B) {
+	benchmarkMatchEndpoint(b, 10000, defaultCacheSize)
+}
+
+// The uncached benchmarks compile the selector values on every match and are the baseline for the cached ones
+
+func BenchmarkMatchEndpoint_Uncached_100(b *testing.B) {
+	benchmarkMatchEndpoint(b, 100, 0)
+}
+
+func BenchmarkMatchEndpoint_Uncached_1000(b *testing.B) {
+	benchmarkMatchEndpoint(b, 1000, 0)
+}
+
+func BenchmarkMatchEndpoint_Uncached_10000(b *testing.B) {
+	benchmarkMatchEndpoint(b, 10000, 0)
+}
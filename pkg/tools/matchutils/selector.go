@@ -0,0 +1,185 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matchutils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// Selector values prefixed with SelectorOpPrefix are operators:
+//
+//	op:in(v1,v2,...)    - the label is set to one of the values
+//	op:notin(v1,v2,...) - the label is not set or is set to none of the values
+//	op:exists()         - the label is set
+//	op:!exists()        - the label is not set
+//	op:gt(n), op:ge(n)  - the label is set to a number greater than (greater than or equal to) n
+//	op:lt(n), op:le(n)  - the label is set to a number less than (less than or equal to) n
+//
+// Spaces around the operator and the arguments are ignored. Arguments may be Go-templates processed the same way as
+// the exact match values, e.g. "op:in({{.region}},us-east-1)". Values without the prefix are always matched exactly,
+// so the existing selectors like "in(a)" or "!exists()" keep their meaning. Prefixed values not being a valid operator
+// never match and are rejected by ValidateSelector.
+const SelectorOpPrefix = "op:"
+
+const (
+	opIn        = "in"
+	opNotIn     = "notin"
+	opExists    = "exists"
+	opNotExists = "!exists"
+	opGt        = "gt"
+	opGe        = "ge"
+	opLt        = "lt"
+	opLe        = "le"
+)
+
+var selectorOpRegexp = regexp.MustCompile(`^\s*(!?[a-z]+)\((.*)\)\s*$`)
+
+type selectorOp struct {
+	op   string
+	args []string
+}
+
+// parseSelectorOp returns nil if the value is not prefixed with SelectorOpPrefix
+func parseSelectorOp(value string) (*selectorOp, error) {
+	if !strings.HasPrefix(value, SelectorOpPrefix) {
+		return nil, nil
+	}
+
+	submatches := selectorOpRegexp.FindStringSubmatch(strings.TrimPrefix(value, SelectorOpPrefix))
+	if submatches == nil {
+		return nil, errors.Errorf("%s: operator is expected", value)
+	}
+
+	var args []string
+	if strings.TrimSpace(submatches[2]) != "" {
+		for _, arg := range strings.Split(submatches[2], ",") {
+			args = append(args, strings.TrimSpace(arg))
+		}
+	}
+
+	s := &selectorOp{
+		op:   submatches[1],
+		args: args,
+	}
+
+	switch s.op {
+	case opIn, opNotIn:
+		if len(args) == 0 {
+			return nil, errors.Errorf("%s: at least one argument is expected", value)
+		}
+	case opExists, opNotExists:
+		if len(args) != 0 {
+			return nil, errors.Errorf("%s: no arguments are expected", value)
+		}
+	case opGt, opGe, opLt, opLe:
+		if len(args) != 1 {
+			return nil, errors.Errorf("%s: exactly one argument is expected", value)
+		}
+		if !strings.Contains(args[0], "{{") {
+			if _, err := strconv.ParseFloat(args[0], 64); err != nil {
+				return nil, errors.Wrapf(err, "%s: argument is not a number", value)
+			}
+		}
+	default:
+		return nil, errors.Errorf("%s: unknown operator %s", value, s.op)
+	}
+
+	for _, arg := range args {
+		if _, err := template.New("tmpl").Parse(arg); err != nil {
+			return nil, errors.Wrapf(err, "%s: invalid template %s", value, arg)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *selectorOp) match(labels map[string]string, key string, values map[string]string) bool {
+	value, ok := labels[key]
+	switch s.op {
+	case opExists:
+		return ok
+	case opNotExists:
+		return !ok
+	case opIn:
+		return ok && s.contains(value, values)
+	case opNotIn:
+		return !ok || !s.contains(value, values)
+	}
+
+	if !ok {
+		return false
+	}
+	left, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	right, err := strconv.ParseFloat(processLabels(s.args[0], values), 64)
+	if err != nil {
+		return false
+	}
+	switch s.op {
+	case opGt:
+		return left > right
+	case opGe:
+		return left >= right
+	case opLt:
+		return left < right
+	default:
+		return left <= right
+	}
+}
+
+func (s *selectorOp) contains(value string, values map[string]string) bool {
+	for _, arg := range s.args {
+		if value == arg || value == processLabels(arg, values) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSelector returns an error if any of the selector values prefixed with SelectorOpPrefix is not a valid operator
+func ValidateSelector(selector map[string]string) error {
+	for key, value := range selector {
+		if _, err := parseSelectorOp(value); err != nil {
+			return errors.Wrapf(err, "invalid selector for the label %s", key)
+		}
+	}
+	return nil
+}
+
+// ValidateNetworkService returns an error if any of the network service match selectors is invalid
+func ValidateNetworkService(ns *registry.NetworkService) error {
+	for i, match := range ns.GetMatches() {
+		if err := ValidateSelector(match.GetSourceSelector()); err != nil {
+			return errors.Wrapf(err, "match %d: invalid source selector", i)
+		}
+		for j, route := range match.GetRoutes() {
+			if err := ValidateSelector(route.GetDestinationSelector()); err != nil {
+				return errors.Wrapf(err, "match %d: route %d: invalid destination selector", i, j)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matchutils_test
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/matchutils"
+)
+
+func TestIsSubset_Operators(t *testing.T) {
+	labels := map[string]string{
+		"region":   "us-east-1",
+		"capacity": "10",
+		"app":      "firewall",
+		"legacy":   "in(x)",
+	}
+	values := map[string]string{
+		"clientRegion": "eu-west-1",
+	}
+
+	tests := []struct {
+		name     string
+		selector map[string]string
+		want     bool
+	}{
+		{name: "exact", selector: map[string]string{"app": "firewall"}, want: true},
+		{name: "exactMissing", selector: map[string]string{"zone": "a"}, want: false},
+		{name: "in", selector: map[string]string{"region": "op:in(us-west-1, us-east-1)"}, want: true},
+		{name: "noIn", selector: map[string]string{"region": "op:in(us-west-1,eu-west-1)"}, want: false},
+		{name: "inMissing", selector: map[string]string{"zone": "op:in(a,b)"}, want: false},
+		{name: "inTemplate", selector: map[string]string{"region": "op:in({{.clientRegion}},us-east-1)"}, want: true},
+		{name: "notin", selector: map[string]string{"region": "op:notin({{.clientRegion}})"}, want: true},
+		{name: "noNotin", selector: map[string]string{"region": "op:notin(us-east-1)"}, want: false},
+		{name: "notinMissing", selector: map[string]string{"zone": "op:notin(a)"}, want: true},
+		{name: "exists", selector: map[string]string{"app": "op:exists()"}, want: true},
+		{name: "noExists", selector: map[string]string{"zone": "op:exists()"}, want: false},
+		{name: "notExists", selector: map[string]string{"zone": "op: !exists() "}, want: true},
+		{name: "noNotExists", selector: map[string]string{"app": "op:!exists()"}, want: false},
+		{name: "gt", selector: map[string]string{"capacity": "op:gt(5)"}, want: true},
+		{name: "noGt", selector: map[string]string{"capacity": "op:gt(10)"}, want: false},
+		{name: "ge", selector: map[string]string{"capacity": "op:ge(10)"}, want: true},
+		{name: "lt", selector: map[string]string{"capacity": "op:lt(10.5)"}, want: true},
+		{name: "noLe", selector: map[string]string{"capacity": "op:le(9)"}, want: false},
+		{name: "gtNotNumber", selector: map[string]string{"app": "op:gt(1)"}, want: false},
+		{name: "invalid", selector: map[string]string{"app": "op:exists(firewall)"}, want: false},
+		{name: "unknownOperator", selector: map[string]string{"app": "op:foo(bar)"}, want: false},
+		{name: "plainIn", selector: map[string]string{"legacy": "in(x)"}, want: true},
+		{name: "plainNotExists", selector: map[string]string{"zone": "!exists()"}, want: false},
+		{
+			name: "combined",
+			selector: map[string]string{
+				"region":   "op:in(us-east-1,us-east-2)",
+				"capacity": "op:ge(10)",
+				"zone":     "op:!exists()",
+				"app":      "firewall",
+			},
+			want: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, matchutils.IsSubset(labels, tc.selector, values))
+		})
+	}
+}
+
+func TestValidateNetworkService(t *testing.T) {
+	newNetworkService := func(source, destination map[string]string) *registry.NetworkService {
+		return &registry.NetworkService{
+			Name: "ns",
+			Matches: []*registry.Match{
+				{
+					SourceSelector: source,
+					Routes: []*registry.Destination{
+						{DestinationSelector: destination},
+					},
+				},
+			},
+		}
+	}
+
+	require.NoError(t, matchutils.ValidateNetworkService(newNetworkService(
+		map[string]string{"app": "op:in(a,b)", "other": "foo(bar)", "legacy": "in()"},
+		map[string]string{"capacity": "op:gt({{.capacity}})", "region": "{{.region}}"},
+	)))
+
+	for _, invalid := range []string{"op:in()", "op:notin( )", "op:exists(a)", "op:!exists(a)", "op:gt(a)", "op:le(1,2)", "op:in({{.a)", "op:foo(bar)", "op:in"} {
+		require.Error(t, matchutils.ValidateNetworkService(newNetworkService(map[string]string{"app": invalid}, nil)), invalid)
+		require.Error(t, matchutils.ValidateNetworkService(newNetworkService(nil, map[string]string{"app": invalid})), invalid)
+	}
+}
@@ -1,6 +1,6 @@
 // Copyright (c) 2020-2021 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -89,12 +89,15 @@ func MatchNetworkServiceEndpoints(left, right *registry.NetworkServiceEndpoint)
 }
 
 // IsSubset checks if B is a subset of A.
-// Tries to process values for each B value.
+// Tries to process values for each B value. B values may be selector operators, see ValidateSelector.
 func IsSubset(a, b, values map[string]string) bool {
-	if len(a) < len(b) {
-		return false
-	}
 	for k, v := range b {
+		if compiled := compiledValues.get(v); compiled.op != nil || compiled.opErr != nil {
+			if compiled.opErr != nil || !compiled.op.match(a, k, values) {
+				return false
+			}
+			continue
+		}
 		if a[k] != v {
 			result := processLabels(v, values)
 			if a[k] != result {
@@ -107,9 +110,8 @@ func IsSubset(a, b, values map[string]string) bool {
 
 // processLabels generates matches based on destination label selectors that specify templating.
 func processLabels(str string, vars interface{}) string {
-	tmpl, err := template.New("tmpl").Parse(str)
-
-	if err != nil {
+	tmpl := compiledValues.get(str).tmpl
+	if tmpl == nil {
 		return str
 	}
 
@@ -0,0 +1,97 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matchutils
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+const defaultCacheSize = 1024
+
+// compiledValue is a selector value compiled once per distinct string
+type compiledValue struct {
+	op    *selectorOp
+	opErr error
+	tmpl  *template.Template
+}
+
+func compileValue(value string) *compiledValue {
+	result := new(compiledValue)
+	result.op, result.opErr = parseSelectorOp(value)
+	if strings.Contains(value, "{{") {
+		if tmpl, err := template.New("tmpl").Parse(value); err == nil {
+			result.tmpl = tmpl
+		}
+	}
+	return result
+}
+
+type cacheEntry struct {
+	key   string
+	value *compiledValue
+}
+
+// valueCache is a bounded LRU cache of the compiled selector values. The values are compiled on every get if size <= 0.
+type valueCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     list.List
+}
+
+func newValueCache(size int) *valueCache {
+	return &valueCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *valueCache) get(value string) *compiledValue {
+	if c.size <= 0 {
+		return compileValue(value)
+	}
+
+	c.mu.Lock()
+	if e, ok := c.entries[value]; ok {
+		c.lru.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*cacheEntry).value
+	}
+	c.mu.Unlock()
+
+	compiled := compileValue(value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[value]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cacheEntry).value
+	}
+	c.entries[value] = c.lru.PushFront(&cacheEntry{key: value, value: compiled})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	return compiled
+}
+
+var compiledValues = newValueCache(defaultCacheSize)
@@ -0,0 +1,46 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matchutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueCache_LRU(t *testing.T) {
+	c := newValueCache(2)
+
+	first := c.get("{{.first}}")
+	require.NotNil(t, first.tmpl)
+	require.Same(t, first, c.get("{{.first}}"))
+
+	second := c.get("op:in(a,b)")
+	require.NotNil(t, second.op)
+	require.Nil(t, second.tmpl)
+
+	// "{{.first}}" is the most recently used now, so "op:in(a,b)" is evicted
+	require.Same(t, first, c.get("{{.first}}"))
+	c.get("op:exists()")
+	require.Len(t, c.entries, 2)
+	require.Same(t, first, c.get("{{.first}}"))
+	require.NotSame(t, second, c.get("op:in(a,b)"))
+
+	invalid := c.get("op:in()")
+	require.Nil(t, invalid.op)
+	require.Error(t, invalid.opErr)
+}
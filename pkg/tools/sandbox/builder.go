@@ -1,5 +1,7 @@
 // Copyright (c) 2020-2022 Doc.ai and/or its affiliates.
 //
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
 // SPDX-License-Identifier: Apache-2.0
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -35,6 +37,9 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/registry/chains/memory"
 	"github.com/networkservicemesh/sdk/pkg/registry/chains/proxydns"
 	"github.com/networkservicemesh/sdk/pkg/registry/common/dnsresolve"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/replicate"
+	registryadapter "github.com/networkservicemesh/sdk/pkg/registry/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/snapshot"
 	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
 	"github.com/networkservicemesh/sdk/pkg/tools/token"
@@ -47,16 +52,19 @@ type Builder struct {
 
 	nodesCount int
 
-	supplyNSMgr         SupplyNSMgrFunc
-	supplyNSMgrProxy    SupplyNSMgrProxyFunc
-	supplyRegistry      SupplyRegistryFunc
-	supplyRegistryProxy SupplyRegistryProxyFunc
-	setupNode           SetupNodeFunc
+	supplyNSMgr           SupplyNSMgrFunc
+	supplyNSMgrProxy      SupplyNSMgrProxyFunc
+	supplyRegistry        SupplyRegistryFunc
+	supplyRegistryReplica SupplyRegistryReplicaFunc
+	supplyRegistryProxy   SupplyRegistryProxyFunc
+	setupNode             SetupNodeFunc
 
 	name                      string
 	dnsResolver               dnsresolve.Resolver
 	generateTokenFunc         token.GeneratorFunc
 	registryDefaultExpiration time.Duration
+	registryReplicasCount     int
+	registrySnapshot          *snapshot.Snapshot
 
 	useUnixSockets bool
 
@@ -72,6 +80,20 @@ func newRegistryMemoryServer(ctx context.Context, tokenGenerator token.Generator
 		memory.WithDialOptions(options...))
 }
 
+func newRegistryReplicaMemoryServer(ctx context.Context, tokenGenerator token.GeneratorFunc, defaultExpiration time.Duration, proxyRegistryURL *url.URL, peerRegistryURLs []*url.URL, options ...grpc.DialOption) registry.Registry {
+	return memory.NewServer(
+		ctx,
+		tokenGenerator,
+		memory.WithDefaultExpiration(defaultExpiration),
+		memory.WithProxyRegistryURL(proxyRegistryURL),
+		memory.WithPeerRegistryURLs(peerRegistryURLs...),
+		memory.WithReplicateOptions(
+			replicate.WithDialOptions(append(append([]grpc.DialOption{}, options...), WithRegistryReplicaIdentity())...),
+			replicate.WithPeerSpiffeIDs(registryReplicaSpiffeID),
+			replicate.WithPeerSpiffeIDFunc(peerSpiffeIDFromMetadata)),
+		memory.WithDialOptions(options...))
+}
+
 // NewBuilder creates new SandboxBuilder
 func NewBuilder(ctx context.Context, t *testing.T) *Builder {
 	b := &Builder{
@@ -81,11 +103,13 @@ func NewBuilder(ctx context.Context, t *testing.T) *Builder {
 		supplyNSMgr:               nsmgr.NewServer,
 		supplyNSMgrProxy:          nsmgrproxy.NewServer,
 		supplyRegistry:            newRegistryMemoryServer,
+		supplyRegistryReplica:     newRegistryReplicaMemoryServer,
 		supplyRegistryProxy:       proxydns.NewServer,
 		name:                      "cluster.local",
 		dnsResolver:               NewFakeResolver(),
 		generateTokenFunc:         GenerateTestToken,
 		registryDefaultExpiration: time.Minute,
+		registryReplicasCount:     1,
 	}
 
 	b.setupNode = func(ctx context.Context, node *Node, _ int) {
@@ -119,6 +143,25 @@ func (b *Builder) SetRegistrySupplier(f SupplyRegistryFunc) *Builder {
 	return b
 }
 
+// SetRegistryReplicasCount sets the number of the registry replicas replicating NSEs and NSs with each other. If it is
+// more than 1, the replicas are supplied with the registry replica supplier and the nodes are spread between them.
+func (b *Builder) SetRegistryReplicasCount(registryReplicasCount int) *Builder {
+	b.registryReplicasCount = registryReplicasCount
+	return b
+}
+
+// SetRegistryReplicaSupplier replaces default registry replica supplier to custom function
+func (b *Builder) SetRegistryReplicaSupplier(f SupplyRegistryReplicaFunc) *Builder {
+	b.supplyRegistryReplica = f
+	return b
+}
+
+// SetRegistrySnapshot sets the snapshot to import into the registry before the nodes are started
+func (b *Builder) SetRegistrySnapshot(s *snapshot.Snapshot) *Builder {
+	b.registrySnapshot = s
+	return b
+}
+
 // SetRegistryProxySupplier replaces default memory registry supplier to custom function
 func (b *Builder) SetRegistryProxySupplier(f SupplyRegistryProxyFunc) *Builder {
 	b.supplyRegistryProxy = f
@@ -204,7 +247,18 @@ func (b *Builder) Build() *Domain {
 	}
 
 	b.domain.RegistryProxy = b.newRegistryProxy()
-	b.domain.Registry = b.newRegistry()
+	if b.registryReplicasCount > 1 {
+		b.domain.RegistryReplicas = b.newRegistryReplicas()
+		if len(b.domain.RegistryReplicas) != 0 {
+			b.domain.Registry = b.domain.RegistryReplicas[0]
+		}
+	} else {
+		b.domain.Registry = b.newRegistry()
+		if b.domain.Registry != nil {
+			b.domain.RegistryReplicas = []*RegistryEntry{b.domain.Registry}
+		}
+	}
+	b.importRegistrySnapshot()
 	b.domain.NSMgrProxy = b.newNSMgrProxy()
 	for i := 0; i < b.nodesCount; i++ {
 		b.domain.Nodes = append(b.domain.Nodes, b.newNode(i))
@@ -287,6 +341,63 @@ func (b *Builder) newRegistry() *RegistryEntry {
 	return entry
 }
 
+func (b *Builder) importRegistrySnapshot() {
+	if b.registrySnapshot == nil {
+		return
+	}
+	require.NotNil(b.t, b.domain.Registry, "Registry should be set to import the registry snapshot")
+
+	_, err := snapshot.Import(b.ctx, b.registrySnapshot,
+		registryadapter.NetworkServiceServerToClient(b.domain.Registry.NetworkServiceRegistryServer()),
+		registryadapter.NetworkServiceEndpointServerToClient(b.domain.Registry.NetworkServiceEndpointRegistryServer()),
+		snapshot.ConflictOverwrite,
+	)
+	require.NoError(b.t, err)
+}
+
+func (b *Builder) newRegistryReplicas() []*RegistryEntry {
+	if b.supplyRegistryReplica == nil {
+		return nil
+	}
+
+	var nsmgrProxyURL *url.URL
+	if b.domain.NSMgrProxy != nil {
+		nsmgrProxyURL = CloneURL(b.domain.NSMgrProxy.URL)
+	}
+
+	entries := make([]*RegistryEntry, b.registryReplicasCount)
+	for i := range entries {
+		entries[i] = &RegistryEntry{
+			URL: b.domain.supplyURL("reg"),
+		}
+	}
+	for i, entry := range entries {
+		var peerURLs []*url.URL
+		for j := range entries {
+			if j != i {
+				peerURLs = append(peerURLs, CloneURL(entries[j].URL))
+			}
+		}
+
+		entry := entry
+		entry.restartableServer = newRestartableServer(b.ctx, b.t, entry.URL, func(ctx context.Context) {
+			entry.Registry = b.supplyRegistryReplica(
+				ctx,
+				b.generateTokenFunc,
+				b.registryDefaultExpiration,
+				nsmgrProxyURL,
+				peerURLs,
+				DialOptions(WithTokenGenerator(b.generateTokenFunc))...,
+			)
+			serve(ctx, b.t, entry.URL, entry.Register)
+
+			log.FromContext(ctx).Infof("%s: registry replica serve on: %v", b.name, entry.URL)
+		})
+	}
+
+	return entries
+}
+
 func (b *Builder) newNSMgrProxy() *NSMgrEntry {
 	if b.supplyRegistryProxy == nil {
 		return nil
@@ -320,6 +431,9 @@ func (b *Builder) newNode(nodeNum int) *Node {
 		domain:     b.domain,
 		Forwarders: make(map[string]*EndpointEntry),
 	}
+	if len(b.domain.RegistryReplicas) != 0 {
+		node.registry = b.domain.RegistryReplicas[nodeNum%len(b.domain.RegistryReplicas)]
+	}
 
 	b.setupNode(b.ctx, node, nodeNum)
 
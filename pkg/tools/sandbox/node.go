@@ -1,6 +1,6 @@
 // Copyright (c) 2020-2022 Doc.ai and/or its affiliates.
 //
-// Copyright (c) 2023 Cisco and/or its affiliates.
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -35,6 +35,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/discover"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/heal"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanismtranslation"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/null"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/retry"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/roundrobin"
 	registryclient "github.com/networkservicemesh/sdk/pkg/registry/chains/client"
@@ -48,8 +49,9 @@ import (
 
 // Node is a NSMgr with Forwarder, NSE registry clients
 type Node struct {
-	t      *testing.T
-	domain *Domain
+	t        *testing.T
+	domain   *Domain
+	registry *RegistryEntry
 
 	NSMgr      *NSMgrEntry
 	Forwarders map[string]*EndpointEntry
@@ -77,8 +79,12 @@ func (n *Node) NewNSMgr(
 		nsmgr.WithDialTimeout(DialTimeout),
 	}
 
-	if n.domain.Registry != nil {
-		options = append(options, nsmgr.WithRegistry(CloneURL(n.domain.Registry.URL)))
+	registryEntry := n.domain.Registry
+	if n.registry != nil {
+		registryEntry = n.registry
+	}
+	if registryEntry != nil {
+		options = append(options, nsmgr.WithRegistry(CloneURL(registryEntry.URL)))
 	}
 
 	if serveURL.Scheme != "unix" {
@@ -118,7 +124,9 @@ func (n *Node) NewForwarder(
 		require.NoError(n.t, err)
 	}
 
-	var serverOptions = &forwarderOptions{}
+	var serverOptions = &forwarderOptions{
+		candidatesServer: null.NewServer(),
+	}
 	for _, opt := range opts {
 		opt(serverOptions)
 	}
@@ -147,6 +155,7 @@ func (n *Node) NewForwarder(
 				append(
 					append([]networkservice.NetworkServiceServer{
 						discover.NewServer(nsClient, nseClient),
+						serverOptions.candidatesServer,
 						roundrobin.NewServer(),
 					}, serverOptions.additionalFunctionalityServer...),
 					connect.NewServer(
@@ -18,11 +18,14 @@ package sandbox
 
 import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/circuitbreaker"
 )
 
 type forwarderOptions struct {
 	additionalFunctionalityServer []networkservice.NetworkServiceServer
 	additionalFunctionalityClient []networkservice.NetworkServiceClient
+	candidatesServer              networkservice.NetworkServiceServer
 }
 
 // ForwarderOption is an option to configure a forwarder for sandbox
@@ -41,3 +44,11 @@ func WithForwarderAdditionalFunctionalityClient(a ...networkservice.NetworkServi
 		o.additionalFunctionalityClient = a
 	}
 }
+
+// WithForwarderEndpointCircuitBreaker sets the circuitbreaker.CircuitBreaker excluding the network service endpoints
+// with open circuits from the candidates for the new connections
+func WithForwarderEndpointCircuitBreaker(cb *circuitbreaker.CircuitBreaker) ForwarderOption {
+	return func(o *forwarderOptions) {
+		o.candidatesServer = circuitbreaker.NewCandidatesServer(cb)
+	}
+}
@@ -1,5 +1,7 @@
 // Copyright (c) 2020-2022 Doc.ai and/or its affiliates.
 //
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
 // SPDX-License-Identifier: Apache-2.0
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -43,6 +45,9 @@ type SupplyNSMgrFunc func(ctx context.Context, tokenGenerator token.GeneratorFun
 // SupplyRegistryFunc supplies Registry
 type SupplyRegistryFunc func(ctx context.Context, tokenGenerator token.GeneratorFunc, defaultExpiration time.Duration, proxyRegistryURL *url.URL, options ...grpc.DialOption) registry.Registry
 
+// SupplyRegistryReplicaFunc supplies Registry replicating NSEs and NSs with the peer registries
+type SupplyRegistryReplicaFunc func(ctx context.Context, tokenGenerator token.GeneratorFunc, defaultExpiration time.Duration, proxyRegistryURL *url.URL, peerRegistryURLs []*url.URL, options ...grpc.DialOption) registry.Registry
+
 // SupplyRegistryProxyFunc supplies registry proxy
 type SupplyRegistryProxyFunc func(ctx context.Context, tokenGenerator token.GeneratorFunc, dnsResolver dnsresolve.Resolver, options ...proxydns.Option) registry.Registry
 
@@ -83,6 +88,9 @@ type Domain struct {
 	Registry      *RegistryEntry
 	RegistryProxy *RegistryEntry
 
+	// RegistryReplicas contains all the registry replicas, Registry is the first of them
+	RegistryReplicas []*RegistryEntry
+
 	DNSResolver dnsresolve.Resolver
 	Name        string
 
@@ -26,8 +26,10 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 
 	registryapi "github.com/networkservicemesh/api/pkg/api/registry"
 
@@ -37,8 +39,13 @@ import (
 const (
 	// DialTimeout is a default dial timeout for the sandbox tests
 	DialTimeout = 2 * time.Second
+
+	peerSpiffeIDKey = "sandbox-peer-spiffe-id"
 )
 
+// registryReplicaSpiffeID is the spiffe ID of the sandbox registry replicas
+var registryReplicaSpiffeID = spiffeid.RequireFromString("spiffe://test.com/registry-replica")
+
 type insecurePerRPCCredentials struct {
 	credentials.PerRPCCredentials
 }
@@ -122,3 +129,31 @@ func SetupDefaultNode(ctx context.Context, tokenGenerator token.GeneratorFunc, n
 		},
 	}, tokenGenerator)
 }
+
+type peerSpiffeIDCredentials struct {
+	id spiffeid.ID
+}
+
+func (c *peerSpiffeIDCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{peerSpiffeIDKey: c.id.String()}, nil
+}
+
+func (c *peerSpiffeIDCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// WithRegistryReplicaIdentity authenticates the client as a sandbox registry replica. Sandbox doesn't use TLS, so the
+// registry replicas authenticate each other by the request metadata instead of the peer certificate.
+func WithRegistryReplicaIdentity() grpc.DialOption {
+	return grpc.WithPerRPCCredentials(&peerSpiffeIDCredentials{id: registryReplicaSpiffeID})
+}
+
+// peerSpiffeIDFromMetadata returns spiffe ID of the peer set with WithRegistryReplicaIdentity
+func peerSpiffeIDFromMetadata(ctx context.Context) (spiffeid.ID, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(peerSpiffeIDKey)
+	if len(values) == 0 {
+		return spiffeid.ID{}, errors.New("no peer spiffe id in the request metadata")
+	}
+	return spiffeid.FromString(values[0])
+}
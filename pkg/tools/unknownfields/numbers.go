@@ -0,0 +1,31 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unknownfields
+
+import "google.golang.org/protobuf/encoding/protowire"
+
+// The field numbers of the values carried in the unknown fields of the registry API messages. The numbers are far
+// beyond the ones used by the API messages. Each number is owned by a single package, so a new value should get a new
+// number here rather than reuse one.
+const (
+	// RevisionFieldNumber is the registry revision in the Find queries and responses, see registry/utils/revision
+	RevisionFieldNumber protowire.Number = 1000
+	// PageLimitFieldNumber is the page size limit in the Find queries, see registry/utils/pagination
+	PageLimitFieldNumber protowire.Number = 1001
+	// ContinueTokenFieldNumber is the continue token in the Find queries and responses, see registry/utils/pagination
+	ContinueTokenFieldNumber protowire.Number = 1002
+)
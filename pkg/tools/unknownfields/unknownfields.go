@@ -0,0 +1,128 @@
+// Copyright (c) 2024 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package unknownfields provides helpers to carry extra values in the protobuf unknown fields of the API messages.
+//
+// The unknown fields survive both in-process chains and gRPC transport, so the values can be added without changes in
+// the API. protojson drops them, so the persistence formats based on it should store the unknown fields separately,
+// see filestore. The field numbers in use are listed in this package.
+package unknownfields
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// SetVarint sets the varint field with the num to the msg replacing the previous value
+func SetVarint(msg proto.Message, num protowire.Number, value uint64) {
+	m := msg.ProtoReflect()
+	unknown := strip(m.GetUnknown(), num)
+	unknown = protowire.AppendTag(unknown, num, protowire.VarintType)
+	unknown = protowire.AppendVarint(unknown, value)
+	m.SetUnknown(unknown)
+}
+
+// GetVarint returns the varint field with the num from the msg
+func GetVarint(msg proto.Message, num protowire.Number) (value uint64, ok bool) {
+	var found bool
+	valid := walk(msg, func(n protowire.Number, typ protowire.Type, b []byte) {
+		if n != num || typ != protowire.VarintType {
+			return
+		}
+		if v, m := protowire.ConsumeVarint(b); m >= 0 {
+			value, found = v, true
+		}
+	})
+	if !valid || !found {
+		return value, false
+	}
+	return value, true
+}
+
+// SetBytes sets the bytes field with the num to the msg replacing the previous value
+func SetBytes(msg proto.Message, num protowire.Number, value []byte) {
+	m := msg.ProtoReflect()
+	unknown := strip(m.GetUnknown(), num)
+	unknown = protowire.AppendTag(unknown, num, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, value)
+	m.SetUnknown(unknown)
+}
+
+// GetBytes returns the bytes field with the num from the msg
+func GetBytes(msg proto.Message, num protowire.Number) (value []byte, ok bool) {
+	var found bool
+	valid := walk(msg, func(n protowire.Number, typ protowire.Type, b []byte) {
+		if n != num || typ != protowire.BytesType {
+			return
+		}
+		if v, m := protowire.ConsumeBytes(b); m >= 0 {
+			value, found = v, true
+		}
+	})
+	if !valid || !found {
+		return value, false
+	}
+	return value, true
+}
+
+// Clear removes the field with the num from the msg
+func Clear(msg proto.Message, num protowire.Number) {
+	m := msg.ProtoReflect()
+	if unknown := m.GetUnknown(); len(unknown) > 0 {
+		m.SetUnknown(strip(unknown, num))
+	}
+}
+
+// walk calls f for each unknown field of the msg. It returns false if the unknown fields are malformed.
+func walk(msg proto.Message, f func(num protowire.Number, typ protowire.Type, value []byte)) bool {
+	if msg == nil {
+		return false
+	}
+	unknown := msg.ProtoReflect().GetUnknown()
+	for len(unknown) > 0 {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return false
+		}
+		m := protowire.ConsumeFieldValue(num, typ, unknown[n:])
+		if m < 0 {
+			return false
+		}
+		f(num, typ, unknown[n:n+m])
+		unknown = unknown[n+m:]
+	}
+	return true
+}
+
+// strip returns the unknown fields without the fields with the num
+func strip(unknown []byte, num protowire.Number) []byte {
+	var result []byte
+	for len(unknown) > 0 {
+		n, typ, tagLen := protowire.ConsumeTag(unknown)
+		if tagLen < 0 {
+			return result
+		}
+		valueLen := protowire.ConsumeFieldValue(n, typ, unknown[tagLen:])
+		if valueLen < 0 {
+			return result
+		}
+		if n != num {
+			result = append(result, unknown[:tagLen+valueLen]...)
+		}
+		unknown = unknown[tagLen+valueLen:]
+	}
+	return result
+}